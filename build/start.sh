@@ -19,6 +19,20 @@ export LIBGL_DEBUG=verbose
 CONSOLE_PORT=5554
 ADB_PORT=5555
 
+# Quick-boot snapshots
+# QUICKBOOT_DIR is a volume holding a golden snapshot of the AVD. When QUICKBOOT_MODE
+# is "save", the emulator is cold booted and the AVD is copied to the volume with a
+# snapshot of the booted device. When it is "load", the AVD is restored from the
+# volume and the emulator is booted from the snapshot.
+QUICKBOOT_SNAPSHOT=golden
+QUICKBOOT_LOADED=false
+if [[ "${QUICKBOOT_MODE}" == "load" ]] && [[ -f "${QUICKBOOT_DIR}/.complete" ]] ; then
+    rm -rf "$HOME/.android/avd"
+    mkdir -p "$HOME/.android"
+    cp -a "${QUICKBOOT_DIR}/avd" "$HOME/.android/avd"
+    QUICKBOOT_LOADED=true
+fi
+
 # Setup AVD
 EMULATOR_CONFIG="$HOME/.android/avd/x86_64.avd/config.ini"
 if [[ ! -d "$HOME/.android/avd/" ]] ; then
@@ -42,7 +56,12 @@ sed -i -E "s:(vm\.heapSize)=(.*):\1=${EMULATOR_HEAP_SIZE}:" "${EMULATOR_CONFIG}"
 
 # Set default emulator options if not defined in the environment
 if [[ -z "${EMULATOR_OPTS}" ]] ; then
-    EMULATOR_OPTS="-screen multi-touch -no-boot-anim -noaudio -netfast -verbose -skip-adb-auth -no-snapshot -no-snapstorage"
+    EMULATOR_OPTS="-screen multi-touch -no-boot-anim -noaudio -netfast -verbose -skip-adb-auth"
+    case "${QUICKBOOT_MODE}" in
+        save) EMULATOR_OPTS+=" -no-snapshot-load -no-snapshot-save" ;;
+        load) EMULATOR_OPTS+=" -snapshot ${QUICKBOOT_SNAPSHOT} -no-snapshot-save" ;;
+        *)    EMULATOR_OPTS+=" -no-snapshot -no-snapstorage" ;;
+    esac
 fi
 # Add any extra opts
 EMULATOR_OPTS+=" ${EXTRA_EMULATOR_OPTS}"
@@ -93,7 +112,8 @@ until [ "X${boot_completed:0:1}" = "X1" ]; do
 done
 adb wait-for-device
 
-if [[ "$(ls /opt/sdk/apps/)" != "" ]]; then
+# Apps are already installed when booting from a golden snapshot
+if [[ "${QUICKBOOT_LOADED}" != "true" ]] && [[ "$(ls /opt/sdk/apps/)" != "" ]]; then
   for i in /opt/sdk/apps/*.apk ; do
     pkg_search=$(basename $i | cut -d '.' -f1)
     adb shell "pm list packages" | grep "${pkg_search}" | cut -d ':' -f2 | xargs adb uninstall || true
@@ -101,4 +121,15 @@ if [[ "$(ls /opt/sdk/apps/)" != "" ]]; then
   done
 fi
 
+# Save the golden snapshot and exit
+if [[ "${QUICKBOOT_MODE}" == "save" ]] ; then
+    adb emu avd snapshot save "${QUICKBOOT_SNAPSHOT}"
+    adb emu kill
+    wait ${EMULATOR_PID} || true
+    rm -rf "${QUICKBOOT_DIR}/avd" "${QUICKBOOT_DIR}/.complete"
+    cp -a "$HOME/.android/avd" "${QUICKBOOT_DIR}/avd"
+    touch "${QUICKBOOT_DIR}/.complete"
+    exit 0
+fi
+
 wait ${EMULATOR_PID}
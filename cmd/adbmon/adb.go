@@ -14,7 +14,7 @@ func adbLog(msg ...interface{}) {
 	log.Println(out...)
 }
 
-func runADBServer(stCh <-chan struct{}) {
+func runADBServer(provider string, stCh <-chan struct{}) {
 	ctx, cancel := context.WithCancel(context.Background())
 	adbLog("Starting ADB server watcher")
 	go func() {
@@ -30,6 +30,7 @@ func runADBServer(stCh <-chan struct{}) {
 			} else {
 				adbLog("ADB server exited:", string(out))
 			}
+			adbServerRestarts.WithLabelValues(provider).Inc()
 		}
 	}()
 	<-stCh
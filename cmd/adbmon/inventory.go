@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/adb"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/inventory"
+)
+
+// inventoryProps are the properties read from each device. The order matches
+// the fields filled in by readDeviceProps.
+var inventoryProps = []string{
+	"ro.product.manufacturer",
+	"ro.product.model",
+	"ro.build.version.sdk",
+	"ro.build.version.release",
+	"ro.product.cpu.abi",
+}
+
+var inventoryPropsCmd = func() string {
+	cmds := make([]string, len(inventoryProps))
+	for i, prop := range inventoryProps {
+		cmds[i] = fmt.Sprintf("getprop %s", prop)
+	}
+	return strings.Join(cmds, "; ")
+}()
+
+// inventoryServer serves the last collected inventory of the devices attached
+// to the local ADB server.
+type inventoryServer struct {
+	mux       sync.RWMutex
+	inventory inventory.Inventory
+}
+
+// runInventory periodically collects the devices attached to the local ADB
+// server and serves them for the operator on the inventory port.
+func runInventory(nodeName, provider string, interval time.Duration) {
+	srv := &inventoryServer{inventory: inventory.Inventory{Node: nodeName, Provider: provider, Devices: []inventory.Device{}}}
+	go func() {
+		ticker := time.NewTicker(interval)
+		for ; true; <-ticker.C {
+			devices, err := collectInventory()
+			if err != nil {
+				log.Println("INVENTORY: Failed to collect devices:", err.Error())
+				continue
+			}
+			srv.mux.Lock()
+			srv.inventory.Devices = devices
+			srv.mux.Unlock()
+		}
+	}()
+	mux := http.NewServeMux()
+	mux.HandleFunc(inventory.Path, srv.serveInventory)
+	addr := fmt.Sprintf(":%d", androidv1alpha1.ADBInventoryPort)
+	log.Println("INVENTORY: Serving device inventory on", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Println("INVENTORY: Server exited with error:", err.Error())
+	}
+}
+
+func (s *inventoryServer) serveInventory(w http.ResponseWriter, r *http.Request) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.inventory); err != nil {
+		log.Println("INVENTORY: Failed to write inventory:", err.Error())
+	}
+}
+
+// collectInventory lists the USB devices attached to the local ADB server and
+// reads the properties of the ones that are online.
+func collectInventory() ([]inventory.Device, error) {
+	attached, err := listADBDevices()
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]inventory.Device, 0)
+	for _, adbDevice := range attached {
+		// skip devices connected over the network, e.g. emulators
+		if strings.Contains(adbDevice.serial, ":") || strings.HasPrefix(adbDevice.serial, "emulator-") {
+			continue
+		}
+		device := inventory.Device{Serial: adbDevice.serial, State: adbDevice.state, BatteryLevel: -1}
+		if device.State == inventory.StateDevice {
+			readDeviceProps(&device)
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// readDeviceProps fills in the properties and battery level of a device. Any
+// that cannot be read are left empty.
+func readDeviceProps(device *inventory.Device) {
+	out, err := adb.NewCommand(inventoryPropsCmd).WithDevice(device.Serial).WithShell().WithTimeout(10 * time.Second).Execute()
+	if err != nil {
+		log.Println("INVENTORY: Failed to read properties of", device.Serial, "error:", err.Error())
+	} else {
+		fields := []*string{&device.Manufacturer, &device.Model, &device.SDK, &device.Release, &device.ABI}
+		for i, value := range strings.Split(string(out), "\n") {
+			if i < len(fields) {
+				*fields[i] = strings.TrimSpace(value)
+			}
+		}
+	}
+	out, err = adb.NewCommand("dumpsys battery").WithDevice(device.Serial).WithShell().WithTimeout(10 * time.Second).Execute()
+	if err != nil {
+		log.Println("INVENTORY: Failed to read battery of", device.Serial, "error:", err.Error())
+		return
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "level:") {
+			continue
+		}
+		if level, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "level:"))); err == nil {
+			device.BatteryLevel = int32(level)
+		}
+		break
+	}
+}
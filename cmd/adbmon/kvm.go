@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// kvmInfo represents the virtualization capabilities of a node.
+type kvmInfo struct {
+	// whether the kvm device is present
+	kvm bool
+	// the virtualization extension of the cpu, vmx or svm, if any
+	cpuVirt string
+	// whether nested virtualization is enabled in the kvm module
+	nested bool
+}
+
+// labels returns the node labels reporting the capabilities.
+func (k kvmInfo) labels() map[string]string {
+	cpuVirt := k.cpuVirt
+	if cpuVirt == "" {
+		cpuVirt = "none"
+	}
+	return map[string]string{
+		androidv1alpha1.KVMNodeLabel:                  strconv.FormatBool(k.kvm && k.cpuVirt != ""),
+		androidv1alpha1.CPUVirtualizationNodeLabel:    cpuVirt,
+		androidv1alpha1.NestedVirtualizationNodeLabel: strconv.FormatBool(k.nested),
+	}
+}
+
+// discoverKVM checks the host for the kvm device, the cpu virtualization flags
+// and whether nested virtualization is enabled. The host /dev is expected to be
+// mounted at hostDev. The cpu flags and kvm module parameters are the same
+// inside a container as on the host.
+func discoverKVM(hostDev string) kvmInfo {
+	info := kvmInfo{}
+	if _, err := os.Stat(filepath.Join(hostDev, "kvm")); err == nil {
+		info.kvm = true
+	}
+	if cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo"); err == nil {
+		for _, line := range strings.Split(string(cpuinfo), "\n") {
+			if !strings.HasPrefix(line, "flags") {
+				continue
+			}
+			for _, flag := range strings.Fields(line) {
+				if flag == "vmx" || flag == "svm" {
+					info.cpuVirt = flag
+				}
+			}
+			break
+		}
+	}
+	for _, module := range []string{"kvm_intel", "kvm_amd"} {
+		out, err := ioutil.ReadFile(filepath.Join("/sys/module", module, "parameters", "nested"))
+		if err != nil {
+			continue
+		}
+		if val := strings.TrimSpace(string(out)); val == "Y" || val == "1" {
+			info.nested = true
+		}
+	}
+	return info
+}
+
+// runKVMDiscovery periodically discovers the virtualization capabilities of the
+// node and labels it with them, until a value is received on the stop channel.
+func runKVMDiscovery(nodeName, hostDev string, interval time.Duration, stCh <-chan struct{}) error {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		info := discoverKVM(hostDev)
+		log.Println("KVM: Discovered", info.labels(), "on node", nodeName)
+		if err := labelNode(clientset, nodeName, info.labels()); err != nil {
+			log.Println("KVM: Failed to label node:", err.Error())
+		}
+		select {
+		case <-stCh:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// labelNode applies the given labels to a node.
+func labelNode(clientset kubernetes.Interface, nodeName string, labels map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = clientset.CoreV1().Nodes().Patch(nodeName, types.MergePatchType, patch)
+	return err
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/adb"
 )
 
@@ -20,11 +23,23 @@ func main() {
 		noLocalOffline      bool
 		noLocalUnauthorized bool
 
-		device string
-		host   string
+		device     string
+		disconnect string
+		host       string
 
 		rethinkDBAddr string
 		provider      string
+
+		inventory         bool
+		inventoryInterval time.Duration
+
+		metricsAddr     string
+		metricsInterval time.Duration
+
+		discoverKVM bool
+		nodeName    string
+		hostDev     string
+		kvmInterval time.Duration
 	)
 
 	if addr := os.Getenv("RETHINKDB_PORT_28015_TCP"); addr != "" {
@@ -41,8 +56,17 @@ func main() {
 	flag.BoolVar(&noLocalOffline, "no-local-offline", false, "Don't run the stf provider local offline watcher")
 	flag.BoolVar(&noLocalUnauthorized, "no-local-unauthorized", false, "Don't run the stf provider local unauthorized watcher")
 	flag.StringVar(&device, "connect", "", "Use to connect a device to another adb server from pods")
+	flag.StringVar(&disconnect, "disconnect", "", "Use to disconnect a device from another adb server from pods")
 	flag.StringVar(&host, "host", "127.0.0.1", "Use this host to connect the provided device.")
 	flag.BoolVar(&verbose, "verbose", false, "Verbose logging")
+	flag.BoolVar(&inventory, "inventory", false, "Serve an inventory of the USB devices attached to the ADB server for the operator")
+	flag.DurationVar(&inventoryInterval, "inventory-interval", 15*time.Second, "How often to collect the device inventory")
+	flag.StringVar(&metricsAddr, "metrics-addr", fmt.Sprintf(":%d", androidv1alpha1.ADBMetricsPort), "The address to serve prometheus metrics on, or empty to disable them")
+	flag.DurationVar(&metricsInterval, "metrics-interval", 15*time.Second, "How often to collect the states of devices for metrics")
+	flag.BoolVar(&discoverKVM, "discover-kvm", false, "Periodically label the node with its KVM capabilities instead of running the daemon")
+	flag.StringVar(&nodeName, "node-name", os.Getenv("NODE_NAME"), "The name of the node adbmon is running on")
+	flag.StringVar(&hostDev, "host-dev", "/host/dev", "The path the host /dev is mounted at")
+	flag.DurationVar(&kvmInterval, "kvm-interval", 5*time.Minute, "How often to discover KVM capabilities")
 
 	flag.Parse()
 
@@ -53,6 +77,23 @@ func main() {
 		os.Exit(0)
 	}
 
+	if disconnect != "" {
+		if err := runDisconnect(disconnect, host, verbose); err != nil {
+			panic(err)
+		}
+		os.Exit(0)
+	}
+
+	if discoverKVM {
+		if nodeName == "" {
+			log.Fatal("A node name is required for KVM discovery")
+		}
+		if err := runKVMDiscovery(nodeName, hostDev, kvmInterval, stopChannel()); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
 	runDaemon(daemonOpts{
 		provider:            provider,
 		rethinkDBAddr:       rethinkDBAddr,
@@ -60,6 +101,11 @@ func main() {
 		noRemote:            noRemote,
 		noLocalOffline:      noLocalOffline,
 		noLocalUnauthorized: noLocalUnauthorized,
+		inventory:           inventory,
+		inventoryInterval:   inventoryInterval,
+		nodeName:            nodeName,
+		metricsAddr:         metricsAddr,
+		metricsInterval:     metricsInterval,
 	})
 }
 
@@ -76,13 +122,34 @@ func runConnect(device, host string, verbose bool) error {
 	return nil
 }
 
+func runDisconnect(device, host string, verbose bool) error {
+	cmd := adb.NewCommand("disconnect", device).WithHost(host)
+	if verbose {
+		cmd = cmd.WithVerbose()
+	}
+	out, err := cmd.Execute()
+	if err != nil {
+		// the device may already be gone from the server
+		if strings.Contains(err.Error(), "no such device") {
+			log.Println(err.Error())
+			return nil
+		}
+		return err
+	}
+	log.Println(string(out))
+	return nil
+}
+
 type daemonOpts struct {
-	provider, rethinkDBAddr                              string
+	provider, rethinkDBAddr, nodeName, metricsAddr       string
 	noUSB, noRemote, noLocalOffline, noLocalUnauthorized bool
+	inventory                                            bool
+	inventoryInterval, metricsInterval                   time.Duration
 }
 
-func runDaemon(opts daemonOpts) {
-	// Setup stop channel and signal catcher
+// stopChannel returns a channel that receives a value when the process is
+// asked to stop.
+func stopChannel() chan struct{} {
 	stCh := make(chan struct{})
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
@@ -91,15 +158,31 @@ func runDaemon(opts daemonOpts) {
 		log.Println("Received stop signal")
 		stCh <- struct{}{}
 	}()
+	return stCh
+}
+
+func runDaemon(opts daemonOpts) {
+	// Setup stop channel and signal catcher
+	stCh := stopChannel()
 
 	// Launch the USB watcher
 	if !opts.noUSB {
 		log.Println("Launching Local USB Device Watcher")
-		go watchForRebindDevices()
+		go watchForRebindDevices(opts.provider)
 	} else {
 		log.Println("USB Watching is disabled")
 	}
 
+	if opts.metricsAddr != "" {
+		log.Println("Launching Metrics Server")
+		go runMetrics(opts.metricsAddr, opts.provider, opts.metricsInterval)
+	}
+
+	if opts.inventory {
+		log.Println("Launching Device Inventory Server")
+		go runInventory(opts.nodeName, opts.provider, opts.inventoryInterval)
+	}
+
 	if opts.provider == "" || opts.rethinkDBAddr == " " {
 		log.Println("No provider name and/or rethinkdb configured, skipping STF functionality")
 	} else {
@@ -129,5 +212,5 @@ func runDaemon(opts daemonOpts) {
 	}
 
 	// Run the ADB server
-	runADBServer(stCh)
+	runADBServer(opts.provider, stCh)
 }
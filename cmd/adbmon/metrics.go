@@ -0,0 +1,159 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/adb"
+)
+
+var (
+	devicesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "adbmon_devices",
+		Help: "The number of devices on the ADB server in each state",
+	}, []string{"provider", "state"})
+
+	deviceStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "adbmon_device_state",
+		Help: "The state of each device on the ADB server, always 1",
+	}, []string{"provider", "serial", "state"})
+
+	reconnectAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adbmon_reconnect_attempts_total",
+		Help: "The number of attempts to reconnect a device, by the watcher that made them",
+	}, []string{"provider", "serial", "watcher"})
+
+	reconnectFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adbmon_reconnect_failures_total",
+		Help: "The number of failed attempts to reconnect a device, by the watcher that made them",
+	}, []string{"provider", "serial", "watcher"})
+
+	rebindAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adbmon_usb_rebind_attempts_total",
+		Help: "The number of attempts to rebind a USB device that was not connected to ADB",
+	}, []string{"provider", "serial"})
+
+	rebindFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adbmon_usb_rebind_failures_total",
+		Help: "The number of failed attempts to rebind a USB device",
+	}, []string{"provider", "serial"})
+
+	rethinkDBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "adbmon_rethinkdb_query_duration_seconds",
+		Help:    "The latency of queries made to RethinkDB, including connecting",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider", "query"})
+
+	rethinkDBErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adbmon_rethinkdb_errors_total",
+		Help: "The number of failed queries made to RethinkDB, including connecting",
+	}, []string{"provider", "query"})
+
+	adbServerRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "adbmon_adb_server_restarts_total",
+		Help: "The number of times the ADB server exited and was restarted",
+	}, []string{"provider"})
+)
+
+// Watcher names used in the reconnect metrics.
+const (
+	watcherRemote       = "remote"
+	watcherOffline      = "offline"
+	watcherUnauthorized = "unauthorized"
+)
+
+// RethinkDB query names used in the rethinkdb metrics.
+const (
+	queryConnect           = "connect"
+	queryDevicesByProvider = "devices_by_provider"
+	queryDevicesByStatus   = "devices_by_status"
+)
+
+func init() {
+	prometheus.MustRegister(
+		devicesGauge,
+		deviceStateGauge,
+		reconnectAttempts,
+		reconnectFailures,
+		rebindAttempts,
+		rebindFailures,
+		rethinkDBQueryDuration,
+		rethinkDBErrors,
+		adbServerRestarts,
+	)
+}
+
+// runMetrics periodically collects the states of the devices on the local ADB
+// server and serves all metrics at /metrics on the given address.
+func runMetrics(addr, provider string, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		for ; true; <-ticker.C {
+			if err := collectDeviceStates(provider); err != nil {
+				log.Println("METRICS: Failed to collect device states:", err.Error())
+			}
+		}
+	}()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	log.Println("METRICS: Serving metrics on", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Println("METRICS: Server exited with error:", err.Error())
+	}
+}
+
+// collectDeviceStates updates the device state gauges from the devices on the
+// local ADB server.
+func collectDeviceStates(provider string) error {
+	devices, err := listADBDevices()
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]float64)
+	deviceStateGauge.Reset()
+	for _, device := range devices {
+		counts[device.state]++
+		deviceStateGauge.WithLabelValues(provider, device.serial, device.state).Set(1)
+	}
+	devicesGauge.Reset()
+	for state, count := range counts {
+		devicesGauge.WithLabelValues(provider, state).Set(count)
+	}
+	return nil
+}
+
+// observeRethinkDB records the latency of a RethinkDB query started at start,
+// and counts it as an error if err is not nil.
+func observeRethinkDB(provider, query string, start time.Time, err error) {
+	rethinkDBQueryDuration.WithLabelValues(provider, query).Observe(time.Since(start).Seconds())
+	if err != nil {
+		rethinkDBErrors.WithLabelValues(provider, query).Inc()
+	}
+}
+
+// adbDevice represents a line in the output of `adb devices`.
+type adbDevice struct {
+	serial, state string
+}
+
+// listADBDevices returns the serials and states of the devices on the local
+// ADB server.
+func listADBDevices() ([]adbDevice, error) {
+	out, err := adb.NewCommand("devices").WithTimeout(10 * time.Second).Execute()
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]adbDevice, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		devices = append(devices, adbDevice{serial: fields[0], state: fields[1]})
+	}
+	return devices, nil
+}
@@ -12,12 +12,16 @@ import (
 func reconnectDevices(rdbAddr, provider string) {
 	ticker := time.NewTicker(time.Duration(5 * time.Second))
 	for range ticker.C {
+		start := time.Now()
 		session, err := rethinkdb.NewSession(rdbAddr)
+		observeRethinkDB(provider, queryConnect, start, err)
 		if err != nil {
 			log.Println("REMOTE: Failed to connect to rethinkdb, skipping:", err.Error())
 			continue
 		}
+		start = time.Now()
 		devices, err := session.GetAllDevicesForProvider(provider)
+		observeRethinkDB(provider, queryDevicesByProvider, start, err)
 		if err != nil {
 			log.Println("REMOTE: Failed to query devices for provider", provider, "error:", err.Error())
 			session.Close()
@@ -34,8 +38,10 @@ func reconnectDevices(rdbAddr, provider string) {
 			if len(strings.Split(device, ":")) > 1 {
 				if !devicePresentAndOnline(adbDevices, device) {
 					log.Println("REMOTE: Reconnecting remote device:", device)
+					reconnectAttempts.WithLabelValues(provider, device, watcherRemote).Inc()
 					if out, err := adb.NewCommand("connect", device).Execute(); err != nil {
 						log.Println("REMOTE: Failed to reconnect remote device:", err.Error())
+						reconnectFailures.WithLabelValues(provider, device, watcherRemote).Inc()
 					} else {
 						log.Println("REMOTE:", strings.TrimSpace(string(out)))
 					}
@@ -49,12 +55,16 @@ func reconnectDevices(rdbAddr, provider string) {
 func watchOfflineDevices(rdbAddr, provider string) {
 	ticker := time.NewTicker(time.Duration(5 * time.Second))
 	for range ticker.C {
+		start := time.Now()
 		session, err := rethinkdb.NewSession(rdbAddr)
+		observeRethinkDB(provider, queryConnect, start, err)
 		if err != nil {
 			log.Println("RDB: Could not connect to rethinkdb instance at", rdbAddr, "error:", err)
 			continue
 		}
+		start = time.Now()
 		devices, err := session.GetDevicesForProviderByStatus(provider, rethinkdb.StatusOffline)
+		observeRethinkDB(provider, queryDevicesByStatus, start, err)
 		if err != nil {
 			session.Close()
 			log.Println("RDB: Failed to query offline devices:", err)
@@ -63,8 +73,10 @@ func watchOfflineDevices(rdbAddr, provider string) {
 		session.Close()
 		for _, device := range devices {
 			log.Println("RDB: Reconnecting offline device:", device)
+			reconnectAttempts.WithLabelValues(provider, device, watcherOffline).Inc()
 			if _, err := adb.NewCommand("reconnect").WithDevice(device).Execute(); err != nil {
 				log.Println("RDB: Failed to reconnect device:", err)
+				reconnectFailures.WithLabelValues(provider, device, watcherOffline).Inc()
 			}
 		}
 	}
@@ -73,12 +85,16 @@ func watchOfflineDevices(rdbAddr, provider string) {
 func watchUnauthorizedDevices(rdbAddr, provider string) {
 	ticker := time.NewTicker(time.Duration(5 * time.Second))
 	for range ticker.C {
+		start := time.Now()
 		session, err := rethinkdb.NewSession(rdbAddr)
+		observeRethinkDB(provider, queryConnect, start, err)
 		if err != nil {
 			log.Println("RDB: Could not connect to rethinkdb instance at", rdbAddr, "error:", err)
 			continue
 		}
+		start = time.Now()
 		devices, err := session.GetDevicesForProviderByStatus(provider, rethinkdb.StatusUnauthorized)
+		observeRethinkDB(provider, queryDevicesByStatus, start, err)
 		if err != nil {
 			session.Close()
 			log.Println("RDB: Failed to query unauthorized devices:", err)
@@ -87,8 +103,10 @@ func watchUnauthorizedDevices(rdbAddr, provider string) {
 		session.Close()
 		for _, device := range devices {
 			log.Println("RDB: Reconnecting unauthorized device:", device)
+			reconnectAttempts.WithLabelValues(provider, device, watcherUnauthorized).Inc()
 			if _, err := adb.NewCommand("reconnect").WithDevice(device).Execute(); err != nil {
 				log.Println("RDB: Failed to reconnect device:", err)
+				reconnectFailures.WithLabelValues(provider, device, watcherUnauthorized).Inc()
 			}
 		}
 	}
@@ -24,17 +24,17 @@ func usbLog(msg ...interface{}) {
 	}
 }
 
-func watchForRebindDevices() {
+func watchForRebindDevices(provider string) {
 	ticker := time.NewTicker(time.Duration(5) * time.Second)
 	for range ticker.C {
 		usbLog("Checking for disconnected USB devices")
-		if err := rebindDevices(); err != nil {
+		if err := rebindDevices(provider); err != nil {
 			usbLog("Error running usb rebind:", err)
 		}
 	}
 }
 
-func rebindDevices() error {
+func rebindDevices(provider string) error {
 	if err := filepath.Walk(devicePath, func(path string, info os.FileInfo, err error) error {
 		if info.Name() == "bInterfaceSubClass" {
 			details, err := ioutil.ReadFile(path)
@@ -76,10 +76,13 @@ func rebindDevices() error {
 				pathSplit := strings.Split(path, "/")
 				deviceID := pathSplit[len(pathSplit)-2]
 				usbLog("Unbinding and rebinding USB device with ID:", deviceID)
+				rebindAttempts.WithLabelValues(provider, serialStr).Inc()
 				if err := ioutil.WriteFile(unbindPath, []byte(deviceID), 0666); err != nil {
+					rebindFailures.WithLabelValues(provider, serialStr).Inc()
 					return err
 				}
 				if err := ioutil.WriteFile(bindPath, []byte(deviceID), 0666); err != nil {
+					rebindFailures.WithLabelValues(provider, serialStr).Inc()
 					return err
 				}
 			} else {
@@ -15,7 +15,12 @@ import (
 
 	"github.com/tinyzimmer/android-farm-operator/pkg/apis"
 	"github.com/tinyzimmer/android-farm-operator/pkg/controller"
+	"github.com/tinyzimmer/android-farm-operator/pkg/controller/androidjob"
+	farmmetrics "github.com/tinyzimmer/android-farm-operator/pkg/metrics"
+	"github.com/tinyzimmer/android-farm-operator/pkg/resources/emulators"
 	"github.com/tinyzimmer/android-farm-operator/pkg/server"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/artifacts"
+	"github.com/tinyzimmer/android-farm-operator/pkg/webhook"
 	"github.com/tinyzimmer/android-farm-operator/version"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
@@ -27,6 +32,7 @@ import (
 	"github.com/spf13/pflag"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -40,6 +46,13 @@ var (
 	metricsPort         int32 = 8383
 	operatorMetricsPort int32 = 8686
 )
+
+// Defaults for serving admission webhooks
+var (
+	defaultWebhookPort    = 9443
+	defaultWebhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+	defaultWebhookService = "android-farm-operator-webhooks"
+)
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -62,6 +75,20 @@ func main() {
 	var enableAPI bool
 	pflag.CommandLine.BoolVar(&enableAPI, "api", false, "Enable the API server for interacting with devices")
 
+	var enableWebhooks bool
+	var webhookPort int
+	var webhookCertDir string
+	var webhookService string
+	pflag.CommandLine.BoolVar(&enableWebhooks, "webhooks", false, "Serve validating and mutating admission webhooks")
+	pflag.CommandLine.IntVar(&webhookPort, "webhook-port", defaultWebhookPort, "The port to serve admission webhooks on")
+	pflag.CommandLine.StringVar(&webhookCertDir, "webhook-cert-dir", defaultWebhookCertDir, "The directory containing tls.crt, tls.key, and ca.crt for the webhook server")
+	pflag.CommandLine.StringVar(&webhookService, "webhook-service", defaultWebhookService, "The name of the service in front of the webhook server in the operator namespace")
+
+	pflag.CommandLine.BoolVar(&emulators.RequireKVMNodeLabel, "require-kvm-node-label", false, "Only schedule KVM enabled emulators to nodes labeled by the KVM discovery daemon")
+
+	pflag.CommandLine.IntVar(&androidjob.MaxConcurrentDeviceJobs, "job-workers", androidjob.MaxConcurrentDeviceJobs, "The number of devices AndroidJobs can run on at the same time")
+	pflag.CommandLine.StringVar(&artifacts.Dir, "artifacts-dir", "", "The directory of the volume to store job artifacts in for templates using a volume sink")
+
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -95,6 +122,8 @@ func main() {
 	mgr, err := manager.New(cfg, manager.Options{
 		Namespace:          metav1.NamespaceAll,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		Port:               webhookPort,
+		CertDir:            webhookCertDir,
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -115,6 +144,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Report device counts from the manager's cache
+	if err := farmmetrics.RegisterDeviceCollector(mgr.GetClient()); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	// Setup all Webhooks
+	if enableWebhooks {
+		if err := webhook.AddToManager(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		addConversion(cfg, webhookService, webhookCertDir)
+	}
+
 	// Add the Metrics Service
 	addMetrics(ctx, cfg, metav1.NamespaceAll)
 
@@ -146,6 +190,24 @@ func main() {
 	wg.Wait()
 }
 
+// addConversion will configure the multi-version CRDs to use the conversion webhook
+// served by the operator.
+func addConversion(cfg *rest.Config, service, certDir string) {
+	namespace, err := k8sutil.GetOperatorNamespace()
+	if err != nil {
+		if errors.Is(err, k8sutil.ErrRunLocal) {
+			log.Info("Skipping CRD conversion setup; not running in a cluster.")
+			return
+		}
+		log.Error(err, "")
+		os.Exit(1)
+	}
+	if err := webhook.InjectConversionConfig(cfg, types.NamespacedName{Name: service, Namespace: namespace}, certDir); err != nil {
+		log.Error(err, "Could not configure CRD conversion webhook")
+		os.Exit(1)
+	}
+}
+
 // addMetrics will create the Services and Service Monitors to allow the operator export the metrics by using
 // the Prometheus operator
 func addMetrics(ctx context.Context, cfg *rest.Config, namespace string) {
@@ -13,11 +13,13 @@ require (
 	github.com/operator-framework/operator-sdk v0.16.0
 	github.com/otiai10/gosseract/v2 v2.2.4
 	github.com/pkg/errors v0.9.1 // indirect
+	github.com/prometheus/client_golang v1.2.1
 	github.com/spf13/pflag v1.0.5
 	github.com/vitali-fedulov/images v0.0.0-20191211155917-6fa8ac4e96b9
 	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd // indirect
 	gopkg.in/rethinkdb/rethinkdb-go.v6 v6.2.1
 	k8s.io/api v0.17.3
+	k8s.io/apiextensions-apiserver v0.17.3
 	k8s.io/apimachinery v0.18.0
 	k8s.io/client-go v12.0.0+incompatible
 	sigs.k8s.io/controller-runtime v0.5.1-0.20200307095134-d0de78d9f1c1
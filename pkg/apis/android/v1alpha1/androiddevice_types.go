@@ -17,11 +17,129 @@ type AndroidDeviceSpec struct {
 	Hostname string `json:"hostname,omitempty"`
 	// A subdomain to apply to the device (used by AndroidFarm controller)
 	Subdomain string `json:"subdomain,omitempty"`
+	// Where the device comes from. Physical devices are maintained by the
+	// AndroidFarm controller for the devices attached to the providers of its
+	// host USB device groups, and are not provisioned. Defaults to Emulator.
+	Source DeviceSource `json:"source,omitempty"`
 }
 
+// DeviceSource represents where an AndroidDevice comes from.
+type DeviceSource string
+
+const (
+	// EmulatorSource means the device is an emulator run in a pod by the
+	// operator.
+	EmulatorSource DeviceSource = "Emulator"
+	// PhysicalSource means the device is attached to a node over USB and was
+	// reported by the ADB server of its provider.
+	PhysicalSource DeviceSource = "Physical"
+)
+
+// DevicePhase represents the current phase in the lifecycle of an AndroidDevice.
+type DevicePhase string
+
+const (
+	// DevicePending means the device pod has not been created or has not been
+	// assigned an IP address yet.
+	DevicePending DevicePhase = "Pending"
+	// DeviceBooting means the device pod is running and the emulator is booting.
+	DeviceBooting DevicePhase = "Booting"
+	// DeviceReady means the emulator has finished booting.
+	DeviceReady DevicePhase = "Ready"
+	// DeviceBound means the emulator has finished booting and is connected to
+	// its STF provider.
+	DeviceBound DevicePhase = "Bound"
+	// DeviceDegraded means the emulator finished booting at one point, but is
+	// no longer reachable over ADB.
+	DeviceDegraded DevicePhase = "Degraded"
+	// DeviceTerminating means the device has been marked for deletion.
+	DeviceTerminating DevicePhase = "Terminating"
+)
+
 // AndroidDeviceStatus defines the observed state of AndroidDevice
 type AndroidDeviceStatus struct {
-	State string `json:"state"`
+	// The current phase of the device.
+	Phase DevicePhase `json:"phase,omitempty"`
+	// The IP address of the device pod.
+	PodIP string `json:"podIP,omitempty"`
+	// The serial of the device as known by its STF provider's ADB server.
+	ADBSerial string `json:"adbSerial,omitempty"`
+	// The checksum of the configuration used to provision the device pod.
+	ConfigChecksum string `json:"configChecksum,omitempty"`
+	// The time the emulator started booting.
+	BootStartedAt *metav1.Time `json:"bootStartedAt,omitempty"`
+	// The time the emulator finished booting.
+	BootCompletedAt *metav1.Time `json:"bootCompletedAt,omitempty"`
+	// The last time the device was successfully checked over ADB.
+	LastHealthCheck *metav1.Time `json:"lastHealthCheck,omitempty"`
+	// The state of the health checks and remediation of the device, when its
+	// configuration has a health policy.
+	Health *DeviceHealthStatus `json:"health,omitempty"`
+	// The state of the PVCs attached to the device.
+	Volumes []DeviceVolumeStatus `json:"volumes,omitempty"`
+	// The node a physical device is attached to.
+	NodeName string `json:"nodeName,omitempty"`
+	// The properties reported for a physical device by the ADB server it is
+	// attached to.
+	Properties *DeviceProperties `json:"properties,omitempty"`
+	// Conditions observed on the device.
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// DeviceVolumeStatus represents the observed state of a PVC attached to a
+// device.
+type DeviceVolumeStatus struct {
+	// The name of the PVC.
+	ClaimName string `json:"claimName"`
+	// The phase of the PVC, e.g. Pending or Bound.
+	Phase corev1.PersistentVolumeClaimPhase `json:"phase,omitempty"`
+}
+
+// DeviceProperties represents the hardware and software properties of a
+// physical device.
+type DeviceProperties struct {
+	// The manufacturer of the device.
+	Manufacturer string `json:"manufacturer,omitempty"`
+	// The model of the device.
+	Model string `json:"model,omitempty"`
+	// The SDK level of the Android version running on the device.
+	SDK string `json:"sdk,omitempty"`
+	// The Android version running on the device.
+	Release string `json:"release,omitempty"`
+	// The primary ABI of the device.
+	ABI string `json:"abi,omitempty"`
+	// The battery level of the device in percent.
+	BatteryLevel *int32 `json:"batteryLevel,omitempty"`
+}
+
+// RemediationAction represents an action taken to recover an unhealthy device.
+type RemediationAction string
+
+const (
+	// RemediationReconnect reconnects the device to the ADB server of its STF
+	// provider.
+	RemediationReconnect RemediationAction = "Reconnect"
+	// RemediationReboot reboots the emulator over ADB.
+	RemediationReboot RemediationAction = "Reboot"
+	// RemediationRecreate deletes the device pod so that a new one is created.
+	RemediationRecreate RemediationAction = "Recreate"
+)
+
+// DeviceHealthStatus represents the observed health of a device.
+type DeviceHealthStatus struct {
+	// The last time the health checks were run.
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// The number of consecutive failed health checks.
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// The reason the last failed health check failed.
+	LastFailure string `json:"lastFailure,omitempty"`
+	// The last remediation action taken on the device. This is cleared once the
+	// device passes its health checks again.
+	LastAction RemediationAction `json:"lastAction,omitempty"`
+	// The time the last remediation action was taken.
+	LastActionTime *metav1.Time `json:"lastActionTime,omitempty"`
+	// A hash of the screen at the last check, used by ScreenNotFrozen checks.
+	ScreenHash string `json:"screenHash,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -29,6 +147,13 @@ type AndroidDeviceStatus struct {
 // AndroidDevice is the Schema for the androiddevices API
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=androiddevices,scope=Namespaced
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="IP",type="string",JSONPath=".status.podIP"
+// +kubebuilder:printcolumn:name="Serial",type="string",JSONPath=".status.adbSerial"
+// +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.source",priority=1
+// +kubebuilder:printcolumn:name="Model",type="string",JSONPath=".status.properties.model",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type AndroidDevice struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
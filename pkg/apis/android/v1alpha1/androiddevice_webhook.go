@@ -0,0 +1,70 @@
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-android-stf-io-v1alpha1-androiddevice,mutating=true,failurePolicy=fail,groups=android.stf.io,resources=androiddevices,verbs=create;update,versions=v1alpha1,name=mandroiddevice.android.stf.io
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androiddevice,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androiddevices,verbs=create;update,versions=v1alpha1,name=vandroiddevice.android.stf.io
+
+var _ webhook.Defaulter = &AndroidDevice{}
+var _ webhook.Validator = &AndroidDevice{}
+
+// Default applies default values to an AndroidDevice. Devices referencing an
+// AndroidDeviceConfig are labeled with its name so they are picked up when the
+// configuration changes.
+func (a *AndroidDevice) Default() {
+	if a.Spec.ConfigRef == nil || a.Spec.ConfigRef.Name == "" {
+		return
+	}
+	if a.Labels == nil {
+		a.Labels = make(map[string]string)
+	}
+	if _, ok := a.Labels[DeviceConfigLabel]; !ok {
+		a.Labels[DeviceConfigLabel] = a.Spec.ConfigRef.Name
+	}
+}
+
+// ValidateCreate validates a new AndroidDevice.
+func (a *AndroidDevice) ValidateCreate() error {
+	return a.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidDevice.
+func (a *AndroidDevice) ValidateUpdate(old runtime.Object) error {
+	return a.validate()
+}
+
+// ValidateDelete validates the deletion of an AndroidDevice. Deletions are
+// always allowed.
+func (a *AndroidDevice) ValidateDelete() error { return nil }
+
+func (a *AndroidDevice) validate() error {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	switch a.Spec.Source {
+	case "", EmulatorSource, PhysicalSource:
+	default:
+		errs = append(errs, field.NotSupported(specPath.Child("source"), a.Spec.Source, []string{string(EmulatorSource), string(PhysicalSource)}))
+	}
+	// physical devices are not provisioned from a configuration
+	if a.IsPhysicalDevice() {
+		if a.Spec.ConfigRef != nil || a.Spec.DeviceConfig != nil {
+			errs = append(errs, field.Forbidden(specPath.Child("source"), "physical devices cannot specify a configRef or deviceConfig"))
+		}
+	} else if a.Spec.ConfigRef == nil && (a.Spec.DeviceConfig == nil || a.Spec.DeviceConfig.DockerImage == "") {
+		errs = append(errs, field.Required(specPath.Child("configRef"), "a configRef is required unless a deviceConfig with a dockerImage is provided"))
+	} else if a.Spec.ConfigRef != nil && a.Spec.ConfigRef.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("configRef", "name"), "the configRef must reference an AndroidDeviceConfig by name"))
+	}
+	if a.Spec.DeviceConfig != nil {
+		errs = append(errs, a.Spec.DeviceConfig.validate(specPath.Child("deviceConfig"))...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, errs)
+}
@@ -37,6 +37,32 @@ type AndroidDeviceConfigSpec struct {
 	StartupJobTemplates []string `json:"startupJobTemplates,omitempty"`
 	// Configuration for the tcp redirection side car
 	TCPRedir *TCPRedirConfig `json:"tcpRedir,omitempty"`
+	// Configuration for booting emulators from a golden quick-boot snapshot
+	// instead of cold booting them.
+	QuickBoot *QuickBootConfig `json:"quickBoot,omitempty"`
+	// A policy for checking the health of booted emulators and remediating
+	// the ones that fail.
+	HealthPolicy *HealthPolicy `json:"healthPolicy,omitempty"`
+	// Scheduling constraints for the emulator pods. Device groups can override
+	// these in their deviceConfig.
+	Scheduling *SchedulingConfig `json:"scheduling,omitempty"`
+}
+
+// SchedulingConfig represents the scheduling constraints for emulator pods.
+// When neither a pod anti-affinity nor topology spread constraints are given,
+// the devices of a group prefer to be spread across nodes.
+type SchedulingConfig struct {
+	// A node selector to apply to the emulator pods.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// The affinity of the emulator pods.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Tolerations to apply to the emulator pods.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// The priority class of the emulator pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Topology spread constraints for the emulator pods. These require the
+	// EvenPodsSpread feature gate on kubernetes versions before 1.18.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
 // Volume represents a volume configuration for the emulator.
@@ -47,8 +73,27 @@ type Volume struct {
 	MountPoint string `json:"mountPoint"`
 	// A PVC spec to use for creating the emulator volumes.
 	PVCSpec corev1.PersistentVolumeClaimSpec `json:"pvcSpec"`
+	// What to do with the PVCs of devices when they are deleted or their
+	// configuration changes. Defaults to Retain.
+	RetentionPolicy VolumeRetentionPolicy `json:"retentionPolicy,omitempty"`
 }
 
+// VolumeRetentionPolicy represents what happens to the PVCs of emulator devices
+// when they are deleted or their configuration changes.
+type VolumeRetentionPolicy string
+
+const (
+	// VolumeRetain keeps PVCs when devices are deleted, so they are reused when
+	// a device with the same name is created again.
+	VolumeRetain VolumeRetentionPolicy = "Retain"
+	// VolumeDelete makes the device the owner of its PVCs, so they are deleted
+	// along with it.
+	VolumeDelete VolumeRetentionPolicy = "Delete"
+	// VolumeWipeOnConfigChange keeps PVCs when devices are deleted, but replaces
+	// them with empty ones when the configuration of the device changes.
+	VolumeWipeOnConfigChange VolumeRetentionPolicy = "WipeOnConfigChange"
+)
+
 type TCPRedirConfig struct {
 	// Whether to run a sidecar with emulator pods that redirects TCP traffic on the adb port
 	// to the emulator adb server listening on the loopback interface. This is required
@@ -64,6 +109,66 @@ type TCPRedirConfig struct {
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
+// QuickBootConfig configures golden snapshots for emulators. A golden snapshot
+// is taken once for every configuration checksum, in every namespace with devices
+// using the configuration, and emulator pods are booted from it. The emulator
+// image must support the QUICKBOOT_MODE and QUICKBOOT_DIR environment variables,
+// like the image in this repository does.
+type QuickBootConfig struct {
+	// A PVC spec to use for the volume holding the golden snapshot. Unless a
+	// volumeSnapshotClassName is provided, this volume is mounted read-only into
+	// every emulator pod, so its access modes should allow that (e.g. ReadOnlyMany).
+	PVCSpec corev1.PersistentVolumeClaimSpec `json:"pvcSpec"`
+	// When set, a VolumeSnapshot is taken of the golden volume with this class,
+	// and every emulator pod is given its own PVC cloned from it.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// HealthPolicy configures periodic health checks against booted emulators.
+// When the checks fail failureThreshold times in a row, the device is
+// remediated, escalating from reconnecting it to its STF provider, to rebooting
+// the emulator, to recreating its pod.
+type HealthPolicy struct {
+	// How often to run the checks, in seconds. Defaults to 60.
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+	// The checks to run against the device. Defaults to ADBReachable and
+	// BootCompleted.
+	Checks []HealthCheck `json:"checks,omitempty"`
+	// The number of consecutive failed checks before the device is remediated.
+	// Defaults to 3.
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// HealthCheckType represents a type of health check run against an emulator.
+type HealthCheckType string
+
+const (
+	// ADBReachable checks that the emulator accepts ADB connections and is not
+	// offline.
+	ADBReachable HealthCheckType = "ADBReachable"
+	// BootCompleted checks that sys.boot_completed is still set on the emulator.
+	BootCompleted HealthCheckType = "BootCompleted"
+	// ShellProbe runs a shell command on the emulator and checks its output.
+	ShellProbe HealthCheckType = "ShellProbe"
+	// ScreenNotFrozen checks that the screen is not entirely black and has
+	// changed since the previous check. Since an idle emulator may leave its
+	// screen untouched, this is best used with a long interval or a device that
+	// is known to be animating.
+	ScreenNotFrozen HealthCheckType = "ScreenNotFrozen"
+)
+
+// HealthCheck represents a single check run against an emulator.
+type HealthCheck struct {
+	// The type of the check.
+	Type HealthCheckType `json:"type"`
+	// For ShellProbe checks, the shell command to run on the device. The check
+	// fails if the command exits non-zero.
+	Command string `json:"command,omitempty"`
+	// For ShellProbe checks, a string that must appear in the output of the
+	// command.
+	ExpectedOutput string `json:"expectedOutput,omitempty"`
+}
+
 // AndroidDeviceConfigStatus defines the observed state of AndroidDeviceConfig
 type AndroidDeviceConfigStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -76,6 +181,7 @@ type AndroidDeviceConfigStatus struct {
 // AndroidDeviceConfig is the Schema for the androiddeviceconfigs API
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=androiddeviceconfigs,scope=Cluster
+// +kubebuilder:storageversion
 type AndroidDeviceConfig struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
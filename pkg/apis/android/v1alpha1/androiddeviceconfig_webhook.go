@@ -0,0 +1,117 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-android-stf-io-v1alpha1-androiddeviceconfig,mutating=true,failurePolicy=fail,groups=android.stf.io,resources=androiddeviceconfigs,verbs=create;update,versions=v1alpha1,name=mandroiddeviceconfig.android.stf.io
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androiddeviceconfig,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androiddeviceconfigs,verbs=create;update,versions=v1alpha1,name=vandroiddeviceconfig.android.stf.io
+
+var _ webhook.Defaulter = &AndroidDeviceConfig{}
+var _ webhook.Validator = &AndroidDeviceConfig{}
+
+// Default applies default values to an AndroidDeviceConfig. Overrides embedded
+// in devices and farms are not defaulted, since any value set on them takes
+// precedence over the referenced configuration.
+func (c *AndroidDeviceConfig) Default() {
+	c.Spec.ADBPort = c.GetADBPort()
+	if c.Spec.TCPRedir != nil && c.Spec.TCPRedir.Enabled {
+		c.Spec.TCPRedir.Image = c.GetRedirImage()
+	}
+	if policy := c.Spec.HealthPolicy; policy != nil {
+		policy.IntervalSeconds = int32(policy.GetInterval().Seconds())
+		policy.Checks = policy.GetChecks()
+		policy.FailureThreshold = policy.GetFailureThreshold()
+	}
+}
+
+// ValidateCreate validates a new AndroidDeviceConfig.
+func (c *AndroidDeviceConfig) ValidateCreate() error {
+	return c.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidDeviceConfig.
+func (c *AndroidDeviceConfig) ValidateUpdate(old runtime.Object) error {
+	return c.validate()
+}
+
+// ValidateDelete validates the deletion of an AndroidDeviceConfig. Deletions
+// are always allowed.
+func (c *AndroidDeviceConfig) ValidateDelete() error { return nil }
+
+func (c *AndroidDeviceConfig) validate() error {
+	errs := c.Spec.validate(field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(c.GroupVersionKind().GroupKind(), c.Name, errs)
+}
+
+func (c *AndroidDeviceConfigSpec) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if c.ADBPort < 0 || c.ADBPort > 65535 {
+		errs = append(errs, field.Invalid(path.Child("adbPort"), c.ADBPort, "must be a valid port number"))
+	}
+	prefixes := make(map[string]struct{})
+	for idx, volume := range c.Volumes {
+		volPath := path.Child("volumes").Index(idx)
+		if volume.VolumePrefix == "" {
+			errs = append(errs, field.Required(volPath.Child("volumePrefix"), "volumes must have a prefix to apply to device PVCs"))
+		} else if _, ok := prefixes[volume.VolumePrefix]; ok {
+			errs = append(errs, field.Duplicate(volPath.Child("volumePrefix"), volume.VolumePrefix))
+		}
+		prefixes[volume.VolumePrefix] = struct{}{}
+		if volume.MountPoint == "" {
+			errs = append(errs, field.Required(volPath.Child("mountPoint"), "volumes must have a mount point in the emulator pods"))
+		}
+		switch volume.RetentionPolicy {
+		case "", VolumeRetain, VolumeDelete, VolumeWipeOnConfigChange:
+		default:
+			errs = append(errs, field.NotSupported(volPath.Child("retentionPolicy"), volume.RetentionPolicy, []string{
+				string(VolumeRetain), string(VolumeDelete), string(VolumeWipeOnConfigChange),
+			}))
+		}
+	}
+	if c.QuickBoot != nil {
+		pvcPath := path.Child("quickBoot", "pvcSpec")
+		if len(c.QuickBoot.PVCSpec.AccessModes) == 0 {
+			errs = append(errs, field.Required(pvcPath.Child("accessModes"), "the golden snapshot volume must have at least one access mode"))
+		}
+		if _, ok := c.QuickBoot.PVCSpec.Resources.Requests[corev1.ResourceStorage]; !ok {
+			errs = append(errs, field.Required(pvcPath.Child("resources", "requests", "storage"), "the golden snapshot volume must request storage"))
+		}
+	}
+	if c.HealthPolicy != nil {
+		errs = append(errs, c.HealthPolicy.validate(path.Child("healthPolicy"))...)
+	}
+	return errs
+}
+
+func (h *HealthPolicy) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if h.IntervalSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("intervalSeconds"), h.IntervalSeconds, "must not be negative"))
+	}
+	if h.FailureThreshold < 0 {
+		errs = append(errs, field.Invalid(path.Child("failureThreshold"), h.FailureThreshold, "must not be negative"))
+	}
+	for idx, check := range h.Checks {
+		checkPath := path.Child("checks").Index(idx)
+		switch check.Type {
+		case ADBReachable, BootCompleted, ScreenNotFrozen:
+		case ShellProbe:
+			if check.Command == "" {
+				errs = append(errs, field.Required(checkPath.Child("command"), "shell probes must have a command to run"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(checkPath.Child("type"), check.Type, []string{
+				string(ADBReachable), string(BootCompleted), string(ShellProbe), string(ScreenNotFrozen),
+			}))
+		}
+	}
+	return errs
+}
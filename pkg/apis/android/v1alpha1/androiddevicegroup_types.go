@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AndroidDeviceGroupSpec defines the desired state of AndroidDeviceGroup
+type AndroidDeviceGroupSpec struct {
+	// The desired number of devices in the group. This is initialized from the
+	// count of the emulator group in the AndroidFarm, and overwritten whenever
+	// that count changes. It cannot exceed the maxCount of the emulator group.
+	Replicas int32 `json:"replicas"`
+}
+
+// AndroidDeviceGroupStatus defines the observed state of AndroidDeviceGroup
+type AndroidDeviceGroupStatus struct {
+	// The number of AndroidDevices that have been created for the group.
+	Replicas int32 `json:"replicas"`
+	// The number of devices that have completed booting.
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// The label selector for the devices and pods in the group.
+	Selector string `json:"selector,omitempty"`
+	// The number of devices that are in use in OpenSTF. This is only reported for
+	// groups with an autoscaling policy.
+	InUseReplicas int32 `json:"inUseReplicas,omitempty"`
+	// The last time the group was scaled by its autoscaling policy.
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AndroidDeviceGroup is the Schema for the androiddevicegroups API. A device group
+// is created by the AndroidFarm controller for each emulated device group in
+// a farm, and allows the group to be scaled independently of the farm.
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:resource:path=androiddevicegroups,scope=Namespaced
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas"
+// +kubebuilder:printcolumn:name="Current",type="integer",JSONPath=".status.replicas"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+// +kubebuilder:printcolumn:name="In Use",type="integer",JSONPath=".status.inUseReplicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type AndroidDeviceGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AndroidDeviceGroupSpec   `json:"spec,omitempty"`
+	Status AndroidDeviceGroupStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AndroidDeviceGroupList contains a list of AndroidDeviceGroup
+type AndroidDeviceGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AndroidDeviceGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AndroidDeviceGroup{}, &AndroidDeviceGroupList{})
+}
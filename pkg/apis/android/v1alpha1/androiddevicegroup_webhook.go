@@ -0,0 +1,37 @@
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androiddevicegroup,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androiddevicegroups,verbs=create;update,versions=v1alpha1,name=vandroiddevicegroup.android.stf.io
+
+var _ webhook.Validator = &AndroidDeviceGroup{}
+
+// ValidateCreate validates a new AndroidDeviceGroup.
+func (a *AndroidDeviceGroup) ValidateCreate() error {
+	return a.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidDeviceGroup.
+func (a *AndroidDeviceGroup) ValidateUpdate(old runtime.Object) error {
+	return a.validate()
+}
+
+// ValidateDelete validates the deletion of an AndroidDeviceGroup. Deletions are
+// always allowed.
+func (a *AndroidDeviceGroup) ValidateDelete() error { return nil }
+
+func (a *AndroidDeviceGroup) validate() error {
+	errs := field.ErrorList{}
+	if a.Spec.Replicas < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "replicas"), a.Spec.Replicas, "must be greater than or equal to 0"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, errs)
+}
@@ -0,0 +1,86 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AndroidDeviceLeaseSpec defines the desired state of AndroidDeviceLease
+type AndroidDeviceLeaseSpec struct {
+	// Labels used to select the AndroidDevices in the namespace of the lease that
+	// can be acquired. When empty, any device in the namespace can be acquired.
+	DeviceSelector map[string]string `json:"deviceSelector,omitempty"`
+	// The number of seconds to hold the device for after it has been acquired.
+	DurationSeconds int32 `json:"durationSeconds"`
+	// The user to mark as the owner of the device in OpenSTF. Defaults to a user
+	// named after the lease.
+	Owner *LeaseOwner `json:"owner,omitempty"`
+}
+
+// LeaseOwner represents the OpenSTF user that a leased device is assigned to.
+type LeaseOwner struct {
+	// The email address of the owner.
+	Email string `json:"email,omitempty"`
+	// The display name of the owner.
+	Name string `json:"name,omitempty"`
+}
+
+// LeasePhase represents the current phase in the lifecycle of an AndroidDeviceLease.
+type LeasePhase string
+
+const (
+	// LeasePending means the lease is waiting for a free device.
+	LeasePending LeasePhase = "Pending"
+	// LeaseActive means the lease holds a device.
+	LeaseActive LeasePhase = "Active"
+	// LeaseExpired means the lease has ended and its device was released.
+	LeaseExpired LeasePhase = "Expired"
+)
+
+// AndroidDeviceLeaseStatus defines the observed state of AndroidDeviceLease
+type AndroidDeviceLeaseStatus struct {
+	// The current phase of the lease.
+	Phase LeasePhase `json:"phase,omitempty"`
+	// The name of the leased AndroidDevice.
+	DeviceName string `json:"deviceName,omitempty"`
+	// The address of the ADB server of the leased device, in the form host:port.
+	ADBEndpoint string `json:"adbEndpoint,omitempty"`
+	// The time the device was acquired.
+	AcquiredAt *metav1.Time `json:"acquiredAt,omitempty"`
+	// The time the lease expires.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// A human readable message with details about the phase of the lease.
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AndroidDeviceLease is the Schema for the androiddeviceleases API. A lease
+// acquires a free, booted AndroidDevice for a period of time and marks it as in
+// use in OpenSTF.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=androiddeviceleases,scope=Namespaced
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Device",type="string",JSONPath=".status.deviceName"
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".status.adbEndpoint"
+// +kubebuilder:printcolumn:name="Expires",type="date",JSONPath=".status.expiresAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type AndroidDeviceLease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AndroidDeviceLeaseSpec   `json:"spec,omitempty"`
+	Status AndroidDeviceLeaseStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AndroidDeviceLeaseList contains a list of AndroidDeviceLease
+type AndroidDeviceLeaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AndroidDeviceLease `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AndroidDeviceLease{}, &AndroidDeviceLeaseList{})
+}
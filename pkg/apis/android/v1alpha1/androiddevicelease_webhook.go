@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-android-stf-io-v1alpha1-androiddevicelease,mutating=true,failurePolicy=fail,groups=android.stf.io,resources=androiddeviceleases,verbs=create;update,versions=v1alpha1,name=mandroiddevicelease.android.stf.io
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androiddevicelease,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androiddeviceleases,verbs=create;update,versions=v1alpha1,name=vandroiddevicelease.android.stf.io
+
+var _ webhook.Defaulter = &AndroidDeviceLease{}
+var _ webhook.Validator = &AndroidDeviceLease{}
+
+// Default applies default values to an AndroidDeviceLease.
+func (a *AndroidDeviceLease) Default() {
+	if a.Spec.Owner == nil {
+		a.Spec.Owner = &LeaseOwner{}
+	}
+	a.Spec.Owner.Email = a.GetOwnerEmail()
+	a.Spec.Owner.Name = a.GetOwnerName()
+}
+
+// ValidateCreate validates a new AndroidDeviceLease.
+func (a *AndroidDeviceLease) ValidateCreate() error {
+	return a.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidDeviceLease. The spec of a
+// lease cannot be changed once it has acquired a device.
+func (a *AndroidDeviceLease) ValidateUpdate(old runtime.Object) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	oldLease := old.(*AndroidDeviceLease)
+	if oldLease.Status.DeviceName != "" && !reflect.DeepEqual(a.Spec, oldLease.Spec) {
+		return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, field.ErrorList{
+			field.Forbidden(field.NewPath("spec"), "the spec of a lease cannot be changed after it has acquired a device"),
+		})
+	}
+	return nil
+}
+
+// ValidateDelete validates the deletion of an AndroidDeviceLease. Deletions are
+// always allowed.
+func (a *AndroidDeviceLease) ValidateDelete() error { return nil }
+
+func (a *AndroidDeviceLease) validate() error {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if a.Spec.DurationSeconds <= 0 {
+		errs = append(errs, field.Invalid(specPath.Child("durationSeconds"), a.Spec.DurationSeconds, "must be greater than 0"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, errs)
+}
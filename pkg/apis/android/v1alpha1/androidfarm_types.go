@@ -4,12 +4,22 @@ import (
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// PodManagementPolicy is the strategy used for creating and updating the
+// devices in a device group.
 type PodManagementPolicy string
 
 const (
+	// GroupedOrderedReady creates and updates the devices in a group in order, with
+	// at most the configured concurrency booting at once.
 	GroupedOrderedReady PodManagementPolicy = "GroupedOrderedReady"
+	// Parallel creates and updates all the devices in a group at once.
+	Parallel PodManagementPolicy = "Parallel"
+	// RollingUpdate creates devices at once and replaces outdated devices a few at
+	// a time, within the bounds of maxUnavailable and maxSurge.
+	RollingUpdate PodManagementPolicy = "RollingUpdate"
 )
 
 // AndroidFarmSpec defines the desired state of AndroidFarm
@@ -37,6 +47,9 @@ type DeviceGroup struct {
 	Emulators *EmulatorConfig `json:"emulators,omitempty"`
 	// A configuration for connecting host usb devices to the AndroidFarm.
 	HostUSB *HostUSBConfig `json:"hostUSB,omitempty"`
+	// A policy for scaling an emulated device group based on the number of its
+	// devices that are in use in OpenSTF.
+	Autoscaling *AutoscalingPolicy `json:"autoscaling,omitempty"`
 	// TODO: implement
 	OmitFromSTF bool `json:"omitFromSTF,omitempty"`
 }
@@ -72,6 +85,10 @@ type EmulatorConfig struct {
 	Namespace string `json:"namespace,omitempty"`
 	// The number of devices to run in the group. Defaults to no devices.
 	Count int32 `json:"count,omitempty"`
+	// The maximum number of devices the group can be scaled to through its
+	// AndroidDeviceGroup. Provider ports are allocated for this many devices, so
+	// this should be set on groups that are scaled externally. Defaults to count.
+	MaxCount int32 `json:"maxCount,omitempty"`
 	// A go-template to use for configuring the hostname of the devices. Currently
 	// only {{ .Index }} is passed to thte template, but more will come. A headless
 	// service is put in front of device groups to make the individual pods accessible
@@ -94,21 +111,81 @@ type EmulatorConfig struct {
 // HostUSBConfig is a configuration for connecting devices attached physically
 // to the kubernetes hosts.
 type HostUSBConfig struct {
+	// The namespace to create AndroidDevices in for the devices attached to the
+	// providers of this group. Defaults to default.
+	Namespace string `json:"namespace,omitempty"`
 	// The node to launch an ADB server on for binding devices to STF.
 	NodeName string `json:"nodeName,omitempty"`
 	// Specify the maximum number of devices expected to run on this host. This
 	// is required because for lack of a better solution we dynamically allocate provider
 	// service ports at the moment and we need to determine how many to do for a usb farm.
 	MaxDevices int32 `json:"maxDevices,omitempty"`
+	// Labels selecting the nodes to launch ADB servers on. A provider is run on
+	// every matching node, each with its own range of ports, and nodes joining
+	// or leaving the selector are picked up automatically. Cannot be used with
+	// NodeName.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// The maximum number of nodes expected to match the NodeSelector. Provider
+	// ports are allocated for this many nodes, with MaxDevices on each. Nodes
+	// matching beyond this are not given a provider. Defaults to 1.
+	MaxNodes int32 `json:"maxNodes,omitempty"`
+}
+
+// AutoscalingPolicy represents a policy for scaling an emulated device group
+// based on the number of its devices that are owned by users in OpenSTF. Devices
+// are only removed when they are idle and have the highest indices in the group.
+type AutoscalingPolicy struct {
+	// The minimum number of devices to run in the group. Defaults to 0.
+	MinCount int32 `json:"minCount,omitempty"`
+	// The maximum number of devices to run in the group. Provider ports are
+	// allocated for this many devices.
+	MaxCount int32 `json:"maxCount"`
+	// The percentage of devices in the group that should be in use. The group is
+	// scaled up when utilization rises above this value, and scaled down when it
+	// falls below it. Defaults to 80.
+	TargetUtilization int32 `json:"targetUtilization,omitempty"`
+	// The number of seconds to wait after the group was last scaled before scaling
+	// it up again. Defaults to 60.
+	ScaleUpCooldownSeconds *int32 `json:"scaleUpCooldownSeconds,omitempty"`
+	// The number of seconds to wait after the group was last scaled before scaling
+	// it down again. Defaults to 300.
+	ScaleDownCooldownSeconds *int32 `json:"scaleDownCooldownSeconds,omitempty"`
 }
 
 // DeviceManagementPolicy represents a policy for managing concurrency during
 // the creation and updating of emulator pods.
 type DeviceManagementPolicy struct {
-	// The type of policy to enforce, currently only OrderedReady.
+	// The type of policy to enforce, one of GroupedOrderedReady, Parallel or
+	// RollingUpdate. Defaults to GroupedOrderedReady.
 	PodManagementPolicy PodManagementPolicy `json:"podManagementPolicy,omitempty"`
 	// The maximum number of devices that can be booting at any point in time.
+	// Only used by the GroupedOrderedReady policy.
 	Concurrency int32 `json:"concurrency,omitempty"`
+	// Configuration for the RollingUpdate policy.
+	RollingUpdate *RollingUpdateConfig `json:"rollingUpdate,omitempty"`
+	// When the configuration of a group changes, only devices with an index greater
+	// than or equal to the partition are updated. This can be used to canary a new
+	// configuration on the highest index devices first, and lowered to roll it out
+	// to the rest of the group. Defaults to 0.
+	Partition int32 `json:"partition,omitempty"`
+	// When true, no more devices are updated until the rollout is resumed. Devices
+	// are still created and removed as the group is scaled.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RollingUpdateConfig configures how outdated devices are replaced with the
+// RollingUpdate policy.
+type RollingUpdateConfig struct {
+	// The maximum number of desired devices that can be unavailable while outdated
+	// devices are replaced. This can be an absolute number or a percentage of the
+	// desired devices, rounded down. Defaults to 1.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// The maximum number of devices that can be created above the desired count
+	// while outdated devices are replaced. This can be an absolute number or a
+	// percentage of the desired devices, rounded up. Surge devices are only created
+	// up to the maxCount of the group, since the provider only reserves ports for
+	// that many devices. Defaults to 0.
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 }
 
 // STFConfig represents configuration options for the OpenSTF deployment in this
@@ -363,14 +440,79 @@ type TraefikDashboard struct {
 
 // AndroidFarmStatus defines the observed state of AndroidFarm
 type AndroidFarmStatus struct {
-	State string `json:"state"`
+	// The most recent generation of the AndroidFarm that was fully reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions for the individual components of the AndroidFarm. When the
+	// controller is waiting on a component, the reason is reported on the first
+	// condition that is not yet true.
+	Conditions []Condition `json:"conditions,omitempty"`
+	// The observed state of each emulated device group in the farm.
+	DeviceGroups []DeviceGroupStatus `json:"deviceGroups,omitempty"`
+	// The KVM capable nodes discovered in the cluster and the emulators of the
+	// farm running on them. Nodes are discovered by the KVM discovery daemon.
+	Nodes []NodeStatus `json:"nodes,omitempty"`
+}
+
+// NodeStatus represents a KVM capable node and the capacity it has for the
+// emulators in a farm.
+type NodeStatus struct {
+	// The name of the node.
+	Name string `json:"name"`
+	// Whether nested virtualization is enabled on the node.
+	NestedVirtualization bool `json:"nestedVirtualization,omitempty"`
+	// The number of emulators in the farm running on the node.
+	Devices int32 `json:"devices"`
+	// The number of emulators of the most demanding configuration in the farm
+	// that fit in the allocatable resources of the node. Unset when the
+	// configurations do not declare resource requests or limits.
+	Capacity int32 `json:"capacity,omitempty"`
 }
 
+// DeviceGroupStatus represents the observed state of the devices in a device
+// group.
+type DeviceGroupStatus struct {
+	// The name of the device group.
+	Name string `json:"name"`
+	// The number of devices desired in the group.
+	Desired int32 `json:"desired"`
+	// The number of AndroidDevices that have been created for the group.
+	Created int32 `json:"created"`
+	// The number of devices that have completed booting.
+	Booted int32 `json:"booted"`
+	// The number of devices that are connected to their STF provider.
+	STFBound int32 `json:"stfBound"`
+	// The number of devices running the current configuration of the group.
+	Updated int32 `json:"updated"`
+	// The state of the rollout of the current configuration to the devices in the
+	// group.
+	Rollout RolloutPhase `json:"rollout,omitempty"`
+}
+
+// RolloutPhase represents the state of the rollout of a configuration to the
+// devices in a device group.
+type RolloutPhase string
+
+const (
+	// RolloutComplete means all devices run the current configuration.
+	RolloutComplete RolloutPhase = "Complete"
+	// RolloutProgressing means outdated devices are being replaced.
+	RolloutProgressing RolloutPhase = "Progressing"
+	// RolloutPaused means the rollout was paused before all devices were updated.
+	RolloutPaused RolloutPhase = "Paused"
+	// RolloutPartitioned means all devices at or above the partition are updated,
+	// and the devices below it are held at their previous configuration.
+	RolloutPartitioned RolloutPhase = "Partitioned"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // AndroidFarm is the Schema for the androidfarms API
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=androidfarms,scope=Cluster
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type AndroidFarm struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,347 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"html/template"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-android-stf-io-v1alpha1-androidfarm,mutating=true,failurePolicy=fail,groups=android.stf.io,resources=androidfarms,verbs=create;update,versions=v1alpha1,name=mandroidfarm.android.stf.io
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androidfarm,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androidfarms,verbs=create;update,versions=v1alpha1,name=vandroidfarm.android.stf.io
+
+var _ webhook.Defaulter = &AndroidFarm{}
+var _ webhook.Validator = &AndroidFarm{}
+
+// Default applies default values to an AndroidFarm.
+func (a *AndroidFarm) Default() {
+	for _, group := range a.DeviceGroups() {
+		if group.Emulators != nil {
+			group.Emulators.Namespace = group.GetNamespace()
+			group.Emulators.Subdomain = group.GetSubdomain()
+		}
+		if group.HostUSB != nil {
+			group.HostUSB.Namespace = group.GetNamespace()
+		}
+		if group.Provider == nil {
+			group.Provider = &ProviderConfig{}
+		}
+		group.Provider.StartPort = group.GetProviderStartPort()
+		if group.Autoscaling != nil {
+			group.Autoscaling.Default()
+		}
+	}
+	if policy := a.Spec.DeviceManagementPolicy; policy != nil {
+		policy.Default()
+	}
+	for _, group := range a.DeviceGroups() {
+		if group.Emulators != nil && group.Emulators.DeviceManagementPolicy != nil {
+			group.Emulators.DeviceManagementPolicy.Default()
+		}
+	}
+	if a.STFDisabled() {
+		return
+	}
+	stf := a.STFConfig()
+	stf.Namespace = stf.GetNamespace()
+	stf.STFSecretKey = stf.GetSTFSecretKey()
+	if stf.Auth != nil && stf.Auth.OAuth != nil {
+		stf.Auth.OAuth.ClientIDKey = stf.GetOAuthClientIDKey()
+		stf.Auth.OAuth.ClientSecretKey = stf.GetOAuthClientSecretKey()
+	}
+}
+
+// Default applies default values to a DeviceManagementPolicy.
+func (d *DeviceManagementPolicy) Default() {
+	d.PodManagementPolicy = d.GetPodManagementPolicy()
+	d.Concurrency = d.GetConcurrency()
+	if d.PodManagementPolicy == RollingUpdate {
+		if d.RollingUpdate == nil {
+			d.RollingUpdate = &RollingUpdateConfig{}
+		}
+		if d.RollingUpdate.MaxUnavailable == nil {
+			maxUnavailable := intstr.FromInt(1)
+			d.RollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		if d.RollingUpdate.MaxSurge == nil {
+			maxSurge := intstr.FromInt(0)
+			d.RollingUpdate.MaxSurge = &maxSurge
+		}
+	}
+}
+
+// Default applies default values to an AutoscalingPolicy.
+func (a *AutoscalingPolicy) Default() {
+	a.TargetUtilization = a.GetTargetUtilization()
+	if a.ScaleUpCooldownSeconds == nil {
+		cooldown := int32(a.GetScaleUpCooldown().Seconds())
+		a.ScaleUpCooldownSeconds = &cooldown
+	}
+	if a.ScaleDownCooldownSeconds == nil {
+		cooldown := int32(a.GetScaleDownCooldown().Seconds())
+		a.ScaleDownCooldownSeconds = &cooldown
+	}
+}
+
+// ValidateCreate validates a new AndroidFarm.
+func (a *AndroidFarm) ValidateCreate() error {
+	return a.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidFarm.
+func (a *AndroidFarm) ValidateUpdate(old runtime.Object) error {
+	return a.validate()
+}
+
+// ValidateDelete validates the deletion of an AndroidFarm. Deletions are always
+// allowed.
+func (a *AndroidFarm) ValidateDelete() error { return nil }
+
+func (a *AndroidFarm) validate() error {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	groupNames := make(map[string]struct{})
+	for idx, group := range a.DeviceGroups() {
+		groupPath := specPath.Child("deviceGroups").Index(idx)
+		if group.Name == "" {
+			errs = append(errs, field.Required(groupPath.Child("name"), "device groups must have a name"))
+		} else if _, ok := groupNames[group.Name]; ok {
+			errs = append(errs, field.Duplicate(groupPath.Child("name"), group.Name))
+		}
+		groupNames[group.Name] = struct{}{}
+		errs = append(errs, group.validate(groupPath)...)
+	}
+
+	if policy := a.Spec.DeviceManagementPolicy; policy != nil {
+		errs = append(errs, policy.validate(specPath.Child("deviceManagementPolicy"))...)
+	}
+
+	if !a.STFDisabled() {
+		errs = append(errs, a.STFConfig().validate(specPath.Child("stfConfig"))...)
+		errs = append(errs, a.validateProviderPorts(specPath.Child("deviceGroups"))...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, errs)
+}
+
+// validateProviderPorts ensures the provider port ranges of the device groups
+// in the farm do not overlap.
+func (a *AndroidFarm) validateProviderPorts(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	groups := a.DeviceGroups()
+	for i, group := range groups {
+		for j := 0; j < i; j++ {
+			other := groups[j]
+			if group.GetProviderStartPort() <= other.GetProviderMaxPort() && other.GetProviderStartPort() <= group.GetProviderMaxPort() {
+				errs = append(errs, field.Invalid(
+					path.Index(i).Child("provider", "startPort"),
+					group.GetProviderStartPort(),
+					fmt.Sprintf(
+						"provider ports %d-%d overlap with ports %d-%d of device group %q",
+						group.GetProviderStartPort(), group.GetProviderMaxPort(),
+						other.GetProviderStartPort(), other.GetProviderMaxPort(), other.Name,
+					),
+				))
+			}
+		}
+	}
+	return errs
+}
+
+func (g *DeviceGroup) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	switch {
+	case g.Emulators != nil && g.HostUSB != nil:
+		errs = append(errs, field.Forbidden(path.Child("hostUSB"), "a device group cannot specify both emulators and hostUSB"))
+	case g.Emulators == nil && g.HostUSB == nil:
+		errs = append(errs, field.Required(path, "a device group must specify one of emulators or hostUSB"))
+	}
+	if g.Emulators != nil {
+		errs = append(errs, g.Emulators.validate(path.Child("emulators"))...)
+	}
+	if g.Autoscaling != nil {
+		if g.Emulators == nil {
+			errs = append(errs, field.Forbidden(path.Child("autoscaling"), "autoscaling is only supported for emulated device groups"))
+		}
+		errs = append(errs, g.Autoscaling.validate(path.Child("autoscaling"))...)
+		if g.Emulators != nil && g.Emulators.MaxCount != 0 && g.Autoscaling.MaxCount > g.Emulators.MaxCount {
+			errs = append(errs, field.Invalid(path.Child("autoscaling", "maxCount"), g.Autoscaling.MaxCount, "must be less than or equal to the maxCount of the emulators"))
+		}
+	}
+	if g.HostUSB != nil {
+		errs = append(errs, g.HostUSB.validate(path.Child("hostUSB"))...)
+	}
+	if g.Provider != nil && g.Provider.StartPort != 0 {
+		if g.Provider.StartPort < 1024 || g.GetProviderMaxPort() > 65535 {
+			errs = append(errs, field.Invalid(path.Child("provider", "startPort"), g.Provider.StartPort, "provider ports must fall between 1024 and 65535"))
+		}
+	}
+	return errs
+}
+
+func (h *HostUSBConfig) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if h.MaxDevices < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxDevices"), h.MaxDevices, "must be greater than or equal to 0"))
+	}
+	if h.MaxNodes < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxNodes"), h.MaxNodes, "must be greater than or equal to 0"))
+	}
+	if h.NodeName != "" && len(h.NodeSelector) > 0 {
+		errs = append(errs, field.Forbidden(path.Child("nodeSelector"), "cannot specify both nodeName and nodeSelector"))
+	}
+	if h.MaxNodes != 0 && len(h.NodeSelector) == 0 {
+		errs = append(errs, field.Forbidden(path.Child("maxNodes"), "maxNodes is only used with a nodeSelector"))
+	}
+	return errs
+}
+
+func (e *EmulatorConfig) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if e.Count < 0 {
+		errs = append(errs, field.Invalid(path.Child("count"), e.Count, "must be greater than or equal to 0"))
+	}
+	if e.MaxCount != 0 && e.MaxCount < e.Count {
+		errs = append(errs, field.Invalid(path.Child("maxCount"), e.MaxCount, "must be greater than or equal to count"))
+	}
+	if e.ConfigRef == nil && (e.DeviceConfig == nil || e.DeviceConfig.DockerImage == "") {
+		errs = append(errs, field.Required(path.Child("configRef"), "a configRef is required unless a deviceConfig with a dockerImage is provided"))
+	} else if e.ConfigRef != nil && e.ConfigRef.Name == "" {
+		errs = append(errs, field.Required(path.Child("configRef", "name"), "the configRef must reference an AndroidDeviceConfig by name"))
+	}
+	if e.HostnameTemplate != "" {
+		if _, err := template.New("hostname").Parse(e.HostnameTemplate); err != nil {
+			errs = append(errs, field.Invalid(path.Child("hostnameTemplate"), e.HostnameTemplate, err.Error()))
+		}
+	}
+	if e.DeviceManagementPolicy != nil {
+		errs = append(errs, e.DeviceManagementPolicy.validate(path.Child("deviceManagementPolicy"))...)
+	}
+	if e.DeviceConfig != nil {
+		errs = append(errs, e.DeviceConfig.validate(path.Child("deviceConfig"))...)
+	}
+	return errs
+}
+
+func (a *AutoscalingPolicy) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if a.MinCount < 0 {
+		errs = append(errs, field.Invalid(path.Child("minCount"), a.MinCount, "must be greater than or equal to 0"))
+	}
+	if a.MaxCount < 1 {
+		errs = append(errs, field.Invalid(path.Child("maxCount"), a.MaxCount, "must be greater than 0"))
+	} else if a.MaxCount < a.MinCount {
+		errs = append(errs, field.Invalid(path.Child("maxCount"), a.MaxCount, "must be greater than or equal to minCount"))
+	}
+	if a.TargetUtilization < 0 || a.TargetUtilization > 100 {
+		errs = append(errs, field.Invalid(path.Child("targetUtilization"), a.TargetUtilization, "must be a percentage between 1 and 100"))
+	}
+	if a.ScaleUpCooldownSeconds != nil && *a.ScaleUpCooldownSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("scaleUpCooldownSeconds"), *a.ScaleUpCooldownSeconds, "must be greater than or equal to 0"))
+	}
+	if a.ScaleDownCooldownSeconds != nil && *a.ScaleDownCooldownSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("scaleDownCooldownSeconds"), *a.ScaleDownCooldownSeconds, "must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+func (d *DeviceManagementPolicy) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	switch d.GetPodManagementPolicy() {
+	case GroupedOrderedReady, Parallel:
+		if d.RollingUpdate != nil {
+			errs = append(errs, field.Forbidden(path.Child("rollingUpdate"), "may only be set with the RollingUpdate policy"))
+		}
+	case RollingUpdate:
+		if d.RollingUpdate != nil {
+			errs = append(errs, d.RollingUpdate.validate(path.Child("rollingUpdate"))...)
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("podManagementPolicy"), d.PodManagementPolicy, []string{string(GroupedOrderedReady), string(Parallel), string(RollingUpdate)}))
+	}
+	if d.Concurrency < 0 {
+		errs = append(errs, field.Invalid(path.Child("concurrency"), d.Concurrency, "must be greater than or equal to 0"))
+	}
+	if d.Partition < 0 {
+		errs = append(errs, field.Invalid(path.Child("partition"), d.Partition, "must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+func (r *RollingUpdateConfig) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	zero := true
+	for _, opt := range []struct {
+		name string
+		val  *intstr.IntOrString
+	}{{"maxUnavailable", r.MaxUnavailable}, {"maxSurge", r.MaxSurge}} {
+		if opt.val == nil {
+			continue
+		}
+		// resolve percentages against 100 devices to check their format
+		count, err := intstr.GetValueFromIntOrPercent(opt.val, 100, true)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Child(opt.name), opt.val.String(), "must be an integer or a percentage"))
+			continue
+		}
+		if count < 0 {
+			errs = append(errs, field.Invalid(path.Child(opt.name), opt.val.String(), "must be greater than or equal to 0"))
+		}
+		if count > 0 {
+			zero = false
+		}
+	}
+	if zero && r.MaxUnavailable != nil && r.MaxSurge != nil {
+		errs = append(errs, field.Invalid(path.Child("maxUnavailable"), r.MaxUnavailable.String(), "may not be 0 when maxSurge is 0"))
+	}
+	return errs
+}
+
+func (s *STFConfig) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if s.AppHostname == "" {
+		errs = append(errs, field.Required(path.Child("appHostname"), "the external hostname for OpenSTF is required"))
+	}
+	if s.Secret == "" {
+		errs = append(errs, field.Required(path.Child("secret"), "a secret containing the OpenSTF secret key is required"))
+	}
+	if s.Auth != nil {
+		authPath := path.Child("auth")
+		if s.Auth.Mock && s.Auth.OAuth != nil {
+			errs = append(errs, field.Forbidden(authPath.Child("oauth"), "cannot use both mock and oauth authentication"))
+		}
+		if oauth := s.Auth.OAuth; oauth != nil {
+			// the client id and secret keys are defaulted
+			oauthPath := authPath.Child("oauth")
+			for _, url := range []struct{ name, value string }{
+				{"authorizationURL", oauth.AuthorizationURL},
+				{"tokenURL", oauth.TokenURL},
+				{"userInfoURL", oauth.UserInfoURL},
+				{"callbackURL", oauth.CallbackURL},
+			} {
+				if url.value == "" {
+					errs = append(errs, field.Required(oauthPath.Child(url.name), "required when using oauth authentication"))
+				}
+			}
+		}
+	}
+	if s.Traefik != nil && s.Traefik.TLS != nil {
+		tls := s.Traefik.TLS
+		tlsPath := path.Child("traefik", "tls")
+		if tls.TLSSecret != nil && tls.IssuerRef != nil {
+			errs = append(errs, field.Forbidden(tlsPath.Child("issuerRef"), "cannot specify both a tlsSecret and an issuerRef"))
+		}
+		if tls.External && (tls.TLSSecret != nil || tls.IssuerRef != nil) {
+			errs = append(errs, field.Forbidden(tlsPath.Child("external"), "cannot use external TLS with a tlsSecret or issuerRef"))
+		}
+	}
+	return errs
+}
@@ -1,6 +1,9 @@
 package v1alpha1
 
 import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -11,30 +14,230 @@ type JobStatus string
 
 const (
 	StatusPending  JobStatus = "Pending"
+	StatusRunning  JobStatus = "Running"
 	StatusComplete JobStatus = "Complete"
 	StatusFailed   JobStatus = "Failed"
+	// StatusError is reported for steps that ended in an error and will be
+	// retried
+	StatusError JobStatus = "Error"
+)
+
+// JobFailureReason is the reason a job failed on a device
+type JobFailureReason string
+
+const (
+	// ReasonStepFailed is used when an action of the job failed
+	ReasonStepFailed JobFailureReason = "StepFailed"
+	// ReasonDeviceUnreachable is used when the device could not be reached
+	// over ADB
+	ReasonDeviceUnreachable JobFailureReason = "DeviceUnreachable"
+	// ReasonBackoffLimitExceeded is used when the job ended in an error more
+	// times than its backoff limit allows
+	ReasonBackoffLimitExceeded JobFailureReason = "BackoffLimitExceeded"
+	// ReasonDeadlineExceeded is used when the job did not finish on the device
+	// before its active deadline
+	ReasonDeadlineExceeded JobFailureReason = "DeadlineExceeded"
+	// ReasonTemplateError is used when the parameters of the job could not be
+	// resolved or an action could not be rendered for the device
+	ReasonTemplateError JobFailureReason = "TemplateError"
 )
 
+// DefaultJobBackoffLimit is the backoff limit used by jobs that do not set one
+const DefaultJobBackoffLimit = 6
+
 // AndroidJobSpec defines the desired state of AndroidJob
 type AndroidJobSpec struct {
 	DeviceName              string            `json:"deviceName,omitempty"`
 	DeviceSelector          map[string]string `json:"deviceSelector,omitempty"`
 	JobTemplate             string            `json:"jobTemplate"`
 	TTLSecondsAfterCreation *int              `json:"ttlSecondsAfterCreation,omitempty"`
+	// The number of times to retry the job on a device after it ends in an
+	// error before marking it failed. Defaults to 6.
+	BackoffLimit *int `json:"backoffLimit,omitempty"`
+	// The number of seconds after the creation of the job that devices which
+	// have not finished it are marked failed.
+	ActiveDeadlineSeconds *int `json:"activeDeadlineSeconds,omitempty"`
+	// Values for the parameters of the job template
+	Parameters []JobParameterValue `json:"parameters,omitempty"`
+}
+
+// JobParameterValue is the value of a job template parameter. Exactly one of
+// Value or ValueFrom must be set.
+type JobParameterValue struct {
+	// The name of the parameter
+	Name string `json:"name"`
+	// The value of the parameter
+	Value string `json:"value,omitempty"`
+	// A source in the namespace of the job to read the value from
+	ValueFrom *ParameterValueSource `json:"valueFrom,omitempty"`
+}
+
+// ParameterValueSource is a source for the value of a parameter. Exactly one
+// of its fields must be set.
+type ParameterValueSource struct {
+	// Selects a key of a secret. The secret must be labeled
+	// android.stf.io/job-parameters=true.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// Selects a key of a ConfigMap
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
 }
 
 // AndroidJobStatus defines the observed state of AndroidJob
 type AndroidJobStatus struct {
+	// Summary counts the devices targeted by the job by their status
+	Summary JobSummary `json:"summary,omitempty"`
 	// JobStatus is a map of device name to device status
 	JobStatus map[string]DeviceJobStatus `json:"jobStatus,omitempty"`
 }
 
+// JobSummary counts the devices targeted by a job by their status
+type JobSummary struct {
+	// Total is the number of devices targeted by the job
+	Total int `json:"total"`
+	// Complete is the number of devices the job completed on
+	Complete int `json:"complete"`
+	// Failed is the number of devices the job failed on
+	Failed int `json:"failed"`
+	// Running is the number of devices running the job
+	Running int `json:"running"`
+	// Pending is the number of devices waiting to run or retry the job
+	Pending int `json:"pending"`
+}
+
 // DeviceJobStatus defines the state of the job for a single device
 type DeviceJobStatus struct {
 	// Status is the current status of the job
 	Status JobStatus `json:"jobStatus,omitempty"`
 	// Message may contain extra information about the status of the job
 	Message string `json:"message,omitempty"`
+	// Reason is the reason the job failed, set when the status is Failed
+	Reason JobFailureReason `json:"reason,omitempty"`
+	// Attempts is the number of times the job was run on the device
+	Attempts int `json:"attempts,omitempty"`
+	// LastAttemptTime is the time the last attempt started
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+	// Steps contains the result of each action in the job template that was
+	// run in the last attempt
+	Steps []JobStepStatus `json:"steps,omitempty"`
+	// Artifacts contains the artifacts collected from the device in the last
+	// attempt
+	Artifacts []JobArtifact `json:"artifacts,omitempty"`
+}
+
+// JobArtifact is an artifact collected from a device running a job
+type JobArtifact struct {
+	// Name is the name of the artifact
+	Name string `json:"name"`
+	// URL is where the artifact was stored
+	URL string `json:"url"`
+}
+
+// JobStepStatus defines the result of a single action of a job on a device
+type JobStepStatus struct {
+	// Name is the name of the action, or its activity and index if it has none
+	Name string `json:"name"`
+	// Activity is the activity of the action
+	Activity Activity `json:"activity"`
+	// Status is the result of the action
+	Status JobStatus `json:"status"`
+	// Message may contain extra information about the result of the action
+	Message string `json:"message,omitempty"`
+	// Error is the error the action failed or ended with
+	Error string `json:"error,omitempty"`
+	// StartTime is the time the action started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the action finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// ExitCode is the exit code of the last command run by the action
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Stdout is the standard output of the commands run by the action,
+	// truncated to the last 1KiB
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr is the standard error of the commands run by the action,
+	// truncated to the last 1KiB
+	Stderr string `json:"stderr,omitempty"`
+	// FullOutput is where the full output was stored when it was truncated
+	FullOutput *StepOutputRef `json:"fullOutput,omitempty"`
+	// OutputError is why the full output could not be stored, if it was not
+	OutputError string `json:"outputError,omitempty"`
+}
+
+// StepOutputRef is where the full output of an action was stored
+type StepOutputRef struct {
+	// ConfigMap is the name of a ConfigMap in the namespace of the job holding
+	// the output in the keys <step>.stdout and <step>.stderr
+	ConfigMap string `json:"configMap,omitempty"`
+	// StdoutURL is the artifact the standard output was stored in
+	StdoutURL string `json:"stdoutURL,omitempty"`
+	// StderrURL is the artifact the standard error was stored in
+	StderrURL string `json:"stderrURL,omitempty"`
+	// Truncated is true if the output stored in the ConfigMap is not complete
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// OwnerReferences returns the owner references to place on objects created
+// for this job.
+func (a *AndroidJob) OwnerReferences() []metav1.OwnerReference {
+	trueVal := true
+	return []metav1.OwnerReference{
+		{
+			APIVersion:         SchemeGroupVersion.String(),
+			Kind:               "AndroidJob",
+			Name:               a.GetName(),
+			UID:                a.GetUID(),
+			Controller:         &trueVal,
+			BlockOwnerDeletion: &trueVal,
+		},
+	}
+}
+
+// Summarize returns the summary of the status of the job on the given devices,
+// and any other devices it has a status for.
+func (a *AndroidJobStatus) Summarize(deviceNames []string) JobSummary {
+	devices := make(map[string]struct{})
+	for _, name := range deviceNames {
+		devices[name] = struct{}{}
+	}
+	for name := range a.JobStatus {
+		devices[name] = struct{}{}
+	}
+	summary := JobSummary{Total: len(devices)}
+	for name := range devices {
+		switch a.JobStatus[name].Status {
+		case StatusComplete:
+			summary.Complete++
+		case StatusFailed:
+			summary.Failed++
+		case StatusRunning:
+			summary.Running++
+		default:
+			summary.Pending++
+		}
+	}
+	return summary
+}
+
+// Finished returns true if the job completed or failed on the device.
+func (d DeviceJobStatus) Finished() bool {
+	return d.Status == StatusComplete || d.Status == StatusFailed
+}
+
+// GetBackoffLimit returns the number of times to retry the job on a device
+// after an error.
+func (a *AndroidJob) GetBackoffLimit() int {
+	if a.Spec.BackoffLimit != nil {
+		return *a.Spec.BackoffLimit
+	}
+	return DefaultJobBackoffLimit
+}
+
+// Deadline returns the time after which devices that have not finished the
+// job are marked failed, or the zero time if the job has no deadline.
+func (a *AndroidJob) Deadline() time.Time {
+	if a.Spec.ActiveDeadlineSeconds == nil {
+		return time.Time{}
+	}
+	return a.GetCreationTimestamp().Add(time.Duration(*a.Spec.ActiveDeadlineSeconds) * time.Second)
 }
 
 func (a *AndroidJob) DeviceNamespacedName() types.NamespacedName {
@@ -49,6 +252,11 @@ func (a *AndroidJob) TemplateNamespacedName() types.NamespacedName {
 
 // AndroidJob is the Schema for the androidjobs API
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Template",type="string",JSONPath=".spec.jobTemplate"
+// +kubebuilder:printcolumn:name="Complete",type="integer",JSONPath=".status.summary.complete"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.summary.failed"
+// +kubebuilder:printcolumn:name="Total",type="integer",JSONPath=".status.summary.total"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:path=androidjobs,scope=Namespaced
 type AndroidJob struct {
 	metav1.TypeMeta   `json:",inline"`
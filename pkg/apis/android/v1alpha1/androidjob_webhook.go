@@ -0,0 +1,82 @@
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androidjob,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androidjobs,verbs=create;update,versions=v1alpha1,name=vandroidjob.android.stf.io
+
+var _ webhook.Validator = &AndroidJob{}
+
+// ValidateCreate validates a new AndroidJob.
+func (a *AndroidJob) ValidateCreate() error {
+	return a.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidJob.
+func (a *AndroidJob) ValidateUpdate(old runtime.Object) error {
+	return a.validate()
+}
+
+// ValidateDelete validates the deletion of an AndroidJob. Deletions are always
+// allowed.
+func (a *AndroidJob) ValidateDelete() error { return nil }
+
+func (a *AndroidJob) validate() error {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	switch {
+	case a.Spec.DeviceName == "" && len(a.Spec.DeviceSelector) == 0:
+		errs = append(errs, field.Required(specPath.Child("deviceName"), "one of deviceName or deviceSelector is required"))
+	case a.Spec.DeviceName != "" && len(a.Spec.DeviceSelector) != 0:
+		errs = append(errs, field.Forbidden(specPath.Child("deviceSelector"), "cannot specify both deviceName and deviceSelector"))
+	}
+	if a.Spec.JobTemplate == "" {
+		errs = append(errs, field.Required(specPath.Child("jobTemplate"), "the name of an AndroidJobTemplate is required"))
+	}
+	if ttl := a.Spec.TTLSecondsAfterCreation; ttl != nil && *ttl < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("ttlSecondsAfterCreation"), *ttl, "must be greater than or equal to 0"))
+	}
+	if limit := a.Spec.BackoffLimit; limit != nil && *limit < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("backoffLimit"), *limit, "must be greater than or equal to 0"))
+	}
+	if deadline := a.Spec.ActiveDeadlineSeconds; deadline != nil && *deadline <= 0 {
+		errs = append(errs, field.Invalid(specPath.Child("activeDeadlineSeconds"), *deadline, "must be greater than 0"))
+	}
+	paramsPath := specPath.Child("parameters")
+	names := make(map[string]struct{})
+	for idx, param := range a.Spec.Parameters {
+		if _, ok := names[param.Name]; ok {
+			errs = append(errs, field.Duplicate(paramsPath.Index(idx).Child("name"), param.Name))
+		}
+		names[param.Name] = struct{}{}
+		errs = append(errs, param.validate(paramsPath.Index(idx))...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, errs)
+}
+
+func (j *JobParameterValue) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if j.Name == "" {
+		errs = append(errs, field.Required(path.Child("name"), "parameter values require the name of a parameter"))
+	}
+	if j.ValueFrom == nil {
+		return errs
+	}
+	if j.Value != "" {
+		errs = append(errs, field.Forbidden(path.Child("valueFrom"), "cannot specify both value and valueFrom"))
+	}
+	switch from := j.ValueFrom; {
+	case from.SecretKeyRef == nil && from.ConfigMapKeyRef == nil:
+		errs = append(errs, field.Required(path.Child("valueFrom"), "one of secretKeyRef or configMapKeyRef is required"))
+	case from.SecretKeyRef != nil && from.ConfigMapKeyRef != nil:
+		errs = append(errs, field.Forbidden(path.Child("valueFrom", "configMapKeyRef"), "cannot specify both secretKeyRef and configMapKeyRef"))
+	}
+	return errs
+}
@@ -1,6 +1,11 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"strconv"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,25 +26,196 @@ const (
 	TypeAction  ActionType = "TypeText"
 )
 
+// ScreenshotPolicy is when to take screenshots of a device running a job.
+type ScreenshotPolicy string
+
+const (
+	// ScreenshotsNever never takes screenshots
+	ScreenshotsNever ScreenshotPolicy = "Never"
+	// ScreenshotsOnFailure takes a screenshot after an action fails
+	ScreenshotsOnFailure ScreenshotPolicy = "OnFailure"
+	// ScreenshotsEachAction takes a screenshot after every action
+	ScreenshotsEachAction ScreenshotPolicy = "EachAction"
+)
+
+// ParameterType is the type of a job template parameter.
+type ParameterType string
+
+const (
+	// ParameterString is a parameter that is passed to templates as a string
+	ParameterString ParameterType = "String"
+	// ParameterInt is a parameter that is passed to templates as an integer
+	ParameterInt ParameterType = "Int"
+	// ParameterBool is a parameter that is passed to templates as a boolean
+	ParameterBool ParameterType = "Bool"
+)
+
 // AndroidJobTemplateSpec defines the desired state of AndroidJobTemplate
 type AndroidJobTemplateSpec struct {
-	Actions []Action `json:"actions"`
+	// Parameters that jobs using the template can supply values for. They are
+	// available to templated fields of actions as .Params.<name>.
+	Parameters []JobParameter `json:"parameters,omitempty"`
+	Actions    []Action       `json:"actions"`
+	// Artifacts to collect from each device the job runs on
+	Artifacts *ArtifactsConfig `json:"artifacts,omitempty"`
+}
+
+// JobParameter is a parameter of a job template.
+type JobParameter struct {
+	// The name of the parameter. It must be a valid template identifier.
+	Name string `json:"name"`
+	// A description of the parameter
+	Description string `json:"description,omitempty"`
+	// The type of the parameter. Defaults to String.
+	Type ParameterType `json:"type,omitempty"`
+	// The value to use when a job does not supply one
+	Default *string `json:"default,omitempty"`
+	// Whether jobs must supply a value when there is no default
+	Required bool `json:"required,omitempty"`
+}
+
+// Parse converts a value for the parameter to its type.
+func (j *JobParameter) Parse(value string) (interface{}, error) {
+	switch j.Type {
+	case "", ParameterString:
+		return value, nil
+	case ParameterInt:
+		return strconv.ParseInt(value, 10, 64)
+	case ParameterBool:
+		return strconv.ParseBool(value)
+	default:
+		return nil, fmt.Errorf("Unknown parameter type: %s", j.Type)
+	}
+}
+
+// ZeroValue returns the value of an optional parameter that was not supplied
+// and has no default.
+func (j *JobParameter) ZeroValue() interface{} {
+	switch j.Type {
+	case ParameterInt:
+		return int64(0)
+	case ParameterBool:
+		return false
+	default:
+		return ""
+	}
 }
 
+// NewActionTemplate returns a template for rendering a field of an action.
+// Functions that read the environment of the operator are not available.
+func NewActionTemplate(name string) *template.Template {
+	return template.New(name).Funcs(sprig.HermeticTxtFuncMap()).Option("missingkey=error")
+}
+
+// ArtifactsConfig configures the artifacts collected from devices running a
+// job and where they are stored.
+type ArtifactsConfig struct {
+	// Whether to capture the logcat of the device for the time the job ran
+	Logcat bool `json:"logcat,omitempty"`
+	// When to take screenshots of the device. Defaults to Never.
+	Screenshots ScreenshotPolicy `json:"screenshots,omitempty"`
+	// Paths on the device to pull when the job finishes
+	Paths []string `json:"paths,omitempty"`
+	// Where to store the artifacts
+	Sink ArtifactSink `json:"sink"`
+}
+
+// ArtifactSink is where job artifacts are stored. Exactly one sink must be
+// configured.
+type ArtifactSink struct {
+	// Store artifacts on the artifacts volume of the operator
+	Volume *VolumeSink `json:"volume,omitempty"`
+	// Store artifacts in an S3 compatible bucket
+	S3 *S3Sink `json:"s3,omitempty"`
+	// Store artifacts with the OpenSTF storage service of the farm of the device
+	STF *STFSink `json:"stf,omitempty"`
+}
+
+// VolumeSink stores artifacts on the volume mounted by the operator at the
+// directory given by its --artifacts-dir flag.
+type VolumeSink struct {
+	// A sub-directory of the volume to store artifacts in
+	Path string `json:"path,omitempty"`
+}
+
+// S3Sink stores artifacts in an S3 compatible bucket, such as MinIO.
+type S3Sink struct {
+	// The host and optional port of the S3 API
+	Endpoint string `json:"endpoint"`
+	// The bucket to store artifacts in
+	Bucket string `json:"bucket"`
+	// A prefix for the keys of the artifacts
+	Prefix string `json:"prefix,omitempty"`
+	// The region of the bucket. Defaults to us-east-1.
+	Region string `json:"region,omitempty"`
+	// Whether to use plain HTTP to reach the endpoint
+	Insecure bool `json:"insecure,omitempty"`
+	// The name of a secret in the namespace of the job containing the
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to use
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// STFSink stores artifacts with the OpenSTF storage service, where they can
+// be downloaded from the STF app.
+type STFSink struct {
+	// The farm to store artifacts with. Defaults to the farm of each device.
+	Farm string `json:"farm,omitempty"`
+}
+
+// Action is a single step of a job template. Commands, the APK URL, the
+// command of a wait condition, and the targets and inputs of interactions are
+// go templates, rendered for each device with the sprig functions.
 type Action struct {
-	Activity     Activity      `json:"activity"`
-	Name         string        `json:"name,omitempty"`
-	RunAsRoot    bool          `json:"runAsRoot,omitempty"`
-	Commands     []string      `json:"commands,omitempty"`
-	APKUrl       string        `json:"apkURL,omitempty"`
-	Seconds      int           `json:"seconds,omitempty"`
-	Interactions []Interaction `json:"interactions,omitempty"`
+	Activity  Activity `json:"activity"`
+	Name      string   `json:"name,omitempty"`
+	RunAsRoot bool     `json:"runAsRoot,omitempty"`
+	Commands  []string `json:"commands,omitempty"`
+	APKUrl    string   `json:"apkURL,omitempty"`
+	// The hex encoded SHA-256 checksum the APK downloaded for an Install
+	// activity must match.
+	APKChecksum string `json:"apkChecksum,omitempty"`
+	// The number of times to attempt downloading the APK for an Install
+	// activity. Defaults to 3.
+	Retries int `json:"retries,omitempty"`
+	// For a Wait activity, the number of seconds to sleep, or the number of
+	// seconds to wait for the Condition before failing. Defaults to 300 when
+	// waiting for a condition.
+	Seconds int `json:"seconds,omitempty"`
+	// A condition to wait for in a Wait activity.
+	Condition *WaitCondition `json:"condition,omitempty"`
+	// The number of seconds each command run by the action may take, or the
+	// APK of an Install activity may take to install. Defaults to 10 seconds
+	// for commands and 300 seconds for installs.
+	TimeoutSeconds int           `json:"timeoutSeconds,omitempty"`
+	Interactions   []Interaction `json:"interactions,omitempty"`
+}
+
+// WaitCondition is a condition on the device to wait for in a Wait activity.
+// When both a command and text are given, both must be satisfied.
+type WaitCondition struct {
+	// A shell command to run until it succeeds
+	Command string `json:"command,omitempty"`
+	// A string the output of the command must contain for it to succeed
+	Output string `json:"output,omitempty"`
+	// A string to wait for on the screen of the device
+	Text string `json:"text,omitempty"`
+	// Whether to invert the screen when searching for the text
+	Invert bool `json:"invert,omitempty"`
+	// The number of seconds between checks of the condition. Defaults to 5.
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
 }
 
 type Interaction struct {
-	Type   ActionType `json:"type,omitempty"`
-	Target string     `json:"target,omitempty"`
-	Input  string     `json:"input,omitempty"`
+	Type ActionType `json:"type,omitempty"`
+	// The string on the screen to tap. For a TypeText interaction it is tapped
+	// before typing, if given.
+	Target string `json:"target,omitempty"`
+	// The text to type for a TypeText interaction
+	Input string `json:"input,omitempty"`
+	// Whether to scroll the screen until the target is found
+	Scroll bool `json:"scroll,omitempty"`
+	// Whether to invert the screen when searching for the target
+	Invert bool `json:"invert,omitempty"`
 }
 
 // AndroidJobTemplateStatus defines the observed state of AndroidJobTemplate
@@ -0,0 +1,233 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-android-stf-io-v1alpha1-androidjobtemplate,mutating=true,failurePolicy=fail,groups=android.stf.io,resources=androidjobtemplates,verbs=create;update,versions=v1alpha1,name=mandroidjobtemplate.android.stf.io
+// +kubebuilder:webhook:path=/validate-android-stf-io-v1alpha1-androidjobtemplate,mutating=false,failurePolicy=fail,groups=android.stf.io,resources=androidjobtemplates,verbs=create;update,versions=v1alpha1,name=vandroidjobtemplate.android.stf.io
+
+var sha256Regex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+var parameterNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var _ webhook.Defaulter = &AndroidJobTemplate{}
+var _ webhook.Validator = &AndroidJobTemplate{}
+
+// Default applies default values to an AndroidJobTemplate. Actions without a
+// name are named after their activity and position in the template, and
+// parameters without a type are strings.
+func (a *AndroidJobTemplate) Default() {
+	for idx := range a.Spec.Parameters {
+		if a.Spec.Parameters[idx].Type == "" {
+			a.Spec.Parameters[idx].Type = ParameterString
+		}
+	}
+	for idx := range a.Spec.Actions {
+		action := &a.Spec.Actions[idx]
+		if action.Name == "" {
+			action.Name = fmt.Sprintf("%s-%d", strings.ToLower(string(action.Activity)), idx)
+		}
+	}
+}
+
+// ValidateCreate validates a new AndroidJobTemplate.
+func (a *AndroidJobTemplate) ValidateCreate() error {
+	return a.validate()
+}
+
+// ValidateUpdate validates an update to an AndroidJobTemplate.
+func (a *AndroidJobTemplate) ValidateUpdate(old runtime.Object) error {
+	return a.validate()
+}
+
+// ValidateDelete validates the deletion of an AndroidJobTemplate. Deletions are
+// always allowed.
+func (a *AndroidJobTemplate) ValidateDelete() error { return nil }
+
+func (a *AndroidJobTemplate) validate() error {
+	errs := field.ErrorList{}
+	paramsPath := field.NewPath("spec", "parameters")
+	names := make(map[string]struct{})
+	for idx, param := range a.Spec.Parameters {
+		if _, ok := names[param.Name]; ok {
+			errs = append(errs, field.Duplicate(paramsPath.Index(idx).Child("name"), param.Name))
+		}
+		names[param.Name] = struct{}{}
+		errs = append(errs, param.validate(paramsPath.Index(idx))...)
+	}
+	actionsPath := field.NewPath("spec", "actions")
+	if len(a.Spec.Actions) == 0 {
+		errs = append(errs, field.Required(actionsPath, "at least one action is required"))
+	}
+	for idx, action := range a.Spec.Actions {
+		errs = append(errs, action.validate(actionsPath.Index(idx))...)
+	}
+	if a.Spec.Artifacts != nil {
+		errs = append(errs, a.Spec.Artifacts.validate(field.NewPath("spec", "artifacts"))...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(a.GroupVersionKind().GroupKind(), a.Name, errs)
+}
+
+func (a *Action) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if a.TimeoutSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("timeoutSeconds"), a.TimeoutSeconds, "must not be negative"))
+	}
+	switch a.Activity {
+	case CommandActivity:
+		if len(a.Commands) == 0 {
+			errs = append(errs, field.Required(path.Child("commands"), "command activities require at least one command"))
+		}
+		for idx, cmd := range a.Commands {
+			errs = append(errs, validateTemplate(path.Child("commands").Index(idx), cmd)...)
+		}
+	case InstallActivity:
+		if a.APKUrl == "" {
+			errs = append(errs, field.Required(path.Child("apkURL"), "install activities require the URL of an APK"))
+		}
+		errs = append(errs, validateTemplate(path.Child("apkURL"), a.APKUrl)...)
+		if a.APKChecksum != "" && !sha256Regex.MatchString(a.APKChecksum) {
+			errs = append(errs, field.Invalid(path.Child("apkChecksum"), a.APKChecksum, "must be a hex encoded SHA-256 checksum"))
+		}
+		if a.Retries < 0 {
+			errs = append(errs, field.Invalid(path.Child("retries"), a.Retries, "must not be negative"))
+		}
+	case WaitActivity:
+		if a.Condition != nil {
+			if a.Seconds < 0 {
+				errs = append(errs, field.Invalid(path.Child("seconds"), a.Seconds, "must not be negative"))
+			}
+			errs = append(errs, a.Condition.validate(path.Child("condition"))...)
+		} else if a.Seconds <= 0 {
+			errs = append(errs, field.Invalid(path.Child("seconds"), a.Seconds, "wait activities require a number of seconds greater than 0 or a condition"))
+		}
+	case InteractActivity:
+		if len(a.Interactions) == 0 {
+			errs = append(errs, field.Required(path.Child("interactions"), "interact activities require at least one interaction"))
+		}
+		for idx, interaction := range a.Interactions {
+			errs = append(errs, interaction.validate(path.Child("interactions").Index(idx))...)
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("activity"), a.Activity, []string{
+			string(CommandActivity), string(InstallActivity), string(WaitActivity), string(InteractActivity),
+		}))
+	}
+	return errs
+}
+
+func (a *ArtifactsConfig) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	switch a.Screenshots {
+	case "", ScreenshotsNever, ScreenshotsOnFailure, ScreenshotsEachAction:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("screenshots"), a.Screenshots, []string{
+			string(ScreenshotsNever), string(ScreenshotsOnFailure), string(ScreenshotsEachAction),
+		}))
+	}
+	for idx, p := range a.Paths {
+		if !strings.HasPrefix(p, "/") {
+			errs = append(errs, field.Invalid(path.Child("paths").Index(idx), p, "must be an absolute path"))
+		}
+	}
+	sinkPath := path.Child("sink")
+	var sinks int
+	if a.Sink.Volume != nil {
+		sinks++
+	}
+	if a.Sink.STF != nil {
+		sinks++
+	}
+	if s3 := a.Sink.S3; s3 != nil {
+		sinks++
+		if s3.Endpoint == "" {
+			errs = append(errs, field.Required(sinkPath.Child("s3", "endpoint"), "s3 sinks require an endpoint"))
+		}
+		if s3.Bucket == "" {
+			errs = append(errs, field.Required(sinkPath.Child("s3", "bucket"), "s3 sinks require a bucket"))
+		}
+		if s3.CredentialsSecret == "" {
+			errs = append(errs, field.Required(sinkPath.Child("s3", "credentialsSecret"), "s3 sinks require a credentials secret"))
+		}
+	}
+	if sinks != 1 {
+		errs = append(errs, field.Invalid(sinkPath, sinks, "exactly one of volume, s3, or stf must be configured"))
+	}
+	return errs
+}
+
+func (w *WaitCondition) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if w.Command == "" && w.Text == "" {
+		errs = append(errs, field.Required(path, "wait conditions require a command or text"))
+	}
+	if w.Output != "" && w.Command == "" {
+		errs = append(errs, field.Required(path.Child("command"), "an output requires a command"))
+	}
+	errs = append(errs, validateTemplate(path.Child("command"), w.Command)...)
+	if w.IntervalSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("intervalSeconds"), w.IntervalSeconds, "must not be negative"))
+	}
+	return errs
+}
+
+func (i *Interaction) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	switch i.Type {
+	case ClickAction:
+		if i.Target == "" {
+			errs = append(errs, field.Required(path.Child("target"), "click interactions require a target"))
+		}
+	case TypeAction:
+		if i.Input == "" {
+			errs = append(errs, field.Required(path.Child("input"), "text interactions require an input"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("type"), i.Type, []string{string(ClickAction), string(TypeAction)}))
+	}
+	errs = append(errs, validateTemplate(path.Child("target"), i.Target)...)
+	errs = append(errs, validateTemplate(path.Child("input"), i.Input)...)
+	return errs
+}
+
+func (j *JobParameter) validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if !parameterNameRegex.MatchString(j.Name) {
+		errs = append(errs, field.Invalid(path.Child("name"), j.Name, "must start with a letter or underscore and contain only letters, digits and underscores"))
+	}
+	switch j.Type {
+	case "", ParameterString, ParameterInt, ParameterBool:
+		if j.Default != nil {
+			if _, err := j.Parse(*j.Default); err != nil {
+				errs = append(errs, field.Invalid(path.Child("default"), *j.Default, err.Error()))
+			}
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("type"), j.Type, []string{
+			string(ParameterString), string(ParameterInt), string(ParameterBool),
+		}))
+	}
+	return errs
+}
+
+// validateTemplate checks that a templated field of an action parses.
+func validateTemplate(path *field.Path, tmpl string) field.ErrorList {
+	if tmpl == "" {
+		return nil
+	}
+	if _, err := NewActionTemplate(path.String()).Parse(tmpl); err != nil {
+		return field.ErrorList{field.Invalid(path, tmpl, err.Error())}
+	}
+	return nil
+}
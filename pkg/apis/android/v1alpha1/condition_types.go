@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionType represents the type of a status condition on an android.stf.io
+// resource.
+type ConditionType string
+
+// Condition types reported on AndroidFarm resources. Conditions for individual
+// OpenSTF deployments are named after their component, see STFComponentConditionType.
+const (
+	// RethinkDBReady is true when all replicas in the RethinkDB StatefulSet are ready.
+	RethinkDBReady ConditionType = "RethinkDBReady"
+	// RethinkDBProxyReady is true when all RethinkDB proxy replicas are ready.
+	RethinkDBProxyReady ConditionType = "RethinkDBProxyReady"
+	// RethinkDBMigrated is true when the STF database migration job has completed.
+	RethinkDBMigrated ConditionType = "RethinkDBMigrated"
+	// TraefikReady is true when the main traefik deployment is available.
+	TraefikReady ConditionType = "TraefikReady"
+	// DevicesReady is true when every emulated device group has its desired number
+	// of devices booted.
+	DevicesReady ConditionType = "DevicesReady"
+	// FarmReady is true when all other conditions on the AndroidFarm are true.
+	FarmReady ConditionType = "Ready"
+)
+
+// Condition types reported on AndroidDevice resources.
+const (
+	// DeviceBootCompleted is true when the emulator reports that it has finished
+	// booting.
+	DeviceBootCompleted ConditionType = "BootCompleted"
+	// DeviceSTFBound is true when the emulator is connected to the ADB server of
+	// its STF provider.
+	DeviceSTFBound ConditionType = "STFBound"
+	// DeviceHealthy is true when the device passes the checks in the health
+	// policy of its configuration.
+	DeviceHealthy ConditionType = "Healthy"
+	// DeviceQuickBootReady is true when the emulator boots from a golden
+	// quick-boot snapshot, and false when it falls back to a cold boot because
+	// the snapshot could not be taken.
+	DeviceQuickBootReady ConditionType = "QuickBootReady"
+)
+
+// Condition represents an observation of a single aspect of a resource's state.
+type Condition struct {
+	// The type of the condition.
+	Type ConditionType `json:"type"`
+	// The status of the condition, one of True, False, or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// A human readable message with details about the transition.
+	Message string `json:"message,omitempty"`
+	// The last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
@@ -0,0 +1,14 @@
+package v1alpha1
+
+// v1alpha1 is the storage version of the android.stf.io API and the version
+// used internally by the controllers. Other versions are converted to and from
+// these types by the conversion webhook.
+
+// Hub marks this type as a conversion hub.
+func (*AndroidFarm) Hub() {}
+
+// Hub marks this type as a conversion hub.
+func (*AndroidDevice) Hub() {}
+
+// Hub marks this type as a conversion hub.
+func (*AndroidDeviceConfig) Hub() {}
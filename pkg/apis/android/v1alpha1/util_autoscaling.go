@@ -0,0 +1,45 @@
+package v1alpha1
+
+import "time"
+
+// GetTargetUtilization returns the percentage of devices that should be in use
+// in a group managed by this policy.
+func (a *AutoscalingPolicy) GetTargetUtilization() int32 {
+	if a.TargetUtilization == 0 {
+		return 80
+	}
+	return a.TargetUtilization
+}
+
+// GetScaleUpCooldown returns the time to wait after a group was last scaled
+// before scaling it up again.
+func (a *AutoscalingPolicy) GetScaleUpCooldown() time.Duration {
+	if a.ScaleUpCooldownSeconds == nil {
+		return time.Duration(60) * time.Second
+	}
+	return time.Duration(*a.ScaleUpCooldownSeconds) * time.Second
+}
+
+// GetScaleDownCooldown returns the time to wait after a group was last scaled
+// before scaling it down again.
+func (a *AutoscalingPolicy) GetScaleDownCooldown() time.Duration {
+	if a.ScaleDownCooldownSeconds == nil {
+		return time.Duration(300) * time.Second
+	}
+	return time.Duration(*a.ScaleDownCooldownSeconds) * time.Second
+}
+
+// DesiredCount returns the number of devices a group managed by this policy
+// should run for the given number of devices in use, bounded by the minimum and
+// maximum counts of the policy.
+func (a *AutoscalingPolicy) DesiredCount(inUse int32) int32 {
+	target := a.GetTargetUtilization()
+	desired := (inUse*100 + target - 1) / target
+	switch {
+	case desired < a.MinCount:
+		return a.MinCount
+	case desired > a.MaxCount:
+		return a.MaxCount
+	}
+	return desired
+}
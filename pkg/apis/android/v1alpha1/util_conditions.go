@@ -0,0 +1,103 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// STFComponentConditionType returns the condition type used to report the
+// availability of the OpenSTF deployment for the given component. For example,
+// the "apk-storage" component is reported as "ApkStorageAvailable".
+func STFComponentConditionType(component string) ConditionType {
+	var name strings.Builder
+	for _, part := range strings.Split(component, "-") {
+		if part == "" {
+			continue
+		}
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return ConditionType(name.String() + "Available")
+}
+
+// NewCondition returns a new condition with the given parameters. The transition
+// time is set to now, but is preserved by SetCondition if the status did not change.
+func NewCondition(ctype ConditionType, status corev1.ConditionStatus, reason, message string) Condition {
+	return Condition{
+		Type:               ctype,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+}
+
+// IsTrue returns true if the condition has a status of True.
+func (c *Condition) IsTrue() bool {
+	return c != nil && c.Status == corev1.ConditionTrue
+}
+
+// GetCondition returns the condition of the given type on the AndroidFarm status,
+// or nil if it is not present.
+func (s *AndroidFarmStatus) GetCondition(ctype ConditionType) *Condition {
+	return getCondition(s.Conditions, ctype)
+}
+
+// SetCondition adds or updates the given condition on the AndroidFarm status.
+func (s *AndroidFarmStatus) SetCondition(cond Condition) {
+	s.Conditions = setCondition(s.Conditions, cond)
+}
+
+// RemoveCondition removes the condition of the given type from the AndroidFarm
+// status.
+func (s *AndroidFarmStatus) RemoveCondition(ctype ConditionType) {
+	s.Conditions = removeCondition(s.Conditions, ctype)
+}
+
+// GetCondition returns the condition of the given type on the AndroidDevice
+// status, or nil if it is not present.
+func (s *AndroidDeviceStatus) GetCondition(ctype ConditionType) *Condition {
+	return getCondition(s.Conditions, ctype)
+}
+
+// SetCondition adds or updates the given condition on the AndroidDevice status.
+func (s *AndroidDeviceStatus) SetCondition(cond Condition) {
+	s.Conditions = setCondition(s.Conditions, cond)
+}
+
+// RemoveCondition removes the condition of the given type from the AndroidDevice
+// status.
+func (s *AndroidDeviceStatus) RemoveCondition(ctype ConditionType) {
+	s.Conditions = removeCondition(s.Conditions, ctype)
+}
+
+func getCondition(conds []Condition, ctype ConditionType) *Condition {
+	for i := range conds {
+		if conds[i].Type == ctype {
+			return &conds[i]
+		}
+	}
+	return nil
+}
+
+func setCondition(conds []Condition, cond Condition) []Condition {
+	if existing := getCondition(conds, cond.Type); existing != nil {
+		if existing.Status == cond.Status {
+			cond.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = cond
+		return conds
+	}
+	return append(conds, cond)
+}
+
+func removeCondition(conds []Condition, ctype ConditionType) []Condition {
+	out := make([]Condition, 0)
+	for _, cond := range conds {
+		if cond.Type != ctype {
+			out = append(out, cond)
+		}
+	}
+	return out
+}
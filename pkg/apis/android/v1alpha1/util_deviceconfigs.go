@@ -4,15 +4,27 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Checksum returns the SHA256 hash of the current android device.
 // Since this instance will often get merged with another, this is helpful
 // for storing the state of the merged configuration.
+//
+// The health policy and volume retention policies do not change the emulator
+// pods, so they are left out of the checksum to avoid replacing devices when
+// they change.
 func (c *AndroidDeviceConfig) Checksum() (string, error) {
-	out, err := json.Marshal(c.Spec)
+	spec := c.Spec.DeepCopy()
+	spec.HealthPolicy = nil
+	for idx := range spec.Volumes {
+		spec.Volumes[idx].RetentionPolicy = ""
+	}
+	out, err := json.Marshal(spec)
 	if err != nil {
 		return "", err
 	}
@@ -153,3 +165,130 @@ func (c *AndroidDeviceConfigSpec) MergeInto(target AndroidDeviceConfigSpec) (*An
 	}
 	return &target, nil
 }
+
+// QuickBootEnabled returns true if emulators using this configuration are booted
+// from a golden snapshot.
+func (c *AndroidDeviceConfig) QuickBootEnabled() bool {
+	return c.Spec.QuickBoot != nil
+}
+
+// ClonesVolumeSnapshots returns true if emulators are given their own PVC cloned
+// from a VolumeSnapshot of the golden volume, instead of mounting it directly.
+func (q *QuickBootConfig) ClonesVolumeSnapshots() bool {
+	return q.VolumeSnapshotClassName != ""
+}
+
+// GetRetentionPolicy returns what to do with the PVCs for this volume when
+// devices are deleted or their configuration changes.
+func (v *Volume) GetRetentionPolicy() VolumeRetentionPolicy {
+	if v.RetentionPolicy == "" {
+		return VolumeRetain
+	}
+	return v.RetentionPolicy
+}
+
+// GetNodeSelector returns the node selector for emulator pods.
+func (c *AndroidDeviceConfig) GetNodeSelector() map[string]string {
+	if c.Spec.Scheduling == nil {
+		return nil
+	}
+	return c.Spec.Scheduling.NodeSelector
+}
+
+// GetTolerations returns the tolerations for emulator pods.
+func (c *AndroidDeviceConfig) GetTolerations() []corev1.Toleration {
+	if c.Spec.Scheduling == nil {
+		return nil
+	}
+	return c.Spec.Scheduling.Tolerations
+}
+
+// GetPriorityClassName returns the priority class for emulator pods.
+func (c *AndroidDeviceConfig) GetPriorityClassName() string {
+	if c.Spec.Scheduling == nil {
+		return ""
+	}
+	return c.Spec.Scheduling.PriorityClassName
+}
+
+// GetTopologySpreadConstraints returns the topology spread constraints for
+// emulator pods.
+func (c *AndroidDeviceConfig) GetTopologySpreadConstraints() []corev1.TopologySpreadConstraint {
+	if c.Spec.Scheduling == nil {
+		return nil
+	}
+	return c.Spec.Scheduling.TopologySpreadConstraints
+}
+
+// GetAffinity returns the affinity for emulator pods. If no pod anti-affinity
+// or topology spread constraints are configured, and the given selector for the
+// pods of the device group is not empty, the pods prefer to be spread across
+// nodes.
+func (c *AndroidDeviceConfig) GetAffinity(groupSelector map[string]string) *corev1.Affinity {
+	affinity := &corev1.Affinity{}
+	if c.Spec.Scheduling != nil && c.Spec.Scheduling.Affinity != nil {
+		affinity = c.Spec.Scheduling.Affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity != nil || len(c.GetTopologySpreadConstraints()) > 0 || len(groupSelector) == 0 {
+		if reflect.DeepEqual(*affinity, corev1.Affinity{}) {
+			return nil
+		}
+		return affinity
+	}
+	affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+			{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: groupSelector},
+					TopologyKey:   corev1.LabelHostname,
+				},
+			},
+		},
+	}
+	return affinity
+}
+
+// GetHealthPolicy returns the health policy for devices using this configuration,
+// or nil if their health is not checked.
+func (c *AndroidDeviceConfig) GetHealthPolicy() *HealthPolicy {
+	return c.Spec.HealthPolicy
+}
+
+// GetInterval returns the amount of time between health checks.
+func (h *HealthPolicy) GetInterval() time.Duration {
+	if h.IntervalSeconds == 0 {
+		return time.Duration(60) * time.Second
+	}
+	return time.Duration(h.IntervalSeconds) * time.Second
+}
+
+// GetChecks returns the checks to run against devices.
+func (h *HealthPolicy) GetChecks() []HealthCheck {
+	if len(h.Checks) == 0 {
+		return []HealthCheck{{Type: ADBReachable}, {Type: BootCompleted}}
+	}
+	return h.Checks
+}
+
+// GetFailureThreshold returns the number of consecutive failed checks before a
+// device is remediated.
+func (h *HealthPolicy) GetFailureThreshold() int32 {
+	if h.FailureThreshold == 0 {
+		return 3
+	}
+	return h.FailureThreshold
+}
+
+// NextRemediation returns the action to take after the given one when a device
+// is still unhealthy. Recreating the pod is the last resort and is repeated.
+func NextRemediation(last RemediationAction) RemediationAction {
+	switch last {
+	case "":
+		return RemediationReconnect
+	case RemediationReconnect:
+		return RemediationReboot
+	default:
+		return RemediationRecreate
+	}
+}
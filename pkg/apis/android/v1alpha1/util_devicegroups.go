@@ -0,0 +1,47 @@
+package v1alpha1
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NamespacedName returns the namespaced name object for this device group
+func (a *AndroidDeviceGroup) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: a.Name, Namespace: a.Namespace}
+}
+
+// FarmCount returns the count of the emulator group in the AndroidFarm when the
+// replicas of this device group were last synced from it, or -1 if they have
+// never been synced.
+func (a *AndroidDeviceGroup) FarmCount() int32 {
+	if a.Annotations == nil {
+		return -1
+	}
+	count, err := strconv.Atoi(a.Annotations[FarmCountAnnotation])
+	if err != nil {
+		return -1
+	}
+	return int32(count)
+}
+
+// SetFarmCount records the count of the emulator group in the AndroidFarm that
+// the replicas of this device group were synced from.
+func (a *AndroidDeviceGroup) SetFarmCount(count int32) {
+	if a.Annotations == nil {
+		a.Annotations = make(map[string]string)
+	}
+	a.Annotations[FarmCountAnnotation] = strconv.Itoa(int(count))
+}
+
+// DesiredReplicas returns the number of devices that should be running for the
+// given farm device group, bounded by the maximum count of the group.
+func (a *AndroidDeviceGroup) DesiredReplicas(group *DeviceGroup) int32 {
+	switch {
+	case a.Spec.Replicas < 0:
+		return 0
+	case a.Spec.Replicas > group.GetMaxCount():
+		return group.GetMaxCount()
+	}
+	return a.Spec.Replicas
+}
@@ -0,0 +1,36 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NamespacedName returns the namespaced name object for this lease
+func (a *AndroidDeviceLease) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: a.Name, Namespace: a.Namespace}
+}
+
+// Duration returns the amount of time a device is held by this lease.
+func (a *AndroidDeviceLease) Duration() time.Duration {
+	return time.Duration(a.Spec.DurationSeconds) * time.Second
+}
+
+// GetOwnerEmail returns the email address of the OpenSTF user to mark as the
+// owner of the leased device.
+func (a *AndroidDeviceLease) GetOwnerEmail() string {
+	if a.Spec.Owner != nil && a.Spec.Owner.Email != "" {
+		return a.Spec.Owner.Email
+	}
+	return fmt.Sprintf("%s.%s@leases.android.stf.io", a.Name, a.Namespace)
+}
+
+// GetOwnerName returns the display name of the OpenSTF user to mark as the owner
+// of the leased device.
+func (a *AndroidDeviceLease) GetOwnerName() string {
+	if a.Spec.Owner != nil && a.Spec.Owner.Name != "" {
+		return a.Spec.Owner.Name
+	}
+	return fmt.Sprintf("AndroidDeviceLease %s/%s", a.Namespace, a.Name)
+}
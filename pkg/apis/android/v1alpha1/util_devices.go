@@ -25,13 +25,48 @@ func (a *AndroidDevice) IsFarmedDevice() bool {
 	return false
 }
 
+// GetSource returns where this device comes from.
+func (a *AndroidDevice) GetSource() DeviceSource {
+	if a.Spec.Source == "" {
+		return EmulatorSource
+	}
+	return a.Spec.Source
+}
+
+// IsPhysicalDevice returns true if this device is attached to a node over USB
+// rather than emulated by the operator.
+func (a *AndroidDevice) IsPhysicalDevice() bool {
+	return a.GetSource() == PhysicalSource
+}
+
 // ConfigChecksum returns the checksum of the configuration currently present
 // on the device pods.
 func (a *AndroidDevice) ConfigChecksum() string {
-	if sum, ok := a.Annotations[DeviceConfigSHAAnnotation]; ok {
-		return sum
+	return a.Status.ConfigChecksum
+}
+
+// BootCompleted returns true if the device has reported that it finished booting.
+func (a *AndroidDevice) BootCompleted() bool {
+	return a.Status.GetCondition(DeviceBootCompleted).IsTrue()
+}
+
+// STFBound returns true if the device is connected to its STF provider.
+func (a *AndroidDevice) STFBound() bool {
+	return a.Status.GetCondition(DeviceSTFBound).IsTrue()
+}
+
+// LeaseName returns the name of the AndroidDeviceLease holding this device, or
+// an empty string if it is not leased.
+func (a *AndroidDevice) LeaseName() string {
+	if a.Annotations == nil {
+		return ""
 	}
-	return ""
+	return a.Annotations[LeaseAnnotation]
+}
+
+// IsLeased returns true if this device is held by an AndroidDeviceLease.
+func (a *AndroidDevice) IsLeased() bool {
+	return a.LeaseName() != ""
 }
 
 // GetConfig returns the desired configuration state of this device instance.
@@ -48,14 +83,22 @@ func (a *AndroidDevice) GetConfig(c client.Client) (*AndroidDeviceConfig, error)
 			return nil, err
 		}
 	}
+	return a.MergeConfig(found)
+}
+
+// MergeConfig returns a copy of the given configuration with the overrides of
+// this device instance merged on top of it. The configuration should be the one
+// referenced by the configref of the device, if any.
+func (a *AndroidDevice) MergeConfig(base *AndroidDeviceConfig) (*AndroidDeviceConfig, error) {
+	config := base.DeepCopy()
 	if a.Spec.DeviceConfig != nil {
-		merged, err := a.Spec.DeviceConfig.MergeInto(found.Spec)
+		merged, err := a.Spec.DeviceConfig.MergeInto(config.Spec)
 		if err != nil {
 			return nil, err
 		}
-		found.Spec = *merged
+		config.Spec = *merged
 	}
-	return found, nil
+	return config, nil
 }
 
 // GetFarm returns the parent AndroidFarm for the current device.
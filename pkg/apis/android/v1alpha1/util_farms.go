@@ -3,11 +3,13 @@ package v1alpha1
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"html/template"
 
 	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -25,6 +27,17 @@ func (a *AndroidFarm) DeviceGroups() []*DeviceGroup {
 	return a.Spec.DeviceGroups
 }
 
+// GetDeviceGroup returns the device group with the given name, or nil if it
+// does not exist in the farm.
+func (a *AndroidFarm) GetDeviceGroup(name string) *DeviceGroup {
+	for _, group := range a.DeviceGroups() {
+		if group.Name == name {
+			return group
+		}
+	}
+	return nil
+}
+
 // GetDeviceManagementPolicy returns the device management policy for a device
 // group. If one is provided on the group level, it is returned immediately,
 // otherwise any global policy on the AndroidFarm is returned.
@@ -45,7 +58,7 @@ func (a *AndroidFarm) GetDeviceManagementPolicy(group string) *DeviceManagementP
 }
 
 // GetPodManagementPolicy returns the pod management policy for the device
-// mnanagement policy. Currently there is only "OrderedReady".
+// mnanagement policy. Defaults to GroupedOrderedReady.
 func (d *DeviceManagementPolicy) GetPodManagementPolicy() PodManagementPolicy {
 	if d.PodManagementPolicy == "" {
 		return GroupedOrderedReady
@@ -62,6 +75,44 @@ func (d *DeviceManagementPolicy) GetConcurrency() int32 {
 	return d.Concurrency
 }
 
+// GetMaxUnavailable returns the maximum number of the given desired devices
+// that can be unavailable during a rolling update. At least one device can
+// always be unavailable when no devices can surge.
+func (d *DeviceManagementPolicy) GetMaxUnavailable(desired int32) int32 {
+	maxUnavailable := intstr.FromInt(1)
+	if d.RollingUpdate != nil && d.RollingUpdate.MaxUnavailable != nil {
+		maxUnavailable = *d.RollingUpdate.MaxUnavailable
+	}
+	val, err := intstr.GetValueFromIntOrPercent(&maxUnavailable, int(desired), false)
+	if err != nil {
+		val = 1
+	}
+	if val == 0 && d.GetMaxSurge(desired) == 0 {
+		val = 1
+	}
+	return int32(val)
+}
+
+// GetMaxSurge returns the maximum number of devices that can be created above
+// the given desired devices during a rolling update.
+func (d *DeviceManagementPolicy) GetMaxSurge(desired int32) int32 {
+	if d.RollingUpdate == nil || d.RollingUpdate.MaxSurge == nil {
+		return 0
+	}
+	val, err := intstr.GetValueFromIntOrPercent(d.RollingUpdate.MaxSurge, int(desired), true)
+	if err != nil {
+		return 0
+	}
+	return int32(val)
+}
+
+// HoldsUpdate returns true if a device at the given index should not be updated
+// to a new configuration, because the rollout is paused or the device is below
+// the partition.
+func (d *DeviceManagementPolicy) HoldsUpdate(idx int32) bool {
+	return d.Paused || idx < d.Partition
+}
+
 // MatchingLabels returns the selector for finding devices/pods in this
 // device group.
 func (g *DeviceGroup) MatchingLabels() client.MatchingLabels {
@@ -82,11 +133,13 @@ func (g *DeviceGroup) IsUSBGroup() bool {
 // GetNamespace returns the namespace that devices in this group should be
 // provisioned in.
 func (g *DeviceGroup) GetNamespace() string {
-	if g.Emulators == nil {
-		return ""
-	}
-	if g.Emulators.Namespace != "" {
+	switch {
+	case g.Emulators != nil && g.Emulators.Namespace != "":
 		return g.Emulators.Namespace
+	case g.HostUSB != nil && g.HostUSB.Namespace != "":
+		return g.HostUSB.Namespace
+	case g.Emulators == nil && g.HostUSB == nil:
+		return ""
 	}
 	return "default"
 }
@@ -99,6 +152,29 @@ func (f *DeviceGroup) GetCount() int32 {
 	return f.Emulators.Count
 }
 
+// GetMaxCount returns the maximum number of devices this device group can be
+// scaled to. This is the largest of the count, the max count, and the max count
+// of any autoscaling policy.
+func (f *DeviceGroup) GetMaxCount() int32 {
+	if f.Emulators == nil {
+		return 0
+	}
+	max := f.Emulators.Count
+	if f.Emulators.MaxCount > max {
+		max = f.Emulators.MaxCount
+	}
+	if f.Autoscaling != nil && f.Autoscaling.MaxCount > max {
+		max = f.Autoscaling.MaxCount
+	}
+	return max
+}
+
+// DeviceGroupName returns the name of the AndroidDeviceGroup for the given
+// device group in this farm.
+func (a *AndroidFarm) DeviceGroupName(group *DeviceGroup) string {
+	return fmt.Sprintf("%s-%s", a.Name, group.Name)
+}
+
 // GetConfig returns the desired configuration for this device group. If a config
 // reference is provided, it is looked up first. Then any overrides in the group
 // itself are merged on top of it.
@@ -19,6 +19,20 @@ func (f *DeviceGroup) ProviderNodeSelector() map[string]string {
 	return nil
 }
 
+// UsesUSBNodeSelector returns true if this group runs a provider on every node
+// matching a selector, rather than a single one.
+func (f *DeviceGroup) UsesUSBNodeSelector() bool {
+	return f.HostUSB != nil && len(f.HostUSB.NodeSelector) > 0
+}
+
+// MaxUSBNodes returns the number of nodes provider ports are allocated for.
+func (f *DeviceGroup) MaxUSBNodes() int32 {
+	if f.UsesUSBNodeSelector() && f.HostUSB.MaxNodes != 0 {
+		return f.HostUSB.MaxNodes
+	}
+	return 1
+}
+
 // MaxUSBDevices returns the maximum number of usb devices we expect to run
 // on a given node.
 func (f *DeviceGroup) MaxUSBDevices() int32 {
@@ -36,6 +50,16 @@ func (f *DeviceGroup) GetProviderName() string {
 	return fmt.Sprintf("provider-%s", f.Name)
 }
 
+// GetProviderSlotName returns the name of the provider in the given slot of
+// the device group. Groups using a USB node selector run a provider in a slot
+// for each matching node, all other groups only have the first slot.
+func (f *DeviceGroup) GetProviderSlotName(slot int32) string {
+	if !f.UsesUSBNodeSelector() {
+		return f.GetProviderName()
+	}
+	return fmt.Sprintf("%s-%d", f.GetProviderName(), slot)
+}
+
 // ProviderNoCleanup returns true if providers in this group should persist
 // device state.
 func (f *DeviceGroup) ProviderNoCleanup() bool {
@@ -54,6 +78,26 @@ func (f *DeviceGroup) GetProviderStartPort() int32 {
 	return f.Provider.StartPort
 }
 
+// GetProviderMaxPort returns the last port in the range allocated to the device
+// group's providers. Roughly four ports are allocated per device the group can
+// be scaled to, on each node it runs a provider on.
+func (f *DeviceGroup) GetProviderMaxPort() int32 {
+	_, max := f.GetProviderSlotPorts(f.MaxUSBNodes() - 1)
+	return max
+}
+
+// GetProviderSlotPorts returns the first and last ports in the range allocated
+// to the provider in the given slot of the device group. The slots divide the
+// group's port range evenly.
+func (f *DeviceGroup) GetProviderSlotPorts(slot int32) (int32, int32) {
+	count := f.MaxUSBDevices()
+	if f.IsEmulatedGroup() {
+		count = f.GetMaxCount()
+	}
+	start := f.GetProviderStartPort() + slot*(count*4+1)
+	return start, start + count*4
+}
+
 // ADBPodSecurityContext returns the pod security context to use for adb deployments
 // in this farm.
 func (s *STFConfig) ADBPodSecurityContext(group *DeviceGroup) *corev1.PodSecurityContext {
@@ -106,7 +150,10 @@ func (s *STFConfig) ADBSidecarContainer(providerName string, group *DeviceGroup)
 		Name:            "adb",
 		ImagePullPolicy: s.ADBImagePullPolicy(group),
 		Image:           s.ADBImage(group),
-		Ports:           []corev1.ContainerPort{{Name: "adb-server", ContainerPort: 5037}},
+		Ports: []corev1.ContainerPort{
+			{Name: "adb-server", ContainerPort: 5037},
+			{Name: "adb-metrics", ContainerPort: ADBMetricsPort},
+		},
 		SecurityContext: s.ADBContainerSecurityContext(group),
 		Resources:       s.ADBResourceRequirements(group),
 		Env: []corev1.EnvVar{
@@ -120,10 +167,18 @@ func (s *STFConfig) ADBSidecarContainer(providerName string, group *DeviceGroup)
 		if group.IsEmulatedGroup() {
 			container.Args = []string{"--no-usb", "--provider", providerName}
 		} else if group.IsUSBGroup() {
-			container.Args = []string{"--provider", providerName}
+			container.Args = []string{"--provider", providerName, "--inventory"}
 		}
 	}
 	if group.IsUSBGroup() {
+		// the inventory of attached devices is served for the operator
+		container.Ports = append(container.Ports, corev1.ContainerPort{Name: "inventory", ContainerPort: ADBInventoryPort})
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: "NODE_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+			},
+		})
 		container.VolumeMounts = []corev1.VolumeMount{
 			{
 				Name:      "usb",
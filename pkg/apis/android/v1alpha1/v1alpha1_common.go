@@ -10,6 +10,37 @@ const (
 	// DeviceGroupLabel is the selector matching devices to the device group they
 	// belong to.
 	DeviceGroupLabel = "deviceGroup"
+	// QuickBootChecksumLabel is the selector matching golden snapshot resources,
+	// and the PVCs cloned from them, to the configuration checksum they were
+	// taken for.
+	QuickBootChecksumLabel = "quickBootChecksum"
+	// VolumeChecksumLabel is the selector matching device PVCs to the configuration
+	// checksum they were created for. It is used to wipe volumes with the
+	// WipeOnConfigChange retention policy.
+	VolumeChecksumLabel = "volumeChecksum"
+	// DeviceNodeLabel is the selector matching physical devices to the node they
+	// are attached to.
+	DeviceNodeLabel = "deviceNode"
+)
+
+// Labels placed on nodes by the KVM discovery daemon
+const (
+	// KVMNodeLabel is set to "true" on nodes that have the kvm device and a cpu
+	// with virtualization extensions.
+	KVMNodeLabel = "android.stf.io/kvm"
+	// CPUVirtualizationNodeLabel contains the virtualization extension of the
+	// cpu on a node, either vmx, svm, or none.
+	CPUVirtualizationNodeLabel = "android.stf.io/cpu-virtualization"
+	// NestedVirtualizationNodeLabel is set to "true" on nodes that have nested
+	// virtualization enabled in the kvm module.
+	NestedVirtualizationNodeLabel = "android.stf.io/nested-virtualization"
+)
+
+// Labels placed on resources by users
+const (
+	// JobParametersLabel must be set to "true" on secrets that jobs read
+	// parameter values from.
+	JobParametersLabel = "android.stf.io/job-parameters"
 )
 
 // Annotations used for internal operations on resources
@@ -20,24 +51,46 @@ const (
 	// STFProviderAnnotation contains a reference to the stf-provider instance
 	// that manages a device.
 	STFProviderAnnotation = "android.stf.io/stf-provider"
-	// ADBConnectedAnnotation is used to signal that a device is connected to
-	// its ADB server.
-	ADBConnectedAnnotation = "android.stf.io/adb-connected"
-	// BootCompletedAnnotation is used to signal that a device has completed its
-	// boot process.
-	BootCompletedAnnotation = "android.stf.io/boot-completed"
 	// ConfigMapSHAAnnotation is used to store the checksum of the configmap data
 	// used when a deployment was created. If a deployment is reconciled with a new
 	// checksum, it means its configuration has changed and pods need to be cycled.
 	ConfigMapSHAAnnotation = "android.stf.io/configmap-checksum"
-	// DeviceConfigSHAAnnotation is used to store the checksum of the configuration
-	// used to provision a given device instance.
-	DeviceConfigSHAAnnotation = "android.stf.io/device-config-checksum"
-	// ProviderSerialAnnotation contains the name of a device as known by its
-	// stf provider.
-	ProviderSerialAnnotation = "android.stf.io/stf-serial"
+	// FarmCountAnnotation is used to store the count of an emulator group in its
+	// AndroidFarm when the replicas of its AndroidDeviceGroup were last synced from
+	// it. The replicas are only overwritten when the count in the farm changes.
+	FarmCountAnnotation = "android.stf.io/farm-count"
+	// LeaseAnnotation is placed on an AndroidDevice while it is held by an
+	// AndroidDeviceLease. It contains the name of the lease. Leased devices are
+	// not updated or garbage collected until they are released.
+	LeaseAnnotation = "android.stf.io/lease"
 )
 
+// Annotations used by earlier versions of the operator to track the state of
+// devices. They are only read to seed the status of devices created before it
+// existed, and removed afterwards.
+const (
+	// LegacyADBConnectedAnnotation was set to "true" once a device was connected
+	// to its stf provider.
+	LegacyADBConnectedAnnotation = "android.stf.io/adb-connected"
+	// LegacyBootCompletedAnnotation was set to "true" once a device finished
+	// booting.
+	LegacyBootCompletedAnnotation = "android.stf.io/boot-completed"
+	// LegacyDeviceConfigSHAAnnotation contained the checksum of the configuration
+	// a device was provisioned with.
+	LegacyDeviceConfigSHAAnnotation = "android.stf.io/device-config-checksum"
+	// LegacyProviderSerialAnnotation contained the name of a device as known by
+	// its stf provider.
+	LegacyProviderSerialAnnotation = "android.stf.io/stf-serial"
+)
+
+// ADBInventoryPort is the port the ADB servers of host USB providers serve the
+// inventory of their attached devices on.
+const ADBInventoryPort = 5038
+
+// ADBMetricsPort is the port the ADB servers of providers serve prometheus
+// metrics on.
+const ADBMetricsPort = 5039
+
 // Defaults and other static vars
 var (
 	// defaultSTFImage is the default STF image to use for OpenSTF deployments.
@@ -8,6 +8,7 @@ import (
 	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -65,6 +66,11 @@ func (in *Action) DeepCopyInto(out *Action) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(WaitCondition)
+		**out = **in
+	}
 	if in.Interactions != nil {
 		in, out := &in.Interactions, &out.Interactions
 		*out = make([]Interaction, len(*in))
@@ -89,7 +95,7 @@ func (in *AndroidDevice) DeepCopyInto(out *AndroidDevice) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -220,6 +226,21 @@ func (in *AndroidDeviceConfigSpec) DeepCopyInto(out *AndroidDeviceConfigSpec) {
 		*out = new(TCPRedirConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.QuickBoot != nil {
+		in, out := &in.QuickBoot, &out.QuickBoot
+		*out = new(QuickBootConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthPolicy != nil {
+		in, out := &in.HealthPolicy, &out.HealthPolicy
+		*out = new(HealthPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(SchedulingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -249,6 +270,216 @@ func (in *AndroidDeviceConfigStatus) DeepCopy() *AndroidDeviceConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceGroup) DeepCopyInto(out *AndroidDeviceGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceGroup.
+func (in *AndroidDeviceGroup) DeepCopy() *AndroidDeviceGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AndroidDeviceGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceGroupList) DeepCopyInto(out *AndroidDeviceGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AndroidDeviceGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceGroupList.
+func (in *AndroidDeviceGroupList) DeepCopy() *AndroidDeviceGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AndroidDeviceGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceGroupSpec) DeepCopyInto(out *AndroidDeviceGroupSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceGroupSpec.
+func (in *AndroidDeviceGroupSpec) DeepCopy() *AndroidDeviceGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceGroupStatus) DeepCopyInto(out *AndroidDeviceGroupStatus) {
+	*out = *in
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceGroupStatus.
+func (in *AndroidDeviceGroupStatus) DeepCopy() *AndroidDeviceGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceLease) DeepCopyInto(out *AndroidDeviceLease) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceLease.
+func (in *AndroidDeviceLease) DeepCopy() *AndroidDeviceLease {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceLease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AndroidDeviceLease) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceLeaseList) DeepCopyInto(out *AndroidDeviceLeaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AndroidDeviceLease, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceLeaseList.
+func (in *AndroidDeviceLeaseList) DeepCopy() *AndroidDeviceLeaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceLeaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AndroidDeviceLeaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceLeaseSpec) DeepCopyInto(out *AndroidDeviceLeaseSpec) {
+	*out = *in
+	if in.DeviceSelector != nil {
+		in, out := &in.DeviceSelector, &out.DeviceSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(LeaseOwner)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceLeaseSpec.
+func (in *AndroidDeviceLeaseSpec) DeepCopy() *AndroidDeviceLeaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceLeaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AndroidDeviceLeaseStatus) DeepCopyInto(out *AndroidDeviceLeaseStatus) {
+	*out = *in
+	if in.AcquiredAt != nil {
+		in, out := &in.AcquiredAt, &out.AcquiredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AndroidDeviceLeaseStatus.
+func (in *AndroidDeviceLeaseStatus) DeepCopy() *AndroidDeviceLeaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AndroidDeviceLeaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AndroidDeviceList) DeepCopyInto(out *AndroidDeviceList) {
 	*out = *in
@@ -311,6 +542,40 @@ func (in *AndroidDeviceSpec) DeepCopy() *AndroidDeviceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AndroidDeviceStatus) DeepCopyInto(out *AndroidDeviceStatus) {
 	*out = *in
+	if in.BootStartedAt != nil {
+		in, out := &in.BootStartedAt, &out.BootStartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.BootCompletedAt != nil {
+		in, out := &in.BootCompletedAt, &out.BootCompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastHealthCheck != nil {
+		in, out := &in.LastHealthCheck, &out.LastHealthCheck
+		*out = (*in).DeepCopy()
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(DeviceHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]DeviceVolumeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = new(DeviceProperties)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -330,7 +595,7 @@ func (in *AndroidFarm) DeepCopyInto(out *AndroidFarm) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -402,7 +667,7 @@ func (in *AndroidFarmSpec) DeepCopyInto(out *AndroidFarmSpec) {
 	if in.DeviceManagementPolicy != nil {
 		in, out := &in.DeviceManagementPolicy, &out.DeviceManagementPolicy
 		*out = new(DeviceManagementPolicy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.STFConfig != nil {
 		in, out := &in.STFConfig, &out.STFConfig
@@ -425,6 +690,23 @@ func (in *AndroidFarmSpec) DeepCopy() *AndroidFarmSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AndroidFarmStatus) DeepCopyInto(out *AndroidFarmStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeviceGroups != nil {
+		in, out := &in.DeviceGroups, &out.DeviceGroups
+		*out = make([]DeviceGroupStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -514,6 +796,23 @@ func (in *AndroidJobSpec) DeepCopyInto(out *AndroidJobSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]JobParameterValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -530,11 +829,12 @@ func (in *AndroidJobSpec) DeepCopy() *AndroidJobSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AndroidJobStatus) DeepCopyInto(out *AndroidJobStatus) {
 	*out = *in
+	out.Summary = in.Summary
 	if in.JobStatus != nil {
 		in, out := &in.JobStatus, &out.JobStatus
 		*out = make(map[string]DeviceJobStatus, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	return
@@ -614,6 +914,13 @@ func (in *AndroidJobTemplateList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AndroidJobTemplateSpec) DeepCopyInto(out *AndroidJobTemplateSpec) {
 	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]JobParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Actions != nil {
 		in, out := &in.Actions, &out.Actions
 		*out = make([]Action, len(*in))
@@ -621,6 +928,11 @@ func (in *AndroidJobTemplateSpec) DeepCopyInto(out *AndroidJobTemplateSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = new(ArtifactsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -671,6 +983,59 @@ func (in *AppConfig) DeepCopy() *AppConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactSink) DeepCopyInto(out *ArtifactSink) {
+	*out = *in
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(VolumeSink)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Sink)
+		**out = **in
+	}
+	if in.STF != nil {
+		in, out := &in.STF, &out.STF
+		*out = new(STFSink)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactSink.
+func (in *ArtifactSink) DeepCopy() *ArtifactSink {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactsConfig) DeepCopyInto(out *ArtifactsConfig) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Sink.DeepCopyInto(&out.Sink)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactsConfig.
+func (in *ArtifactsConfig) DeepCopy() *ArtifactsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthConfig) DeepCopyInto(out *AuthConfig) {
 	*out = *in
@@ -698,15 +1063,58 @@ func (in *AuthConfig) DeepCopy() *AuthConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeviceGroup) DeepCopyInto(out *DeviceGroup) {
+func (in *AutoscalingPolicy) DeepCopyInto(out *AutoscalingPolicy) {
 	*out = *in
-	if in.Provider != nil {
-		in, out := &in.Provider, &out.Provider
-		*out = new(ProviderConfig)
-		(*in).DeepCopyInto(*out)
+	if in.ScaleUpCooldownSeconds != nil {
+		in, out := &in.ScaleUpCooldownSeconds, &out.ScaleUpCooldownSeconds
+		*out = new(int32)
+		**out = **in
 	}
-	if in.ADB != nil {
-		in, out := &in.ADB, &out.ADB
+	if in.ScaleDownCooldownSeconds != nil {
+		in, out := &in.ScaleDownCooldownSeconds, &out.ScaleDownCooldownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingPolicy.
+func (in *AutoscalingPolicy) DeepCopy() *AutoscalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceGroup) DeepCopyInto(out *DeviceGroup) {
+	*out = *in
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(ProviderConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ADB != nil {
+		in, out := &in.ADB, &out.ADB
 		*out = new(ADBConfig)
 		(*in).DeepCopyInto(*out)
 	}
@@ -718,7 +1126,12 @@ func (in *DeviceGroup) DeepCopyInto(out *DeviceGroup) {
 	if in.HostUSB != nil {
 		in, out := &in.HostUSB, &out.HostUSB
 		*out = new(HostUSBConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingPolicy)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -733,9 +1146,65 @@ func (in *DeviceGroup) DeepCopy() *DeviceGroup {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceGroupStatus) DeepCopyInto(out *DeviceGroupStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceGroupStatus.
+func (in *DeviceGroupStatus) DeepCopy() *DeviceGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceHealthStatus) DeepCopyInto(out *DeviceHealthStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastActionTime != nil {
+		in, out := &in.LastActionTime, &out.LastActionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceHealthStatus.
+func (in *DeviceHealthStatus) DeepCopy() *DeviceHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceJobStatus) DeepCopyInto(out *DeviceJobStatus) {
 	*out = *in
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]JobStepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]JobArtifact, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -752,6 +1221,11 @@ func (in *DeviceJobStatus) DeepCopy() *DeviceJobStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceManagementPolicy) DeepCopyInto(out *DeviceManagementPolicy) {
 	*out = *in
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -765,13 +1239,50 @@ func (in *DeviceManagementPolicy) DeepCopy() *DeviceManagementPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceProperties) DeepCopyInto(out *DeviceProperties) {
+	*out = *in
+	if in.BatteryLevel != nil {
+		in, out := &in.BatteryLevel, &out.BatteryLevel
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceProperties.
+func (in *DeviceProperties) DeepCopy() *DeviceProperties {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceProperties)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceVolumeStatus) DeepCopyInto(out *DeviceVolumeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceVolumeStatus.
+func (in *DeviceVolumeStatus) DeepCopy() *DeviceVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmulatorConfig) DeepCopyInto(out *EmulatorConfig) {
 	*out = *in
 	if in.DeviceManagementPolicy != nil {
 		in, out := &in.DeviceManagementPolicy, &out.DeviceManagementPolicy
 		*out = new(DeviceManagementPolicy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.ConfigRef != nil {
 		in, out := &in.ConfigRef, &out.ConfigRef
@@ -817,9 +1328,53 @@ func (in *GlobalProviderConfig) DeepCopy() *GlobalProviderConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
+func (in *HealthCheck) DeepCopy() *HealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthPolicy) DeepCopyInto(out *HealthPolicy) {
+	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]HealthCheck, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthPolicy.
+func (in *HealthPolicy) DeepCopy() *HealthPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostUSBConfig) DeepCopyInto(out *HostUSBConfig) {
 	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -849,6 +1404,172 @@ func (in *Interaction) DeepCopy() *Interaction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobArtifact) DeepCopyInto(out *JobArtifact) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobArtifact.
+func (in *JobArtifact) DeepCopy() *JobArtifact {
+	if in == nil {
+		return nil
+	}
+	out := new(JobArtifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobParameter) DeepCopyInto(out *JobParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobParameter.
+func (in *JobParameter) DeepCopy() *JobParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(JobParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobParameterValue) DeepCopyInto(out *JobParameterValue) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ParameterValueSource)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobParameterValue.
+func (in *JobParameterValue) DeepCopy() *JobParameterValue {
+	if in == nil {
+		return nil
+	}
+	out := new(JobParameterValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobStepStatus) DeepCopyInto(out *JobStepStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int)
+		**out = **in
+	}
+	if in.FullOutput != nil {
+		in, out := &in.FullOutput, &out.FullOutput
+		*out = new(StepOutputRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobStepStatus.
+func (in *JobStepStatus) DeepCopy() *JobStepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobStepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSummary) DeepCopyInto(out *JobSummary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSummary.
+func (in *JobSummary) DeepCopy() *JobSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseOwner) DeepCopyInto(out *LeaseOwner) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseOwner.
+func (in *LeaseOwner) DeepCopy() *LeaseOwner {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseOwner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterValueSource) DeepCopyInto(out *ParameterValueSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterValueSource.
+func (in *ParameterValueSource) DeepCopy() *ParameterValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProcessorConfig) DeepCopyInto(out *ProcessorConfig) {
 	*out = *in
@@ -896,6 +1617,23 @@ func (in *ProviderConfig) DeepCopy() *ProviderConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuickBootConfig) DeepCopyInto(out *QuickBootConfig) {
+	*out = *in
+	in.PVCSpec.DeepCopyInto(&out.PVCSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuickBootConfig.
+func (in *QuickBootConfig) DeepCopy() *QuickBootConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QuickBootConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReaperConfig) DeepCopyInto(out *ReaperConfig) {
 	*out = *in
@@ -964,6 +1702,48 @@ func (in *RethinkDBProxyConfig) DeepCopy() *RethinkDBProxyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateConfig) DeepCopyInto(out *RollingUpdateConfig) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateConfig.
+func (in *RollingUpdateConfig) DeepCopy() *RollingUpdateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Sink) DeepCopyInto(out *S3Sink) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Sink.
+func (in *S3Sink) DeepCopy() *S3Sink {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Sink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *STFConfig) DeepCopyInto(out *STFConfig) {
 	*out = *in
@@ -1097,6 +1877,80 @@ func (in *STFOAuth) DeepCopy() *STFOAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *STFSink) DeepCopyInto(out *STFSink) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new STFSink.
+func (in *STFSink) DeepCopy() *STFSink {
+	if in == nil {
+		return nil
+	}
+	out := new(STFSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingConfig) DeepCopyInto(out *SchedulingConfig) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingConfig.
+func (in *SchedulingConfig) DeepCopy() *SchedulingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepOutputRef) DeepCopyInto(out *StepOutputRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepOutputRef.
+func (in *StepOutputRef) DeepCopy() *StepOutputRef {
+	if in == nil {
+		return nil
+	}
+	out := new(StepOutputRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 	*out = *in
@@ -1309,6 +2163,38 @@ func (in *Volume) DeepCopy() *Volume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSink) DeepCopyInto(out *VolumeSink) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSink.
+func (in *VolumeSink) DeepCopy() *VolumeSink {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitCondition) DeepCopyInto(out *WaitCondition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitCondition.
+func (in *WaitCondition) DeepCopy() *WaitCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebsocketConfig) DeepCopyInto(out *WebsocketConfig) {
 	*out = *in
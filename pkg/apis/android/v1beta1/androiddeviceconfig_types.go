@@ -0,0 +1,205 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AndroidDeviceConfigSpec defines the desired state of AndroidDeviceConfig
+type AndroidDeviceConfigSpec struct {
+	// The docker image to use for emulator devices
+	DockerImage string `json:"dockerImage,omitempty"`
+	// The pull policy to use for emulator pods
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Pull secrets required for the docker image.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// The ADB port that the emulator listens on. Defaults to 5555.
+	// A sidecar will be spawned within emulator pods that redirects external
+	// traffic to this port.
+	ADBPort int32 `json:"adbPort,omitempty"`
+	// An optional command to run when starting an emulator image
+	Command []string `json:"command,omitempty"`
+	// Any arguments to pass to the above command.
+	Args []string `json:"args,omitempty"`
+	// Extra port mappings to apply to the emulator pods.
+	ExtraPorts []corev1.ContainerPort `json:"extraPorts,omitempty"`
+	// Extra environment variables to supply to the emulator pods.
+	ExtraEnvVars []corev1.EnvVar `json:"extraEnvVars,omitempty"`
+	// Whether to mount the kvm device to the pods, will require that the operator
+	// can launch privileged pods.
+	KVMEnabled bool `json:"kvmEnabled,omitempty"`
+	// A list of volume configurations to apply to the emulator pods.
+	Volumes []Volume `json:"volumes,omitempty"`
+	// Resource restraints to place on the emulators.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// A list of AndroidJobTemplates to execute against new instances.
+	// TODO: Very very very beta
+	StartupJobTemplates []string `json:"startupJobTemplates,omitempty"`
+	// Configuration for the tcp redirection sidecar. The sidecar is only run when
+	// this is set.
+	TCPRedir *TCPRedirConfig `json:"tcpRedir,omitempty"`
+	// Configuration for booting emulators from a golden quick-boot snapshot
+	// instead of cold booting them.
+	QuickBoot *QuickBootConfig `json:"quickBoot,omitempty"`
+	// A policy for checking the health of booted emulators and remediating
+	// the ones that fail.
+	HealthPolicy *HealthPolicy `json:"healthPolicy,omitempty"`
+	// Scheduling constraints for the emulator pods. Device groups can override
+	// these in their deviceConfig.
+	Scheduling *SchedulingConfig `json:"scheduling,omitempty"`
+}
+
+// SchedulingConfig represents the scheduling constraints for emulator pods.
+// When neither a pod anti-affinity nor topology spread constraints are given,
+// the devices of a group prefer to be spread across nodes.
+type SchedulingConfig struct {
+	// A node selector to apply to the emulator pods.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// The affinity of the emulator pods.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Tolerations to apply to the emulator pods.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// The priority class of the emulator pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Topology spread constraints for the emulator pods. These require the
+	// EvenPodsSpread feature gate on kubernetes versions before 1.18.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// Volume represents a volume configuration for the emulator.
+type Volume struct {
+	// The name of the volume. It is prefixed to the PVCs created for devices using
+	// this configuration.
+	Name string `json:"name"`
+	// Where to mount the volume in emulator pods.
+	MountPath string `json:"mountPath"`
+	// A PVC spec to use for creating the emulator volumes.
+	PVCSpec corev1.PersistentVolumeClaimSpec `json:"pvcSpec"`
+	// What to do with the PVCs of devices when they are deleted or their
+	// configuration changes. Defaults to Retain.
+	RetentionPolicy VolumeRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// VolumeRetentionPolicy represents what happens to the PVCs of emulator devices
+// when they are deleted or their configuration changes.
+type VolumeRetentionPolicy string
+
+const (
+	// VolumeRetain keeps PVCs when devices are deleted, so they are reused when
+	// a device with the same name is created again.
+	VolumeRetain VolumeRetentionPolicy = "Retain"
+	// VolumeDelete makes the device the owner of its PVCs, so they are deleted
+	// along with it.
+	VolumeDelete VolumeRetentionPolicy = "Delete"
+	// VolumeWipeOnConfigChange keeps PVCs when devices are deleted, but replaces
+	// them with empty ones when the configuration of the device changes.
+	VolumeWipeOnConfigChange VolumeRetentionPolicy = "WipeOnConfigChange"
+)
+
+// TCPRedirConfig is the configuration for a sidecar run with emulator pods that
+// redirects TCP traffic on the adb port to the emulator adb server listening on
+// the loopback interface. This is required for the image used in this repository,
+// but if you are using an image that exposes ADB on all interfaces itself, this
+// is not required.
+type TCPRedirConfig struct {
+	// Image is the repository to download the image from.
+	// Defaults to quay.io/tinyzimmer/goredir whose source is in this repository.
+	Image string `json:"image,omitempty"`
+	// The pull policy to attach to deployments using this image.
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Any pull secrets required for downloading the image.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// QuickBootConfig configures golden snapshots for emulators. A golden snapshot
+// is taken once for every configuration checksum, in every namespace with devices
+// using the configuration, and emulator pods are booted from it. The emulator
+// image must support the QUICKBOOT_MODE and QUICKBOOT_DIR environment variables,
+// like the image in this repository does.
+type QuickBootConfig struct {
+	// A PVC spec to use for the volume holding the golden snapshot. Unless a
+	// volumeSnapshotClassName is provided, this volume is mounted read-only into
+	// every emulator pod, so its access modes should allow that (e.g. ReadOnlyMany).
+	PVCSpec corev1.PersistentVolumeClaimSpec `json:"pvcSpec"`
+	// When set, a VolumeSnapshot is taken of the golden volume with this class,
+	// and every emulator pod is given its own PVC cloned from it.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// HealthPolicy configures periodic health checks against booted emulators.
+// When the checks fail failureThreshold times in a row, the device is
+// remediated, escalating from reconnecting it to its STF provider, to rebooting
+// the emulator, to recreating its pod.
+type HealthPolicy struct {
+	// How often to run the checks, in seconds. Defaults to 60.
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+	// The checks to run against the device. Defaults to ADBReachable and
+	// BootCompleted.
+	Checks []HealthCheck `json:"checks,omitempty"`
+	// The number of consecutive failed checks before the device is remediated.
+	// Defaults to 3.
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// HealthCheckType represents a type of health check run against an emulator.
+type HealthCheckType string
+
+const (
+	// ADBReachable checks that the emulator accepts ADB connections and is not
+	// offline.
+	ADBReachable HealthCheckType = "ADBReachable"
+	// BootCompleted checks that sys.boot_completed is still set on the emulator.
+	BootCompleted HealthCheckType = "BootCompleted"
+	// ShellProbe runs a shell command on the emulator and checks its output.
+	ShellProbe HealthCheckType = "ShellProbe"
+	// ScreenNotFrozen checks that the screen is not entirely black and has
+	// changed since the previous check. Since an idle emulator may leave its
+	// screen untouched, this is best used with a long interval or a device that
+	// is known to be animating.
+	ScreenNotFrozen HealthCheckType = "ScreenNotFrozen"
+)
+
+// HealthCheck represents a single check run against an emulator.
+type HealthCheck struct {
+	// The type of the check.
+	Type HealthCheckType `json:"type"`
+	// For ShellProbe checks, the shell command to run on the device. The check
+	// fails if the command exits non-zero.
+	Command string `json:"command,omitempty"`
+	// For ShellProbe checks, a string that must appear in the output of the
+	// command.
+	ExpectedOutput string `json:"expectedOutput,omitempty"`
+}
+
+// AndroidDeviceConfigStatus defines the observed state of AndroidDeviceConfig
+type AndroidDeviceConfigStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
+	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AndroidDeviceConfig is the Schema for the androiddeviceconfigs API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=androiddeviceconfigs,scope=Cluster
+type AndroidDeviceConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AndroidDeviceConfigSpec   `json:"spec,omitempty"`
+	Status AndroidDeviceConfigStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AndroidDeviceConfigList contains a list of AndroidDeviceConfig
+type AndroidDeviceConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AndroidDeviceConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AndroidDeviceConfig{}, &AndroidDeviceConfigList{})
+}
@@ -0,0 +1,24 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionType represents the type of a status condition on an android.stf.io
+// resource.
+type ConditionType string
+
+// Condition represents an observation of a single aspect of a resource's state.
+type Condition struct {
+	// The type of the condition.
+	Type ConditionType `json:"type"`
+	// The status of the condition, one of True, False, or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// A human readable message with details about the transition.
+	Message string `json:"message,omitempty"`
+	// The last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
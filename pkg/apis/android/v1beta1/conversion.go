@@ -0,0 +1,256 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// DisabledTCPRedirAnnotation preserves v1alpha1 tcpRedir configurations that
+// are present but disabled. These have no representation in v1beta1, where the
+// sidecar is enabled by the presence of the configuration, so they are stashed
+// here to keep conversions lossless. The value is a JSON object mapping the
+// path of each device config to its v1alpha1 tcpRedir.
+const DisabledTCPRedirAnnotation = "android.stf.io/v1alpha1-disabled-tcp-redir"
+
+var _ conversion.Convertible = &AndroidFarm{}
+var _ conversion.Convertible = &AndroidDevice{}
+var _ conversion.Convertible = &AndroidDeviceConfig{}
+
+// ConvertTo converts this AndroidFarm to the hub version.
+func (a *AndroidFarm) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.AndroidFarm)
+	dst.ObjectMeta = *a.ObjectMeta.DeepCopy()
+	stash, err := popDisabledTCPRedirs(&dst.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	dst.Spec = v1alpha1.AndroidFarmSpec{}
+	if err := convertJSON(&a.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	for idx, group := range a.Spec.DeviceGroups {
+		if group == nil || group.Emulators == nil || group.Emulators.DeviceConfig == nil {
+			continue
+		}
+		path := groupDeviceConfigPath(group.Name)
+		if err := group.Emulators.DeviceConfig.convertTo(dst.Spec.DeviceGroups[idx].Emulators.DeviceConfig, stash[path]); err != nil {
+			return err
+		}
+	}
+	dst.Status = v1alpha1.AndroidFarmStatus{}
+	return convertJSON(&a.Status, &dst.Status)
+}
+
+// ConvertFrom converts the hub version of an AndroidFarm to this version.
+func (a *AndroidFarm) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.AndroidFarm)
+	a.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	stash := make(map[string]*v1alpha1.TCPRedirConfig)
+	a.Spec = AndroidFarmSpec{}
+	if err := convertJSON(&src.Spec, &a.Spec); err != nil {
+		return err
+	}
+	for idx, group := range src.Spec.DeviceGroups {
+		if group == nil || group.Emulators == nil || group.Emulators.DeviceConfig == nil {
+			continue
+		}
+		disabled, err := a.Spec.DeviceGroups[idx].Emulators.DeviceConfig.convertFrom(group.Emulators.DeviceConfig)
+		if err != nil {
+			return err
+		}
+		if disabled != nil {
+			stash[groupDeviceConfigPath(group.Name)] = disabled
+		}
+	}
+	if err := pushDisabledTCPRedirs(&a.ObjectMeta, stash); err != nil {
+		return err
+	}
+	a.Status = AndroidFarmStatus{}
+	return convertJSON(&src.Status, &a.Status)
+}
+
+// ConvertTo converts this AndroidDevice to the hub version.
+func (a *AndroidDevice) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.AndroidDevice)
+	dst.ObjectMeta = *a.ObjectMeta.DeepCopy()
+	stash, err := popDisabledTCPRedirs(&dst.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	if a.Spec.STFProvider != "" {
+		metav1.SetMetaDataAnnotation(&dst.ObjectMeta, v1alpha1.STFProviderAnnotation, a.Spec.STFProvider)
+	}
+	dst.Spec = v1alpha1.AndroidDeviceSpec{
+		ConfigRef: a.Spec.ConfigRef.DeepCopy(),
+		Hostname:  a.Spec.Hostname,
+		Subdomain: a.Spec.Subdomain,
+		Source:    v1alpha1.DeviceSource(a.Spec.Source),
+	}
+	if a.Spec.DeviceConfig != nil {
+		dst.Spec.DeviceConfig = &v1alpha1.AndroidDeviceConfigSpec{}
+		if err := a.Spec.DeviceConfig.convertTo(dst.Spec.DeviceConfig, stash[deviceConfigPath]); err != nil {
+			return err
+		}
+	}
+	dst.Status = v1alpha1.AndroidDeviceStatus{}
+	return convertJSON(&a.Status, &dst.Status)
+}
+
+// ConvertFrom converts the hub version of an AndroidDevice to this version.
+func (a *AndroidDevice) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.AndroidDevice)
+	a.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	a.Spec = AndroidDeviceSpec{
+		ConfigRef: src.Spec.ConfigRef.DeepCopy(),
+		Hostname:  src.Spec.Hostname,
+		Subdomain: src.Spec.Subdomain,
+		Source:    DeviceSource(src.Spec.Source),
+	}
+	if provider, ok := a.Annotations[v1alpha1.STFProviderAnnotation]; ok {
+		a.Spec.STFProvider = provider
+		delete(a.Annotations, v1alpha1.STFProviderAnnotation)
+		if len(a.Annotations) == 0 {
+			a.Annotations = nil
+		}
+	}
+	stash := make(map[string]*v1alpha1.TCPRedirConfig)
+	if src.Spec.DeviceConfig != nil {
+		a.Spec.DeviceConfig = &AndroidDeviceConfigSpec{}
+		disabled, err := a.Spec.DeviceConfig.convertFrom(src.Spec.DeviceConfig)
+		if err != nil {
+			return err
+		}
+		if disabled != nil {
+			stash[deviceConfigPath] = disabled
+		}
+	}
+	if err := pushDisabledTCPRedirs(&a.ObjectMeta, stash); err != nil {
+		return err
+	}
+	a.Status = AndroidDeviceStatus{}
+	return convertJSON(&src.Status, &a.Status)
+}
+
+// ConvertTo converts this AndroidDeviceConfig to the hub version.
+func (a *AndroidDeviceConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.AndroidDeviceConfig)
+	dst.ObjectMeta = *a.ObjectMeta.DeepCopy()
+	stash, err := popDisabledTCPRedirs(&dst.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	dst.Status = v1alpha1.AndroidDeviceConfigStatus{}
+	return a.Spec.convertTo(&dst.Spec, stash[specPath])
+}
+
+// ConvertFrom converts the hub version of an AndroidDeviceConfig to this version.
+func (a *AndroidDeviceConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.AndroidDeviceConfig)
+	a.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	a.Status = AndroidDeviceConfigStatus{}
+	disabled, err := a.Spec.convertFrom(&src.Spec)
+	if err != nil {
+		return err
+	}
+	stash := make(map[string]*v1alpha1.TCPRedirConfig)
+	if disabled != nil {
+		stash[specPath] = disabled
+	}
+	return pushDisabledTCPRedirs(&a.ObjectMeta, stash)
+}
+
+// convertTo converts this device config spec into the given v1alpha1 spec. If
+// disabled is not nil and no tcpRedir is configured, it is restored as the
+// v1alpha1 tcpRedir.
+func (c *AndroidDeviceConfigSpec) convertTo(dst *v1alpha1.AndroidDeviceConfigSpec, disabled *v1alpha1.TCPRedirConfig) error {
+	*dst = v1alpha1.AndroidDeviceConfigSpec{}
+	if err := convertJSON(c, dst); err != nil {
+		return err
+	}
+	if c.TCPRedir != nil {
+		dst.TCPRedir.Enabled = true
+	} else if disabled != nil {
+		dst.TCPRedir = disabled
+	}
+	for idx, volume := range c.Volumes {
+		dst.Volumes[idx].VolumePrefix = volume.Name
+		dst.Volumes[idx].MountPoint = volume.MountPath
+	}
+	return nil
+}
+
+// convertFrom converts the given v1alpha1 spec into this device config spec. If
+// the v1alpha1 spec contains a disabled tcpRedir, it is returned so it can be
+// preserved on the object.
+func (c *AndroidDeviceConfigSpec) convertFrom(src *v1alpha1.AndroidDeviceConfigSpec) (disabled *v1alpha1.TCPRedirConfig, err error) {
+	*c = AndroidDeviceConfigSpec{}
+	if err := convertJSON(src, c); err != nil {
+		return nil, err
+	}
+	if src.TCPRedir != nil && !src.TCPRedir.Enabled {
+		c.TCPRedir = nil
+		disabled = src.TCPRedir.DeepCopy()
+	}
+	for idx, volume := range src.Volumes {
+		c.Volumes[idx].Name = volume.VolumePrefix
+		c.Volumes[idx].MountPath = volume.MountPoint
+	}
+	return disabled, nil
+}
+
+// Paths used as keys in the DisabledTCPRedirAnnotation
+const (
+	specPath         = "spec"
+	deviceConfigPath = "spec.deviceConfig"
+)
+
+func groupDeviceConfigPath(group string) string {
+	return fmt.Sprintf("spec.deviceGroups[%s].emulators.deviceConfig", group)
+}
+
+// popDisabledTCPRedirs removes the DisabledTCPRedirAnnotation from the given
+// object meta and returns its contents.
+func popDisabledTCPRedirs(meta *metav1.ObjectMeta) (map[string]*v1alpha1.TCPRedirConfig, error) {
+	stash := make(map[string]*v1alpha1.TCPRedirConfig)
+	raw, ok := meta.Annotations[DisabledTCPRedirAnnotation]
+	if !ok {
+		return stash, nil
+	}
+	delete(meta.Annotations, DisabledTCPRedirAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	if err := json.Unmarshal([]byte(raw), &stash); err != nil {
+		return nil, fmt.Errorf("Could not decode %s annotation: %s", DisabledTCPRedirAnnotation, err.Error())
+	}
+	return stash, nil
+}
+
+// pushDisabledTCPRedirs writes the given disabled tcpRedir configurations to
+// the DisabledTCPRedirAnnotation on the given object meta, if there are any.
+func pushDisabledTCPRedirs(meta *metav1.ObjectMeta, stash map[string]*v1alpha1.TCPRedirConfig) error {
+	if len(stash) == 0 {
+		return nil
+	}
+	out, err := json.Marshal(stash)
+	if err != nil {
+		return err
+	}
+	metav1.SetMetaDataAnnotation(meta, DisabledTCPRedirAnnotation, string(out))
+	return nil
+}
+
+// convertJSON copies the fields of src into dst that share the same JSON
+// representation in both versions.
+func convertJSON(src, dst interface{}) error {
+	out, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(out, dst)
+}
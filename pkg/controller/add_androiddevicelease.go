@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"github.com/tinyzimmer/android-farm-operator/pkg/controller/androiddevicelease"
+)
+
+func init() {
+	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
+	AddToManagerFuncs = append(AddToManagerFuncs, androiddevicelease.Add)
+}
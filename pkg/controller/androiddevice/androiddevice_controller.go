@@ -5,13 +5,16 @@ import (
 	"fmt"
 
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/metrics"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources/emulators"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/events"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -31,7 +34,11 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileAndroidDevice{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileAndroidDevice{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: events.NewRateLimitedRecorder(mgr.GetEventRecorderFor("androiddevice-controller"), events.DefaultInterval),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -57,11 +64,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// Watch created volumes and requeue for owner
-	// TOOD: Currently we leave volumes in tact - should this behavior change?
+	// Watch created volumes and requeue for owner. Only volumes with the Delete
+	// retention policy are owned by their device.
 	err = c.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
-		OwnerType:    &androidv1alpha1.AndroidFarm{},
+		OwnerType:    &androidv1alpha1.AndroidDevice{},
 	})
 	if err != nil {
 		return err
@@ -115,8 +122,9 @@ var _ reconcile.Reconciler = &ReconcileAndroidDevice{}
 type ReconcileAndroidDevice struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a AndroidDevice object and makes changes based on the state read
@@ -142,10 +150,17 @@ func (r *ReconcileAndroidDevice) Reconcile(request reconcile.Request) (reconcile
 		return reconcile.Result{}, err
 	}
 
-	reconciler := emulators.NewForDevice(r.client, r.scheme)
+	// physical devices are maintained by the AndroidFarm controller from the
+	// inventory of their provider
+	if instance.IsPhysicalDevice() {
+		return reconcile.Result{}, nil
+	}
+
+	reconciler := emulators.NewForDevice(r.client, r.scheme, r.recorder)
 	if err := reconciler.Reconcile(reqLogger, instance); err != nil {
 		if requeue, ok := errors.IsRequeueError(err); ok {
 			reqLogger.Info(err.Error())
+			metrics.RecordRequeue("androiddevice-controller", requeue)
 			return reconcile.Result{
 				Requeue:      true,
 				RequeueAfter: requeue.Duration(),
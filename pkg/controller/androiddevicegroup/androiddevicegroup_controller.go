@@ -0,0 +1,137 @@
+package androiddevicegroup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_androiddevicegroup")
+
+// syncPeriod is how often device usage is checked for groups with an
+// autoscaling policy.
+var syncPeriod = time.Duration(30) * time.Second
+
+// Add creates a new AndroidDeviceGroup Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileAndroidDeviceGroup{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("androiddevicegroup-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource AndroidDeviceGroup
+	err = c.Watch(&source.Kind{Type: &androidv1alpha1.AndroidDeviceGroup{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch farms and requeue their device groups, so changes to autoscaling
+	// policies are picked up.
+	err = c.Watch(
+		&source.Kind{Type: &androidv1alpha1.AndroidFarm{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				reqs, err := getFarmDeviceGroups(mgr.GetClient(), a.Meta.GetName())
+				if err != nil {
+					fmt.Println("Error requeuing device groups:", err)
+				}
+				return reqs
+			}),
+		})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getFarmDeviceGroups returns the device groups that belong to the given farm.
+func getFarmDeviceGroups(c client.Client, farm string) ([]reconcile.Request, error) {
+	reqs := make([]reconcile.Request, 0)
+	groups := &androidv1alpha1.AndroidDeviceGroupList{}
+	if err := c.List(context.TODO(), groups, client.InNamespace(metav1.NamespaceAll), client.MatchingLabels{androidv1alpha1.DeviceFarmLabel: farm}); err != nil {
+		return reqs, err
+	}
+	for _, group := range groups.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: group.NamespacedName()})
+	}
+	return reqs, nil
+}
+
+// blank assignment to verify that ReconcileAndroidDeviceGroup implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileAndroidDeviceGroup{}
+
+// ReconcileAndroidDeviceGroup reconciles a AndroidDeviceGroup object
+type ReconcileAndroidDeviceGroup struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a AndroidDeviceGroup object and applies
+// the autoscaling policy of its farm device group, if it has one. The replicas of the
+// AndroidDeviceGroup are adjusted, and the AndroidFarm controller takes care of
+// creating and removing devices.
+func (r *ReconcileAndroidDeviceGroup) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	// Fetch the AndroidDeviceGroup instance
+	instance := &androidv1alpha1.AndroidDeviceGroup{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if instance.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	// Fetch the farm and device group configuration
+	farm := &androidv1alpha1.AndroidFarm{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Labels[androidv1alpha1.DeviceFarmLabel], Namespace: metav1.NamespaceAll}, farm); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	group := farm.GetDeviceGroup(instance.Labels[androidv1alpha1.DeviceGroupLabel])
+	if group == nil || group.Autoscaling == nil || !group.IsEmulatedGroup() || farm.STFDisabled() {
+		return reconcile.Result{}, nil
+	}
+
+	reqLogger.Info("Reconciling AndroidDeviceGroup autoscaling")
+	if err := r.autoscale(reqLogger, farm, group, instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: syncPeriod}, nil
+}
@@ -0,0 +1,126 @@
+package androiddevicegroup
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/rethinkdb"
+	stfutil "github.com/tinyzimmer/android-farm-operator/pkg/util/stf"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// autoscale reads the devices in use for the group from OpenSTF and adjusts the
+// replicas of the device group according to its autoscaling policy.
+func (r *ReconcileAndroidDeviceGroup) autoscale(reqLogger logr.Logger, farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, instance *androidv1alpha1.AndroidDeviceGroup) error {
+	inUse, err := r.getDevicesInUse(farm, group)
+	if err != nil {
+		return err
+	}
+
+	current := instance.DesiredReplicas(group)
+	var inUseCount int32
+	// the number of devices that can be removed without disrupting a session
+	floor := int32(0)
+	for idx := range inUse {
+		if idx < current {
+			inUseCount++
+		}
+		if idx+1 > floor {
+			floor = idx + 1
+		}
+	}
+
+	policy := group.Autoscaling
+	desired := policy.DesiredCount(inUseCount)
+	if desired < floor {
+		desired = floor
+	}
+	// never ask for more replicas than the group can be scaled to, or the
+	// group would be updated again after every cooldown
+	if max := group.GetMaxCount(); desired > max {
+		desired = max
+	}
+
+	var cooldown time.Duration
+	switch {
+	case desired > current:
+		cooldown = policy.GetScaleUpCooldown()
+	case desired < current:
+		cooldown = policy.GetScaleDownCooldown()
+	default:
+		return r.updateStatus(instance, inUseCount, nil)
+	}
+
+	lastScale := instance.GetCreationTimestamp()
+	if instance.Status.LastScaleTime != nil {
+		lastScale = *instance.Status.LastScaleTime
+	}
+	if time.Since(lastScale.Time) < cooldown {
+		reqLogger.Info("Device group is cooling down, not scaling", "Current", current, "Desired", desired, "InUse", inUseCount)
+		return r.updateStatus(instance, inUseCount, nil)
+	}
+
+	reqLogger.Info("Scaling device group", "Current", current, "Desired", desired, "InUse", inUseCount)
+	instance.Spec.Replicas = desired
+	if err := r.client.Update(context.TODO(), instance); err != nil {
+		return err
+	}
+	now := metav1.Now()
+	return r.updateStatus(instance, inUseCount, &now)
+}
+
+// getDevicesInUse returns the indices of the devices in the group that are
+// currently owned by a user in OpenSTF.
+func (r *ReconcileAndroidDeviceGroup) getDevicesInUse(farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) (map[int32]struct{}, error) {
+	session, err := rethinkdb.NewSession(strings.TrimPrefix(stfutil.RethinkDBProxyEndpoint(farm), "tcp://"))
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	serials, err := session.GetDevicesInUseForProvider(group.GetProviderName())
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]struct{})
+	for _, serial := range serials {
+		owned[serial] = struct{}{}
+	}
+
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := r.client.List(context.TODO(), devices, client.InNamespace(group.GetNamespace()), client.MatchingLabels{
+		androidv1alpha1.DeviceFarmLabel:  farm.Name,
+		androidv1alpha1.DeviceGroupLabel: group.Name,
+	}); err != nil {
+		return nil, err
+	}
+	inUse := make(map[int32]struct{})
+	for _, device := range devices.Items {
+		if _, ok := owned[device.Status.ADBSerial]; !ok || device.Status.ADBSerial == "" {
+			continue
+		}
+		idx, err := util.DeviceIndex(device.Name)
+		if err != nil {
+			return nil, err
+		}
+		inUse[idx] = struct{}{}
+	}
+	return inUse, nil
+}
+
+// updateStatus records the number of devices in use, and the time of the last
+// scale if provided, on the device group status.
+func (r *ReconcileAndroidDeviceGroup) updateStatus(instance *androidv1alpha1.AndroidDeviceGroup, inUse int32, scaleTime *metav1.Time) error {
+	if instance.Status.InUseReplicas == inUse && scaleTime == nil {
+		return nil
+	}
+	instance.Status.InUseReplicas = inUse
+	if scaleTime != nil {
+		instance.Status.LastScaleTime = scaleTime
+	}
+	return r.client.Status().Update(context.TODO(), instance)
+}
@@ -0,0 +1,156 @@
+package androiddevicelease
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_androiddevicelease")
+
+var leaseFinalizer = "finalizer.androiddeviceleases.android.stf.io"
+
+// pendingRequeue is how often pending leases look for a free device, in
+// addition to when devices in their namespace change.
+var pendingRequeue = time.Duration(10) * time.Second
+
+// Add creates a new AndroidDeviceLease Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileAndroidDeviceLease{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("androiddevicelease-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource AndroidDeviceLease
+	err = c.Watch(&source.Kind{Type: &androidv1alpha1.AndroidDeviceLease{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch devices and requeue the lease holding them, along with any leases
+	// in the same namespace that are waiting for a device.
+	err = c.Watch(
+		&source.Kind{Type: &androidv1alpha1.AndroidDevice{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				reqs, err := getAffectedLeases(mgr.GetClient(), a.Meta.GetNamespace(), a.Meta.GetAnnotations()[androidv1alpha1.LeaseAnnotation])
+				if err != nil {
+					fmt.Println("Error requeuing leases:", err)
+				}
+				return reqs
+			}),
+		})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getAffectedLeases returns the lease with the given name, if not empty, and all
+// pending leases in the given namespace.
+func getAffectedLeases(c client.Client, namespace, leaseName string) ([]reconcile.Request, error) {
+	reqs := make([]reconcile.Request, 0)
+	if leaseName != "" {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: leaseName, Namespace: namespace}})
+	}
+	leases := &androidv1alpha1.AndroidDeviceLeaseList{}
+	if err := c.List(context.TODO(), leases, client.InNamespace(namespace)); err != nil {
+		return reqs, err
+	}
+	for _, lease := range leases.Items {
+		if lease.Name == leaseName || lease.Status.Phase == androidv1alpha1.LeaseActive || lease.Status.Phase == androidv1alpha1.LeaseExpired {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: lease.NamespacedName()})
+	}
+	return reqs, nil
+}
+
+// blank assignment to verify that ReconcileAndroidDeviceLease implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileAndroidDeviceLease{}
+
+// ReconcileAndroidDeviceLease reconciles a AndroidDeviceLease object
+type ReconcileAndroidDeviceLease struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a AndroidDeviceLease object and makes changes based on the state read
+// and what is in the AndroidDeviceLease.Spec
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileAndroidDeviceLease) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling AndroidDeviceLease")
+
+	// Fetch the AndroidDeviceLease instance
+	instance := &androidv1alpha1.AndroidDeviceLease{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	// check if marked for deletion and release the device
+	if instance.GetDeletionTimestamp() != nil {
+		if !util.ContainsString(instance.GetFinalizers(), leaseFinalizer) {
+			return reconcile.Result{}, nil
+		}
+		if err := r.release(reqLogger, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+		instance.SetFinalizers(util.RemoveString(instance.GetFinalizers(), leaseFinalizer))
+		return reconcile.Result{}, r.client.Update(context.TODO(), instance)
+	}
+
+	switch instance.Status.Phase {
+	case androidv1alpha1.LeaseExpired:
+		return reconcile.Result{}, nil
+	case androidv1alpha1.LeaseActive:
+		return r.reconcileActive(reqLogger, instance)
+	}
+
+	// ensure finalizer for releasing the device before acquiring one
+	if !util.ContainsString(instance.GetFinalizers(), leaseFinalizer) {
+		instance.SetFinalizers(append(instance.GetFinalizers(), leaseFinalizer))
+		if err := r.client.Update(context.TODO(), instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return r.acquire(reqLogger, instance)
+}
@@ -0,0 +1,202 @@
+package androiddevicelease
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/rethinkdb"
+	stfutil "github.com/tinyzimmer/android-farm-operator/pkg/util/stf"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// acquire looks for a free, booted device matching the lease's selector and
+// claims it for the lease. Devices that were already marked for this lease are
+// picked up first, in case a previous attempt failed to record the status.
+func (r *ReconcileAndroidDeviceLease) acquire(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDeviceLease) (reconcile.Result, error) {
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := r.client.List(context.TODO(), devices, client.InNamespace(instance.Namespace), client.MatchingLabels(instance.Spec.DeviceSelector)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	candidates := make([]*androidv1alpha1.AndroidDevice, 0)
+	for i := range devices.Items {
+		device := &devices.Items[i]
+		if device.LeaseName() == instance.Name {
+			candidates = append([]*androidv1alpha1.AndroidDevice{device}, candidates...)
+			continue
+		}
+		if device.GetDeletionTimestamp() != nil || device.IsLeased() || !device.IsFarmedDevice() || !device.STFBound() || device.Status.ADBSerial == "" {
+			continue
+		}
+		// the serial of a physical device is its USB serial on the node it is
+		// attached to, so there is no endpoint to publish for it
+		if device.IsPhysicalDevice() {
+			continue
+		}
+		candidates = append(candidates, device)
+	}
+
+	for _, device := range candidates {
+		acquired, err := r.tryAcquire(reqLogger, instance, device)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !acquired {
+			continue
+		}
+		now := metav1.Now()
+		expires := metav1.NewTime(now.Add(instance.Duration()))
+		instance.Status = androidv1alpha1.AndroidDeviceLeaseStatus{
+			Phase:       androidv1alpha1.LeaseActive,
+			DeviceName:  device.Name,
+			ADBEndpoint: device.Status.ADBSerial,
+			AcquiredAt:  &now,
+			ExpiresAt:   &expires,
+		}
+		reqLogger.Info("Acquired device for lease", "Device.Name", device.Name, "ExpiresAt", expires)
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: instance.Duration()}, nil
+	}
+
+	reqLogger.Info("No free devices available for lease, waiting")
+	if err := r.setStatus(instance, androidv1alpha1.LeasePending, "Waiting for a free device matching the selector"); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: pendingRequeue}, nil
+}
+
+// tryAcquire marks the given device as held by the lease and claims it in
+// OpenSTF. If the device is already owned in OpenSTF, the mark is removed and
+// false is returned.
+func (r *ReconcileAndroidDeviceLease) tryAcquire(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDeviceLease, device *androidv1alpha1.AndroidDevice) (bool, error) {
+	if device.LeaseName() != instance.Name {
+		if device.Annotations == nil {
+			device.Annotations = make(map[string]string)
+		}
+		device.Annotations[androidv1alpha1.LeaseAnnotation] = instance.Name
+		if err := r.client.Update(context.TODO(), device); err != nil {
+			if kerrors.IsConflict(err) {
+				// the device was changed, possibly by another lease
+				return false, nil
+			}
+			return false, err
+		}
+	}
+
+	session, err := r.sessionForDevice(device)
+	if err != nil {
+		return false, err
+	}
+	defer session.Close()
+	claimed, err := session.ClaimDevice(device.Status.ADBSerial, leaseOwner(instance))
+	if err != nil {
+		return false, err
+	}
+	if claimed {
+		return true, nil
+	}
+
+	reqLogger.Info("Device is in use in OpenSTF, skipping", "Device.Name", device.Name)
+	delete(device.Annotations, androidv1alpha1.LeaseAnnotation)
+	return false, r.client.Update(context.TODO(), device)
+}
+
+// reconcileActive checks that an active lease still holds its device, and
+// releases it once the lease has expired.
+func (r *ReconcileAndroidDeviceLease) reconcileActive(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDeviceLease) (reconcile.Result, error) {
+	device := &androidv1alpha1.AndroidDevice{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Status.DeviceName, Namespace: instance.Namespace}, device); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+		reqLogger.Info("Leased device no longer exists, expiring lease", "Device.Name", instance.Status.DeviceName)
+		return reconcile.Result{}, r.setStatus(instance, androidv1alpha1.LeaseExpired, "The leased device no longer exists")
+	}
+
+	remaining := time.Until(instance.Status.ExpiresAt.Time)
+	if remaining > 0 && device.LeaseName() == instance.Name {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	reqLogger.Info("Lease has expired, releasing device", "Device.Name", instance.Status.DeviceName)
+	if err := r.release(reqLogger, instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, r.setStatus(instance, androidv1alpha1.LeaseExpired, "The lease has expired")
+}
+
+// release removes the lease's ownership of its device in OpenSTF and the mark
+// on the AndroidDevice.
+func (r *ReconcileAndroidDeviceLease) release(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDeviceLease) error {
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := r.client.List(context.TODO(), devices, client.InNamespace(instance.Namespace)); err != nil {
+		return err
+	}
+	for i := range devices.Items {
+		device := &devices.Items[i]
+		if device.LeaseName() != instance.Name {
+			continue
+		}
+		if device.IsFarmedDevice() && device.Status.ADBSerial != "" {
+			if err := r.releaseInSTF(device, instance); err != nil {
+				return err
+			}
+		}
+		reqLogger.Info("Releasing leased device", "Device.Name", device.Name)
+		delete(device.Annotations, androidv1alpha1.LeaseAnnotation)
+		if err := r.client.Update(context.TODO(), device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseInSTF removes the lease's ownership of the device in OpenSTF. If the
+// farm of the device no longer exists, there is nothing to release.
+func (r *ReconcileAndroidDeviceLease) releaseInSTF(device *androidv1alpha1.AndroidDevice, instance *androidv1alpha1.AndroidDeviceLease) error {
+	session, err := r.sessionForDevice(device)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	defer session.Close()
+	return session.ReleaseDevice(device.Status.ADBSerial, leaseOwner(instance))
+}
+
+// setStatus updates the phase and message of the lease if they have changed.
+func (r *ReconcileAndroidDeviceLease) setStatus(instance *androidv1alpha1.AndroidDeviceLease, phase androidv1alpha1.LeasePhase, message string) error {
+	if instance.Status.Phase == phase && instance.Status.Message == message {
+		return nil
+	}
+	instance.Status.Phase = phase
+	instance.Status.Message = message
+	return r.client.Status().Update(context.TODO(), instance)
+}
+
+// sessionForDevice returns a session to the RethinkDB instance of the farm the
+// given device belongs to.
+func (r *ReconcileAndroidDeviceLease) sessionForDevice(device *androidv1alpha1.AndroidDevice) (rethinkdb.RethinkDBSession, error) {
+	farm, err := device.GetFarm(r.client)
+	if err != nil {
+		return nil, err
+	}
+	return rethinkdb.NewSession(strings.TrimPrefix(stfutil.RethinkDBProxyEndpoint(farm), "tcp://"))
+}
+
+// leaseOwner returns the owner to claim devices in OpenSTF with for a lease.
+// Claims are identified by the UID of the lease.
+func leaseOwner(instance *androidv1alpha1.AndroidDeviceLease) *rethinkdb.DeviceOwner {
+	return &rethinkdb.DeviceOwner{
+		Email: instance.GetOwnerEmail(),
+		Name:  instance.GetOwnerName(),
+		Lease: string(instance.GetUID()),
+	}
+}
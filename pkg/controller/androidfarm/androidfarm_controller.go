@@ -3,26 +3,33 @@ package androidfarm
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/go-logr/logr"
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/metrics"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources/emulators"
+	"github.com/tinyzimmer/android-farm-operator/pkg/resources/physical"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources/rethinkdb"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources/stf"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/events"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -39,7 +46,11 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileAndroidFarm{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileAndroidFarm{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: events.NewRateLimitedRecorder(mgr.GetEventRecorderFor("androidfarm-controller"), events.DefaultInterval),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -65,6 +76,15 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch device groups and requeue the parent farm
+	err = c.Watch(&source.Kind{Type: &androidv1alpha1.AndroidDeviceGroup{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &androidv1alpha1.AndroidFarm{},
+	})
+	if err != nil {
+		return err
+	}
+
 	// Watch configmaps and requeue the parent farm
 	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
@@ -117,9 +137,56 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch nodes joining or leaving and requeue farms with USB node selectors.
+	// Only label changes are of interest, not status updates.
+	err = c.Watch(
+		&source.Kind{Type: &corev1.Node{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				reqs, err := getUSBNodeSelectorFarms(mgr.GetClient())
+				if err != nil {
+					fmt.Println("Error requeuing farms:", err)
+				}
+				return reqs
+			}),
+		},
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return !reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// getUSBNodeSelectorFarms lists all farms, and returns the ones with a device
+// group running providers on the nodes matching a selector.
+func getUSBNodeSelectorFarms(c client.Client) ([]reconcile.Request, error) {
+	reqs := make([]reconcile.Request, 0)
+	farms := &androidv1alpha1.AndroidFarmList{}
+	if err := c.List(context.TODO(), farms, client.InNamespace(metav1.NamespaceAll)); err != nil {
+		return reqs, err
+	}
+	for _, farm := range farms.Items {
+		for _, group := range farm.DeviceGroups() {
+			if group.UsesUSBNodeSelector() {
+				reqs = append(reqs, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      farm.Name,
+						Namespace: farm.Namespace,
+					},
+				})
+				break
+			}
+		}
+	}
+	return reqs, nil
+}
+
 // getAffectedFarms lists all farms, and returns the ones that reference
 // the given device config.
 func getAffectedFarms(c client.Client, config string) ([]reconcile.Request, error) {
@@ -152,8 +219,9 @@ var _ reconcile.Reconciler = &ReconcileAndroidFarm{}
 type ReconcileAndroidFarm struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a AndroidFarm object and makes changes based on the state read
@@ -188,14 +256,23 @@ func (r *ReconcileAndroidFarm) Reconcile(request reconcile.Request) (reconcile.R
 	reconcilers := []resources.FarmReconciler{
 		rethinkdb.New(r.client, r.scheme),
 		stf.New(r.client, r.scheme),
-		emulators.NewForFarm(r.client, r.scheme),
+		emulators.NewForFarm(r.client, r.scheme, r.recorder),
+		physical.NewForFarm(r.client, r.scheme, r.recorder),
 	}
 
-	// run each reconciler
-	for _, r := range reconcilers {
-		if err := r.Reconcile(reqLogger, instance); err != nil {
+	// run each reconciler, updating the status after each one
+	for idx, reconciler := range reconcilers {
+		err := reconciler.Reconcile(reqLogger, instance)
+		if statusErr := r.updateStatus(reqLogger, instance, err, err == nil && idx == len(reconcilers)-1); statusErr != nil {
+			reqLogger.Error(statusErr, "Failed to update AndroidFarm status")
+			if err == nil {
+				return reconcile.Result{}, statusErr
+			}
+		}
+		if err != nil {
 			if requeue, ok := errors.IsRequeueError(err); ok {
 				reqLogger.Info(err.Error())
+				metrics.RecordRequeue("androidfarm-controller", requeue)
 				return reconcile.Result{
 					Requeue:      true,
 					RequeueAfter: requeue.Duration(),
@@ -206,13 +283,18 @@ func (r *ReconcileAndroidFarm) Reconcile(request reconcile.Request) (reconcile.R
 	}
 
 	// ensure finalizer for cleanup
-	if !contains(instance.GetFinalizers(), farmFinalizer) {
+	if !util.ContainsString(instance.GetFinalizers(), farmFinalizer) {
 		instance.SetFinalizers(append(instance.GetFinalizers(), farmFinalizer))
 		if err := r.client.Update(context.TODO(), instance); err != nil {
 			return reconcile.Result{}, err
 		}
 	}
 
+	// poll the providers of host USB device groups for attached devices
+	if physical.HasInventory(instance) {
+		return reconcile.Result{RequeueAfter: physical.InventoryInterval}, nil
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -234,29 +316,11 @@ func (r *ReconcileAndroidFarm) runFinalizers(reqLogger logr.Logger, instance *an
 	}
 
 	// remove the finalizer
-	instance.SetFinalizers(remove(instance.GetFinalizers(), farmFinalizer))
+	metrics.ForgetFarm(instance)
+	instance.SetFinalizers(util.RemoveString(instance.GetFinalizers(), farmFinalizer))
 	if err := r.client.Update(context.TODO(), instance); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	return reconcile.Result{}, nil
 }
-
-func contains(ss []string, s string) bool {
-	for _, x := range ss {
-		if x == s {
-			return true
-		}
-	}
-	return false
-}
-
-func remove(in []string, rm string) []string {
-	out := make([]string, 0)
-	for _, x := range in {
-		if x != rm {
-			out = append(out, x)
-		}
-	}
-	return out
-}
@@ -0,0 +1,447 @@
+package androidfarm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateStatus observes the state of the resources managed for an AndroidFarm
+// and writes it to the status subresource if it has changed. If reconcileErr
+// is a requeue error, its message is used as the reason on the first condition
+// that is not yet true. When complete is true, the observed generation is bumped
+// to the generation of the instance.
+func (r *ReconcileAndroidFarm) updateStatus(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, reconcileErr error, complete bool) error {
+	status := instance.Status.DeepCopy()
+
+	conditions := make([]androidv1alpha1.Condition, 0)
+	observeFuncs := []func(*androidv1alpha1.AndroidFarm) ([]androidv1alpha1.Condition, error){
+		r.observeRethinkDB,
+		r.observeSTFDeployments,
+		func(farm *androidv1alpha1.AndroidFarm) ([]androidv1alpha1.Condition, error) {
+			groups, cond, err := r.observeDeviceGroups(farm)
+			status.DeviceGroups = groups
+			return []androidv1alpha1.Condition{cond}, err
+		},
+		func(farm *androidv1alpha1.AndroidFarm) ([]androidv1alpha1.Condition, error) {
+			nodes, err := r.observeNodes(farm)
+			status.Nodes = nodes
+			return nil, err
+		},
+	}
+	for _, observe := range observeFuncs {
+		conds, err := observe(instance)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, conds...)
+	}
+
+	// attach the reason we are waiting to the first condition blocking the farm
+	if requeue, ok := errors.IsRequeueError(reconcileErr); ok {
+		for i := range conditions {
+			if !conditions[i].IsTrue() {
+				conditions[i].Reason = requeue.Reason()
+				conditions[i].Message = requeue.Error()
+				break
+			}
+		}
+	}
+
+	conditions = append(conditions, readyCondition(conditions, reconcileErr))
+
+	// set the new conditions, preserving transition times, and drop any that are
+	// no longer observed (e.g. removed device groups).
+	observed := make(map[androidv1alpha1.ConditionType]struct{})
+	for _, cond := range conditions {
+		status.SetCondition(cond)
+		observed[cond.Type] = struct{}{}
+	}
+	for _, cond := range instance.Status.Conditions {
+		if _, ok := observed[cond.Type]; !ok {
+			status.RemoveCondition(cond.Type)
+		}
+	}
+
+	if complete {
+		status.ObservedGeneration = instance.GetGeneration()
+	}
+
+	if reflect.DeepEqual(*status, instance.Status) {
+		return nil
+	}
+
+	if cond := status.GetCondition(androidv1alpha1.RethinkDBMigrated); cond.IsTrue() && !instance.Status.GetCondition(androidv1alpha1.RethinkDBMigrated).IsTrue() {
+		r.recorder.Event(instance, corev1.EventTypeNormal, "MigrationCompleted", cond.Message)
+	}
+
+	reqLogger.Info("Updating AndroidFarm status")
+	instance.Status = *status
+	return r.client.Status().Update(context.TODO(), instance)
+}
+
+// readyCondition returns the overall ready condition for the farm given the
+// conditions of its components.
+func readyCondition(conds []androidv1alpha1.Condition, reconcileErr error) androidv1alpha1.Condition {
+	if reconcileErr != nil {
+		if _, ok := errors.IsRequeueError(reconcileErr); !ok {
+			return androidv1alpha1.NewCondition(androidv1alpha1.FarmReady, corev1.ConditionFalse, "ReconcileError", reconcileErr.Error())
+		}
+	}
+	for _, cond := range conds {
+		if !cond.IsTrue() {
+			return androidv1alpha1.NewCondition(
+				androidv1alpha1.FarmReady, corev1.ConditionFalse, cond.Reason,
+				fmt.Sprintf("Waiting for %s", cond.Type),
+			)
+		}
+	}
+	return androidv1alpha1.NewCondition(androidv1alpha1.FarmReady, corev1.ConditionTrue, "Ready", "All components are ready")
+}
+
+// observeRethinkDB returns conditions for the rethinkdb cluster, proxy, and
+// migration job of the farm.
+func (r *ReconcileAndroidFarm) observeRethinkDB(instance *androidv1alpha1.AndroidFarm) ([]androidv1alpha1.Condition, error) {
+	if instance.STFDisabled() {
+		return nil, nil
+	}
+	namespace := instance.STFConfig().GetNamespace()
+
+	rdbCond, err := r.observeStatefulSet(
+		types.NamespacedName{Name: instance.RethinkDBName(), Namespace: namespace},
+		androidv1alpha1.RethinkDBReady,
+		*instance.STFConfig().RethinkDBReplicas(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyCond, err := r.observeStatefulSet(
+		types.NamespacedName{Name: instance.RethinkDBProxyName(), Namespace: namespace},
+		androidv1alpha1.RethinkDBProxyReady,
+		*instance.STFConfig().RethinkDBProxyReplicas(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	migrateCond := androidv1alpha1.NewCondition(androidv1alpha1.RethinkDBMigrated, corev1.ConditionFalse, "NotCreated", "The migration job has not been created")
+	job := &batchv1.Job{}
+	nn := types.NamespacedName{Name: fmt.Sprintf("%s-migrate", instance.RethinkDBName()), Namespace: namespace}
+	if err := r.client.Get(context.TODO(), nn, job); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+	} else if job.Status.Succeeded > 0 {
+		migrateCond = androidv1alpha1.NewCondition(androidv1alpha1.RethinkDBMigrated, corev1.ConditionTrue, "Completed", "The migration job has completed")
+	} else {
+		migrateCond = androidv1alpha1.NewCondition(androidv1alpha1.RethinkDBMigrated, corev1.ConditionFalse, "Running", "The migration job has not completed")
+	}
+
+	return []androidv1alpha1.Condition{rdbCond, proxyCond, migrateCond}, nil
+}
+
+// observeStatefulSet returns a condition of the given type reflecting the
+// number of ready replicas in a statefulset.
+func (r *ReconcileAndroidFarm) observeStatefulSet(nn types.NamespacedName, ctype androidv1alpha1.ConditionType, desired int32) (androidv1alpha1.Condition, error) {
+	ss := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), nn, ss); err != nil {
+		if kerrors.IsNotFound(err) {
+			return androidv1alpha1.NewCondition(ctype, corev1.ConditionFalse, "NotCreated", fmt.Sprintf("StatefulSet %s has not been created", nn.Name)), nil
+		}
+		return androidv1alpha1.Condition{}, err
+	}
+	msg := fmt.Sprintf("%d/%d replicas ready", ss.Status.ReadyReplicas, desired)
+	if ss.Status.ReadyReplicas != desired {
+		return androidv1alpha1.NewCondition(ctype, corev1.ConditionFalse, "Progressing", msg), nil
+	}
+	return androidv1alpha1.NewCondition(ctype, corev1.ConditionTrue, "Ready", msg), nil
+}
+
+// observeSTFDeployments returns an availability condition for each OpenSTF
+// deployment belonging to the farm.
+func (r *ReconcileAndroidFarm) observeSTFDeployments(instance *androidv1alpha1.AndroidFarm) ([]androidv1alpha1.Condition, error) {
+	if instance.STFDisabled() {
+		return nil, nil
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.client.List(
+		context.TODO(),
+		deployments,
+		client.InNamespace(instance.STFConfig().GetNamespace()),
+		client.MatchingLabels{androidv1alpha1.DeviceFarmLabel: instance.GetName(), "app": "stf"},
+	); err != nil {
+		return nil, err
+	}
+	// sort by creation time so conditions follow the order deployments are reconciled
+	sort.Slice(deployments.Items, func(i, j int) bool {
+		ti, tj := deployments.Items[i].CreationTimestamp, deployments.Items[j].CreationTimestamp
+		if ti.Equal(&tj) {
+			return deployments.Items[i].Name < deployments.Items[j].Name
+		}
+		return ti.Before(&tj)
+	})
+
+	conds := make([]androidv1alpha1.Condition, 0)
+	var traefikCond *androidv1alpha1.Condition
+	for _, deployment := range deployments.Items {
+		component := deployment.GetLabels()["component"]
+		ctype := androidv1alpha1.STFComponentConditionType(component)
+		if component == "traefik" {
+			ctype = androidv1alpha1.TraefikReady
+		}
+		var desired int32 = 1
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		msg := fmt.Sprintf("%d/%d replicas available", deployment.Status.AvailableReplicas, desired)
+		cond := androidv1alpha1.NewCondition(ctype, corev1.ConditionTrue, "Available", msg)
+		if deployment.Status.ObservedGeneration < deployment.GetGeneration() || deployment.Status.AvailableReplicas < desired {
+			cond = androidv1alpha1.NewCondition(ctype, corev1.ConditionFalse, "Progressing", msg)
+		}
+		if component == "traefik" {
+			traefikCond = &cond
+			continue
+		}
+		conds = append(conds, cond)
+	}
+
+	// traefik is always reconciled last
+	if traefikCond == nil {
+		cond := androidv1alpha1.NewCondition(androidv1alpha1.TraefikReady, corev1.ConditionFalse, "NotCreated", "The traefik deployment has not been created")
+		traefikCond = &cond
+	}
+	return append(conds, *traefikCond), nil
+}
+
+// observeDeviceGroups returns the status of each emulated device group in the
+// farm along with a condition reflecting whether they are all booted. The status
+// of the AndroidDeviceGroup for each group is updated along the way.
+func (r *ReconcileAndroidFarm) observeDeviceGroups(instance *androidv1alpha1.AndroidFarm) ([]androidv1alpha1.DeviceGroupStatus, androidv1alpha1.Condition, error) {
+	statuses := make([]androidv1alpha1.DeviceGroupStatus, 0)
+	ready := true
+	for _, group := range instance.DeviceGroups() {
+		if !group.IsEmulatedGroup() {
+			continue
+		}
+		devices := &androidv1alpha1.AndroidDeviceList{}
+		if err := r.client.List(
+			context.TODO(),
+			devices,
+			client.InNamespace(group.GetNamespace()),
+			client.MatchingLabels{
+				androidv1alpha1.DeviceFarmLabel:  instance.GetName(),
+				androidv1alpha1.DeviceGroupLabel: group.Name,
+			},
+		); err != nil {
+			return nil, androidv1alpha1.Condition{}, err
+		}
+		deviceGroup := &androidv1alpha1.AndroidDeviceGroup{}
+		nn := types.NamespacedName{Name: instance.DeviceGroupName(group), Namespace: group.GetNamespace()}
+		if err := r.client.Get(context.TODO(), nn, deviceGroup); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, androidv1alpha1.Condition{}, err
+			}
+			deviceGroup = nil
+		}
+		groupStatus := androidv1alpha1.DeviceGroupStatus{
+			Name:    group.Name,
+			Desired: group.GetCount(),
+			Created: int32(len(devices.Items)),
+		}
+		if deviceGroup != nil {
+			groupStatus.Desired = deviceGroup.DesiredReplicas(group)
+		}
+		checksum, err := r.groupChecksum(group)
+		if err != nil {
+			return nil, androidv1alpha1.Condition{}, err
+		}
+		// whether every device that is allowed to be updated is running the
+		// current configuration
+		policy := instance.GetDeviceManagementPolicy(group.Name)
+		unheldUpdated := true
+		for _, device := range devices.Items {
+			if device.BootCompleted() {
+				groupStatus.Booted++
+			}
+			if device.STFBound() {
+				groupStatus.STFBound++
+			}
+			if checksum == "" {
+				continue
+			}
+			if device.ConfigChecksum() == checksum {
+				groupStatus.Updated++
+				continue
+			}
+			if idx, err := util.DeviceIndex(device.Name); err == nil && (policy == nil || !policy.HoldsUpdate(idx)) {
+				unheldUpdated = false
+			}
+		}
+		if checksum != "" {
+			groupStatus.Rollout = rolloutPhase(groupStatus, policy, unheldUpdated)
+		}
+		if deviceGroup != nil {
+			if err := r.updateDeviceGroupStatus(deviceGroup, groupStatus); err != nil {
+				return nil, androidv1alpha1.Condition{}, err
+			}
+		}
+		if groupStatus.Booted < groupStatus.Desired {
+			ready = false
+		}
+		statuses = append(statuses, groupStatus)
+	}
+
+	if !ready {
+		return statuses, androidv1alpha1.NewCondition(androidv1alpha1.DevicesReady, corev1.ConditionFalse, "Progressing", "Not all device groups have their desired devices booted"), nil
+	}
+	return statuses, androidv1alpha1.NewCondition(androidv1alpha1.DevicesReady, corev1.ConditionTrue, "Ready", "All device groups have their desired devices booted"), nil
+}
+
+// observeNodes returns the status of each node labeled as KVM capable by the
+// discovery daemon, along with the number of emulators in the farm running on
+// it and how many it could hold.
+func (r *ReconcileAndroidFarm) observeNodes(instance *androidv1alpha1.AndroidFarm) ([]androidv1alpha1.NodeStatus, error) {
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes, client.MatchingLabels{androidv1alpha1.KVMNodeLabel: "true"}); err != nil {
+		return nil, err
+	}
+	if len(nodes.Items) == 0 {
+		return nil, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.MatchingLabels{androidv1alpha1.DeviceFarmLabel: instance.GetName()}); err != nil {
+		return nil, err
+	}
+	devicesPerNode := make(map[string]int32)
+	for _, pod := range pods.Items {
+		// only count emulators, the farm label is on the OpenSTF pods as well
+		if _, ok := pod.GetLabels()[androidv1alpha1.DeviceGroupLabel]; !ok || pod.Spec.NodeName == "" {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		devicesPerNode[pod.Spec.NodeName]++
+	}
+
+	demand, err := r.emulatorDemand(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]androidv1alpha1.NodeStatus, 0)
+	for _, node := range nodes.Items {
+		statuses = append(statuses, androidv1alpha1.NodeStatus{
+			Name:                 node.GetName(),
+			NestedVirtualization: node.GetLabels()[androidv1alpha1.NestedVirtualizationNodeLabel] == "true",
+			Devices:              devicesPerNode[node.GetName()],
+			Capacity:             nodeCapacity(node.Status.Allocatable, demand),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// emulatorDemand returns the largest cpu and memory requests of the emulators
+// in the farm's device groups. Limits are used for configurations that do not
+// declare requests.
+func (r *ReconcileAndroidFarm) emulatorDemand(instance *androidv1alpha1.AndroidFarm) (corev1.ResourceList, error) {
+	demand := corev1.ResourceList{}
+	for _, group := range instance.DeviceGroups() {
+		if !group.IsEmulatedGroup() {
+			continue
+		}
+		config, err := group.GetConfig(r.client)
+		if err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			quantity, ok := config.Spec.Resources.Requests[name]
+			if !ok {
+				quantity, ok = config.Spec.Resources.Limits[name]
+			}
+			if !ok || quantity.IsZero() {
+				continue
+			}
+			if current, ok := demand[name]; !ok || quantity.Cmp(current) > 0 {
+				demand[name] = quantity
+			}
+		}
+	}
+	return demand, nil
+}
+
+// nodeCapacity returns how many emulators with the given demand fit in the
+// allocatable resources of a node, or zero if there is no demand.
+func nodeCapacity(allocatable, demand corev1.ResourceList) int32 {
+	var capacity int64 = -1
+	for name, quantity := range demand {
+		available := allocatable[name]
+		fits := available.MilliValue() / quantity.MilliValue()
+		if capacity < 0 || fits < capacity {
+			capacity = fits
+		}
+	}
+	if capacity < 0 {
+		return 0
+	}
+	return int32(capacity)
+}
+
+// groupChecksum returns the checksum of the current configuration of a device
+// group, or an empty string if the configuration it references does not exist.
+func (r *ReconcileAndroidFarm) groupChecksum(group *androidv1alpha1.DeviceGroup) (string, error) {
+	config, err := group.GetConfig(r.client)
+	if err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return config.Checksum()
+}
+
+// rolloutPhase returns the state of the rollout of the current configuration
+// of a device group.
+func rolloutPhase(groupStatus androidv1alpha1.DeviceGroupStatus, policy *androidv1alpha1.DeviceManagementPolicy, unheldUpdated bool) androidv1alpha1.RolloutPhase {
+	switch {
+	case groupStatus.Updated == groupStatus.Created:
+		return androidv1alpha1.RolloutComplete
+	case policy != nil && policy.Paused:
+		return androidv1alpha1.RolloutPaused
+	case policy != nil && policy.Partition > 0 && unheldUpdated:
+		return androidv1alpha1.RolloutPartitioned
+	default:
+		return androidv1alpha1.RolloutProgressing
+	}
+}
+
+// updateDeviceGroupStatus writes the observed state of a device group to the
+// status of its AndroidDeviceGroup if it has changed.
+func (r *ReconcileAndroidFarm) updateDeviceGroupStatus(deviceGroup *androidv1alpha1.AndroidDeviceGroup, groupStatus androidv1alpha1.DeviceGroupStatus) error {
+	status := *deviceGroup.Status.DeepCopy()
+	status.Replicas = groupStatus.Created
+	status.ReadyReplicas = groupStatus.Booted
+	status.Selector = labels.SelectorFromSet(deviceGroup.GetLabels()).String()
+	if reflect.DeepEqual(status, deviceGroup.Status) {
+		return nil
+	}
+	deviceGroup.Status = status
+	return r.client.Status().Update(context.TODO(), deviceGroup)
+}
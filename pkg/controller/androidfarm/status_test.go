@@ -0,0 +1,60 @@
+package androidfarm
+
+import (
+	"testing"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+)
+
+func TestRolloutPhase(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        androidv1alpha1.DeviceGroupStatus
+		policy        *androidv1alpha1.DeviceManagementPolicy
+		unheldUpdated bool
+		want          androidv1alpha1.RolloutPhase
+	}{
+		{
+			name:   "all devices updated",
+			status: androidv1alpha1.DeviceGroupStatus{Created: 3, Updated: 3},
+			want:   androidv1alpha1.RolloutComplete,
+		},
+		{
+			name:   "all devices updated while paused",
+			status: androidv1alpha1.DeviceGroupStatus{Created: 3, Updated: 3},
+			policy: &androidv1alpha1.DeviceManagementPolicy{Paused: true},
+			want:   androidv1alpha1.RolloutComplete,
+		},
+		{
+			name:   "no policy",
+			status: androidv1alpha1.DeviceGroupStatus{Created: 3, Updated: 1},
+			want:   androidv1alpha1.RolloutProgressing,
+		},
+		{
+			name:   "paused",
+			status: androidv1alpha1.DeviceGroupStatus{Created: 3, Updated: 1},
+			policy: &androidv1alpha1.DeviceManagementPolicy{Paused: true, Partition: 1},
+			want:   androidv1alpha1.RolloutPaused,
+		},
+		{
+			name:          "devices above the partition updated",
+			status:        androidv1alpha1.DeviceGroupStatus{Created: 3, Updated: 2},
+			policy:        &androidv1alpha1.DeviceManagementPolicy{Partition: 1},
+			unheldUpdated: true,
+			want:          androidv1alpha1.RolloutPartitioned,
+		},
+		{
+			name:   "devices above the partition updating",
+			status: androidv1alpha1.DeviceGroupStatus{Created: 3, Updated: 1},
+			policy: &androidv1alpha1.DeviceManagementPolicy{Partition: 1},
+			want:   androidv1alpha1.RolloutProgressing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rolloutPhase(tt.status, tt.policy, tt.unheldUpdated); got != tt.want {
+				t.Errorf("rolloutPhase() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package androidjob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultDownloadRetries is the number of attempts made to download an APK
+	// when an install activity does not specify one.
+	defaultDownloadRetries = 3
+	// defaultWaitTimeout is the number of seconds to wait for a condition when a
+	// wait activity does not specify one.
+	defaultWaitTimeout = 300
+	// defaultWaitInterval is the number of seconds between checks of a wait
+	// condition when it does not specify one.
+	defaultWaitInterval = 5
+)
+
+// httpClient is used for downloading APKs
+var httpClient = &http.Client{Timeout: time.Duration(5) * time.Minute}
+
+// runAction runs a single action from a job template against a device. As
+// with runCommandActivity, a returned status with a non-empty Status means the
+// action failed and the job should stop, while an error means the action
+// should be retried. The output of any commands run is collected in out. Waits
+// in the action end early with the context's error when it is done.
+func runAction(ctx context.Context, reqLogger logr.Logger, sess android.DeviceSession, instance *androidv1alpha1.AndroidJob, device corev1.Pod, action androidv1alpha1.Action, out *stepOutput) (androidv1alpha1.DeviceJobStatus, error) {
+	switch action.Activity {
+	case androidv1alpha1.CommandActivity:
+		return runCommandActivity(sess, instance, device, action, out)
+	case androidv1alpha1.InstallActivity:
+		return runInstallActivity(ctx, reqLogger, sess, device, action)
+	case androidv1alpha1.WaitActivity:
+		return runWaitActivity(ctx, reqLogger, sess, device, action, out)
+	case androidv1alpha1.InteractActivity:
+		return runInteractActivity(sess, action)
+	default:
+		return androidv1alpha1.DeviceJobStatus{
+			Status:  androidv1alpha1.StatusFailed,
+			Message: fmt.Sprintf("Unknown activity: %s", action.Activity),
+		}, nil
+	}
+}
+
+// commandTimeout returns the timeout for each command run by an action.
+func commandTimeout(action androidv1alpha1.Action) time.Duration {
+	if action.TimeoutSeconds > 0 {
+		return time.Duration(action.TimeoutSeconds) * time.Second
+	}
+	return android.DefaultCommandTimeout
+}
+
+// runInstallActivity downloads the APK for an action and installs it on the
+// device.
+func runInstallActivity(ctx context.Context, reqLogger logr.Logger, sess android.DeviceSession, device corev1.Pod, action androidv1alpha1.Action) (androidv1alpha1.DeviceJobStatus, error) {
+	path, err := downloadAPKWithRetries(ctx, reqLogger, action)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return androidv1alpha1.DeviceJobStatus{}, ctxErr
+		}
+		return androidv1alpha1.DeviceJobStatus{
+			Status:  androidv1alpha1.StatusFailed,
+			Message: err.Error(),
+		}, nil
+	}
+	defer os.Remove(path)
+
+	if err := sess.InstallAPK(path, time.Duration(action.TimeoutSeconds)*time.Second); err != nil {
+		if strings.Contains(err.Error(), "Failure") {
+			// the package manager rejected the APK, retrying will not help
+			return androidv1alpha1.DeviceJobStatus{
+				Status:  androidv1alpha1.StatusFailed,
+				Message: err.Error(),
+			}, nil
+		}
+		return androidv1alpha1.DeviceJobStatus{}, fmt.Errorf("%s: %s", device.Name, err.Error())
+	}
+	return androidv1alpha1.DeviceJobStatus{
+		Message: "Installed the APK",
+	}, nil
+}
+
+// downloadAPKWithRetries downloads the APK for an action to a temporary file,
+// retrying up to the number of times configured on the action. The path to the
+// file is returned and should be removed by the caller. The rendered URL may
+// contain secrets, so it is not logged or included in errors.
+func downloadAPKWithRetries(ctx context.Context, reqLogger logr.Logger, action androidv1alpha1.Action) (string, error) {
+	retries := action.Retries
+	if retries == 0 {
+		retries = defaultDownloadRetries
+	}
+	var err error
+	for attempt := 1; attempt <= retries; attempt++ {
+		var path string
+		if path, err = downloadAPK(action.APKUrl, action.APKChecksum); err == nil {
+			return path, nil
+		}
+		reqLogger.Info("Failed to download APK", "Attempt", attempt, "Error", err.Error())
+		if attempt < retries {
+			if err := sleepContext(ctx, time.Duration(attempt*2)*time.Second); err != nil {
+				return "", err
+			}
+		}
+	}
+	return "", fmt.Errorf("Failed to download the APK after %d attempts: %s", retries, err.Error())
+}
+
+// downloadAPK downloads the APK at the given URL to a temporary file and
+// verifies it against the checksum, if one is given. Returned errors do not
+// include the URL.
+func downloadAPK(apkURL, checksum string) (string, error) {
+	resp, err := httpClient.Get(apkURL)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			return "", urlErr.Err
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Unexpected response from server: %s", resp.Status)
+	}
+
+	f, err := ioutil.TempFile("", "androidjob-*.apk")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if checksum != "" {
+		if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, checksum) {
+			os.Remove(f.Name())
+			return "", fmt.Errorf("Checksum mismatch, expected %s, got %s", checksum, sum)
+		}
+	}
+	return f.Name(), nil
+}
+
+// runWaitActivity sleeps for the seconds in the action, or waits for its
+// condition to be met. The output of the last check of the condition is
+// collected in out.
+func runWaitActivity(ctx context.Context, reqLogger logr.Logger, sess android.DeviceSession, device corev1.Pod, action androidv1alpha1.Action, out *stepOutput) (androidv1alpha1.DeviceJobStatus, error) {
+	if action.Condition == nil {
+		if err := sleepContext(ctx, time.Duration(action.Seconds)*time.Second); err != nil {
+			return androidv1alpha1.DeviceJobStatus{}, err
+		}
+		return androidv1alpha1.DeviceJobStatus{
+			Message: fmt.Sprintf("Waited %d seconds", action.Seconds),
+		}, nil
+	}
+
+	condition := action.Condition
+
+	timeout := action.Seconds
+	if timeout == 0 {
+		timeout = defaultWaitTimeout
+	}
+	interval := condition.IntervalSeconds
+	if interval == 0 {
+		interval = defaultWaitInterval
+	}
+
+	start := time.Now()
+	deadline := start.Add(time.Duration(timeout) * time.Second)
+	for {
+		met, err := conditionMet(sess, commandTimeout(action), condition, out)
+		if err != nil {
+			return androidv1alpha1.DeviceJobStatus{}, fmt.Errorf("%s: %s", device.Name, err.Error())
+		}
+		if met {
+			return androidv1alpha1.DeviceJobStatus{
+				Message: fmt.Sprintf("Condition was met after %s", time.Since(start).Round(time.Second)),
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return androidv1alpha1.DeviceJobStatus{
+				Status:  androidv1alpha1.StatusFailed,
+				Message: fmt.Sprintf("Condition was not met within %d seconds", timeout),
+			}, nil
+		}
+		reqLogger.Info("Condition not yet met, waiting", "DeviceName", device.Name, "Interval", interval)
+		if err := sleepContext(ctx, time.Duration(interval)*time.Second); err != nil {
+			return androidv1alpha1.DeviceJobStatus{}, err
+		}
+	}
+}
+
+// sleepContext sleeps for the given duration, or until the context is done, in
+// which case the context's error is returned.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// conditionMet returns true if the command and text of a wait condition are
+// both satisfied on the device. A command that exits non-zero is treated as
+// not satisfied, while a command that could not be run is an error.
+func conditionMet(sess android.DeviceSession, timeout time.Duration, condition *androidv1alpha1.WaitCondition, out *stepOutput) (bool, error) {
+	if condition.Command != "" {
+		out.reset()
+		result, err := sess.RunCommandWithResult(timeout, false, condition.Command)
+		if err != nil {
+			return false, err
+		}
+		out.record(result)
+		if result.ExitCode != 0 {
+			return false, nil
+		}
+		if condition.Output != "" && !strings.Contains(string(result.Stdout), condition.Output) {
+			return false, nil
+		}
+	}
+	if condition.Text != "" {
+		return sess.ScreenContains(condition.Text, condition.Invert)
+	}
+	return true, nil
+}
+
+// runInteractActivity sends the interactions in an action to the device in
+// order. Targets that cannot be found on the screen fail the action.
+func runInteractActivity(sess android.DeviceSession, action androidv1alpha1.Action) (androidv1alpha1.DeviceJobStatus, error) {
+	for idx, interaction := range action.Interactions {
+		if err := runInteraction(sess, interaction); err != nil {
+			return androidv1alpha1.DeviceJobStatus{
+				Status:  androidv1alpha1.StatusFailed,
+				Message: fmt.Sprintf("Interaction %d (%s) failed: %s", idx, interaction.Type, err.Error()),
+			}, nil
+		}
+	}
+	return androidv1alpha1.DeviceJobStatus{
+		Message: fmt.Sprintf("Sent %d interactions", len(action.Interactions)),
+	}, nil
+}
+
+// runInteraction sends a single interaction to the device.
+func runInteraction(sess android.DeviceSession, interaction androidv1alpha1.Interaction) error {
+	if interaction.Target != "" {
+		if err := sess.TapAtString(&android.TapOptions{
+			String: interaction.Target,
+			Scroll: interaction.Scroll,
+			Invert: interaction.Invert,
+		}); err != nil {
+			return err
+		}
+	}
+	switch interaction.Type {
+	case androidv1alpha1.TypeAction:
+		return sess.InputText(interaction.Input)
+	case androidv1alpha1.ClickAction:
+		return nil
+	default:
+		return fmt.Errorf("Unknown interaction type: %s", interaction.Type)
+	}
+}
@@ -1,20 +1,21 @@
 package androidjob
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"html/template"
-	"sync"
+	"strings"
 	"time"
 
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/android"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/events"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -34,7 +35,13 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileAndroidJob{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	recorder := events.NewRateLimitedRecorder(mgr.GetEventRecorderFor("androidjob-controller"), events.DefaultInterval)
+	return &ReconcileAndroidJob{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: recorder,
+		executor: newJobExecutor(mgr.GetClient(), mgr.GetAPIReader(), recorder, MaxConcurrentDeviceJobs),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -64,14 +71,11 @@ var _ reconcile.Reconciler = &ReconcileAndroidJob{}
 type ReconcileAndroidJob struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
-}
-
-// status is used to feed back status updates when running jobs in parallel
-type status struct {
-	name   string
-	status androidv1alpha1.DeviceJobStatus
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	// executor runs the jobs on devices in the background
+	executor *jobExecutor
 }
 
 // Reconcile reads that state of the cluster for a AndroidJob object and makes changes based on the state read
@@ -90,7 +94,8 @@ func (r *ReconcileAndroidJob) Reconcile(request reconcile.Request) (reconcile.Re
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
-			// Return and don't requeue
+			// Stop any runs of the job that are still in progress.
+			r.executor.forget(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -116,94 +121,132 @@ func (r *ReconcileAndroidJob) Reconcile(request reconcile.Request) (reconcile.Re
 		instance.Status.JobStatus = make(map[string]androidv1alpha1.DeviceJobStatus)
 	}
 
-	// setup channels for running jobs concurrently
-	errChan := make(chan error)
-	statusChan := make(chan status)
-	var wg sync.WaitGroup
-
-	// run the jobs
-	for _, device := range targetDevices {
-		wg.Add(1)
-		reqLogger.Info("Starting job worker for device", "DeviceName", device.Name)
-		go runJobWorker(reqLogger, instance, device, jobTemplate, statusChan, errChan, &wg)
+	// fail the devices that did not finish before the deadline
+	deadline := instance.Deadline()
+	if !deadline.IsZero() && time.Now().After(deadline) {
+		reqLogger.Info("Job is past its active deadline, failing unfinished devices")
+		r.executor.cancel(request.NamespacedName)
+		for _, device := range targetDevices {
+			failDeviceAtDeadline(r.recorder, instance, device.Name)
+		}
+		instance.Status.Summary = instance.Status.Summarize(deviceNames(targetDevices))
+		return reconcile.Result{}, r.client.Status().Update(context.TODO(), instance)
 	}
 
-	// wait and close the channels
-	go func() {
-		wg.Wait()
-		reqLogger.Info("Jobs are complete, closing channels")
-		close(statusChan)
-		close(errChan)
-	}()
-
-	// retrieve results from the channels
-	instance, errOcurred := watchJobChannels(reqLogger, instance, statusChan, errChan)
+	// keep the summary up to date with the statuses written by the executor
+	if summary := instance.Status.Summarize(deviceNames(targetDevices)); summary != instance.Status.Summary {
+		r.executor.setSummary(reqLogger, instance, deviceNames(targetDevices))
+	}
 
-	// push status updates
-	reqLogger.Info("Publishing status updates for job")
-	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
-		return reconcile.Result{}, err
+	// queue the job on the devices that are not finished, running, or backing off.
+	// progress is written to the status by the executor, which triggers another
+	// reconcile.
+	now := time.Now()
+	var unfinished bool
+	for _, device := range targetDevices {
+		jobStatus, ok := instance.Status.JobStatus[device.Name]
+		if ok && jobStatus.Finished() {
+			continue
+		}
+		unfinished = true
+		if r.executor.isRunning(instance, device.Name) || (ok && retryAfter(jobStatus, now) > 0) {
+			continue
+		}
+		reqLogger.Info("Queueing job for device", "DeviceName", device.Name)
+		r.executor.submit(reqLogger, instance, jobTemplate, device)
 	}
 
-	// determine if any errors happened (we need to requeue)
-	if errOcurred {
-		reqLogger.Info("One or more errors ocurred while processing the job, requeing until completion")
-		return reconcile.Result{
-			Requeue:      true,
-			RequeueAfter: time.Duration(3) * time.Second,
-		}, nil
+	// requeue for the next retry of a device that is backing off, or the
+	// deadline if it comes first. While runs are in progress, check back
+	// periodically, since the reconcile triggered by the final status of a run
+	// may still see it running.
+	requeueAfter, ok := nextRetry(r.executor, instance, targetDevices, now)
+	if r.executor.hasRunning(instance) && (!ok || runningRequeueInterval < requeueAfter) {
+		requeueAfter, ok = runningRequeueInterval, true
+	}
+	if !deadline.IsZero() && unfinished && (!ok || time.Until(deadline) < requeueAfter) {
+		return reconcile.Result{RequeueAfter: time.Until(deadline)}, nil
+	}
+	if ok {
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	return reconcile.Result{}, nil
 }
 
-func watchJobChannels(reqLogger logr.Logger, instance *androidv1alpha1.AndroidJob, statusChan chan status, errChan chan error) (*androidv1alpha1.AndroidJob, bool) {
-	var errOcurred bool
-	for {
-		select {
-		case jobStatus, ok := <-statusChan:
-			if ok && jobStatus.name != "" {
-				reqLogger.Info("Received status update for job", "DeviceName", jobStatus.name, "Status", jobStatus.status)
-				instance.Status.JobStatus[jobStatus.name] = jobStatus.status
-			}
-			if !ok {
-				// status channel has closed
-				statusChan = nil
-			}
-		case err, ok := <-errChan:
-			if ok {
-				reqLogger.Error(err, "Received error on jobs channel")
-				errOcurred = true
-			}
-			if !ok {
-				// error channel has closed
-				errChan = nil
-			}
-		}
-		if statusChan == nil && errChan == nil {
-			break
+// runningRequeueInterval is how often a job is reconciled while it is running
+// on any device.
+var runningRequeueInterval = time.Duration(10) * time.Second
+
+// maxRetryBackoff is the longest time to wait before retrying a job on a device.
+var maxRetryBackoff = time.Duration(6) * time.Minute
+
+// retryBackoff returns how long to wait before running a job on a device
+// again after the given number of attempts. Like Kubernetes Jobs, the backoff
+// starts at 10 seconds and doubles up to six minutes.
+func retryBackoff(attempts int) time.Duration {
+	backoff := time.Duration(10) * time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxRetryBackoff {
+			return maxRetryBackoff
 		}
 	}
-	return instance, errOcurred
+	return backoff
+}
+
+// retryAfter returns how long until the job may be retried on a device with
+// the given status.
+func retryAfter(jobStatus androidv1alpha1.DeviceJobStatus, now time.Time) time.Duration {
+	if jobStatus.Attempts == 0 || jobStatus.LastAttemptTime == nil {
+		return 0
+	}
+	return jobStatus.LastAttemptTime.Add(retryBackoff(jobStatus.Attempts)).Sub(now)
 }
 
-func runJobWorker(reqLogger logr.Logger, instance *androidv1alpha1.AndroidJob, device corev1.Pod, jobTemplate *androidv1alpha1.AndroidJobTemplate, statusChan chan status, errChan chan error, wg *sync.WaitGroup) {
-	defer wg.Done()
-	// check if job has already been run
-	if status, ok := instance.Status.JobStatus[device.Name]; ok {
-		if status.Status == androidv1alpha1.StatusComplete || status.Status == androidv1alpha1.StatusFailed {
-			return
+// nextRetry returns the time until the next retry of the job on a device that
+// is backing off, and false if no devices are.
+func nextRetry(executor *jobExecutor, instance *androidv1alpha1.AndroidJob, devices []corev1.Pod, now time.Time) (time.Duration, bool) {
+	var next time.Duration
+	var pending bool
+	for _, device := range devices {
+		jobStatus, ok := instance.Status.JobStatus[device.Name]
+		if !ok || jobStatus.Finished() || executor.isRunning(instance, device.Name) {
+			continue
 		}
+		wait := retryAfter(jobStatus, now)
+		if wait <= 0 {
+			continue
+		}
+		if !pending || wait < next {
+			next = wait
+		}
+		pending = true
 	}
+	return next, pending
+}
 
-	// run the jobs
-	jobStatus, err := runDeviceJobs(reqLogger, instance, device, jobTemplate)
-	if err != nil {
-		errChan <- err
+// failDeviceAtDeadline marks the job failed on a device that has not finished
+// it before its active deadline.
+func failDeviceAtDeadline(recorder record.EventRecorder, instance *androidv1alpha1.AndroidJob, deviceName string) {
+	jobStatus := instance.Status.JobStatus[deviceName]
+	if jobStatus.Finished() {
+		return
 	}
-	if jobStatus.Status != "" {
-		statusChan <- status{name: device.Name, status: jobStatus}
+	jobStatus.Status = androidv1alpha1.StatusFailed
+	jobStatus.Reason = androidv1alpha1.ReasonDeadlineExceeded
+	jobStatus.Message = fmt.Sprintf("The job did not finish within %d seconds", *instance.Spec.ActiveDeadlineSeconds)
+	instance.Status.JobStatus[deviceName] = jobStatus
+	recorder.Eventf(instance, corev1.EventTypeWarning, "DeviceJobFailed", "%s: %s", deviceName, jobStatus.Message)
+}
+
+// deviceNames returns the names of the given devices.
+func deviceNames(devices []corev1.Pod) []string {
+	names := make([]string, len(devices))
+	for idx, device := range devices {
+		names[idx] = device.Name
 	}
+	return names
 }
 
 func getTargetDevices(c client.Client, instance *androidv1alpha1.AndroidJob) ([]corev1.Pod, error) {
@@ -227,16 +270,26 @@ func getTargetDevices(c client.Client, instance *androidv1alpha1.AndroidJob) ([]
 	return targetDevices, nil
 }
 
-func runDeviceJobs(reqLogger logr.Logger, instance *androidv1alpha1.AndroidJob, device corev1.Pod, jobTemplate *androidv1alpha1.AndroidJobTemplate) (androidv1alpha1.DeviceJobStatus, error) {
+// runDeviceJobs connects to a device and runs the actions of a job template
+// against it. The progress function is called with the status of the job
+// after each action.
+func runDeviceJobs(ctx context.Context, reqLogger logr.Logger, c client.Client, instance *androidv1alpha1.AndroidJob, device corev1.Pod, jobTemplate *androidv1alpha1.AndroidJobTemplate, progress func(androidv1alpha1.DeviceJobStatus)) (androidv1alpha1.DeviceJobStatus, error) {
 	// lookup the adb port for the device
 	adbPort, err := util.GetPodADBPort(device)
 	if err != nil {
 		return androidv1alpha1.DeviceJobStatus{
 			Status:  androidv1alpha1.StatusFailed,
+			Reason:  androidv1alpha1.ReasonDeviceUnreachable,
 			Message: "Could not determine ADB port for device",
 		}, nil
 	}
 
+	// resolve the parameters for templates before connecting to the device
+	data, jobStatus, err := newTemplateData(c, instance, jobTemplate, device)
+	if err != nil || jobStatus.Status != "" {
+		return jobStatus, err
+	}
+
 	// connect to the device and run the activities
 	sess, err := android.NewSession(reqLogger, device.Status.PodIP, adbPort)
 	if err != nil {
@@ -244,50 +297,125 @@ func runDeviceJobs(reqLogger logr.Logger, instance *androidv1alpha1.AndroidJob,
 	}
 	defer sess.Close()
 
-	for _, job := range jobTemplate.Spec.Actions {
-		if job.Activity == androidv1alpha1.CommandActivity {
-			status, err := runCommandActivity(sess, instance, device, job)
-			if err != nil {
-				return androidv1alpha1.DeviceJobStatus{}, err
-			}
-			if status.Status != "" {
-				return status, nil
-			}
+	collector := newArtifactCollector(reqLogger, c, instance, device, jobTemplate.Spec.Artifacts, sess)
+	outputs := newOutputStore(reqLogger, c, instance, device, collector)
+	jobStatus, err = runActions(ctx, reqLogger, sess, collector, outputs, data, instance, device, jobTemplate, progress)
+	jobStatus.Artifacts = collector.finish()
+	return jobStatus, err
+}
+
+// runActions runs the actions of a job template against a device in order,
+// stopping at the first that fails or ends in an error, or when the context is
+// cancelled.
+func runActions(ctx context.Context, reqLogger logr.Logger, sess android.DeviceSession, collector *artifactCollector, outputs *outputStore, data *templateData, instance *androidv1alpha1.AndroidJob, device corev1.Pod, jobTemplate *androidv1alpha1.AndroidJobTemplate, progress func(androidv1alpha1.DeviceJobStatus)) (androidv1alpha1.DeviceJobStatus, error) {
+	jobStatus := androidv1alpha1.DeviceJobStatus{
+		Steps: make([]androidv1alpha1.JobStepStatus, 0),
+	}
+	deadline := instance.Deadline()
+	for idx, action := range jobTemplate.Spec.Actions {
+		step := androidv1alpha1.JobStepStatus{
+			Name:     stepName(idx, action),
+			Activity: action.Activity,
+		}
+		if err := ctx.Err(); err != nil {
+			return jobStatus, err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			jobStatus.Status = androidv1alpha1.StatusFailed
+			jobStatus.Reason = androidv1alpha1.ReasonDeadlineExceeded
+			jobStatus.Message = fmt.Sprintf("The job passed its active deadline before step %s", step.Name)
+			return jobStatus, nil
+		}
+		step.StartTime = &metav1.Time{Time: time.Now()}
+		rendered, err := renderAction(data, action)
+		if err != nil {
+			step.CompletionTime = &metav1.Time{Time: time.Now()}
+			step.Status = androidv1alpha1.StatusFailed
+			step.Error = fmt.Sprintf("Failed to render action: %s", err.Error())
+			jobStatus.Steps = append(jobStatus.Steps, step)
+			jobStatus.Status = androidv1alpha1.StatusFailed
+			jobStatus.Reason = androidv1alpha1.ReasonTemplateError
+			jobStatus.Message = fmt.Sprintf("Step %s failed: %s", step.Name, step.Error)
+			return jobStatus, nil
+		}
+		reqLogger.Info("Running job step", "DeviceName", device.Name, "Step", step.Name)
+		out := &stepOutput{}
+		// waits in the action end at the active deadline of the job
+		actionCtx, cancel := ctx, context.CancelFunc(func() {})
+		if !deadline.IsZero() {
+			actionCtx, cancel = context.WithDeadline(ctx, deadline)
+		}
+		result, err := runAction(actionCtx, reqLogger, sess, instance, device, rendered, out)
+		cancel()
+		step.CompletionTime = &metav1.Time{Time: time.Now()}
+		outputs.save(&step, out)
+		if err != nil && ctx.Err() == nil && actionCtx.Err() == context.DeadlineExceeded {
+			step.Status = androidv1alpha1.StatusFailed
+			step.Error = "The job passed its active deadline"
+			collector.afterStep(step)
+			jobStatus.Steps = append(jobStatus.Steps, step)
+			jobStatus.Status = androidv1alpha1.StatusFailed
+			jobStatus.Reason = androidv1alpha1.ReasonDeadlineExceeded
+			jobStatus.Message = fmt.Sprintf("The job passed its active deadline during step %s", step.Name)
+			return jobStatus, nil
+		}
+		if err != nil {
+			step.Status = androidv1alpha1.StatusError
+			step.Error = err.Error()
+			collector.afterStep(step)
+			jobStatus.Steps = append(jobStatus.Steps, step)
+			jobStatus.Status = androidv1alpha1.StatusPending
+			jobStatus.Message = fmt.Sprintf("Step %s ended in an error, retrying", step.Name)
+			return jobStatus, err
+		}
+		if result.Status != "" {
+			step.Status = result.Status
+			step.Error = result.Message
+			collector.afterStep(step)
+			jobStatus.Steps = append(jobStatus.Steps, step)
+			jobStatus.Status = result.Status
+			jobStatus.Reason = androidv1alpha1.ReasonStepFailed
+			jobStatus.Message = fmt.Sprintf("Step %s failed: %s", step.Name, result.Message)
+			return jobStatus, nil
+		}
+		step.Status = androidv1alpha1.StatusComplete
+		step.Message = result.Message
+		collector.afterStep(step)
+		jobStatus.Steps = append(jobStatus.Steps, step)
+		if idx < len(jobTemplate.Spec.Actions)-1 {
+			jobStatus.Status = androidv1alpha1.StatusRunning
+			jobStatus.Message = fmt.Sprintf("Completed step %s", step.Name)
+			progress(jobStatus)
 		}
 	}
 
-	return androidv1alpha1.DeviceJobStatus{
-		Status:  androidv1alpha1.StatusComplete,
-		Message: "The job completed successfully",
-	}, nil
+	jobStatus.Status = androidv1alpha1.StatusComplete
+	jobStatus.Message = "The job completed successfully"
+	return jobStatus, nil
+}
 
+// stepName returns the name to report the result of an action under. Actions
+// are named by the webhook, but templates created without it may not be.
+func stepName(idx int, action androidv1alpha1.Action) string {
+	if action.Name != "" {
+		return action.Name
+	}
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(action.Activity)), idx)
 }
 
-func runCommandActivity(sess android.DeviceSession, instance *androidv1alpha1.AndroidJob, device corev1.Pod, job androidv1alpha1.Action) (androidv1alpha1.DeviceJobStatus, error) {
-	for _, cmd := range job.Commands {
-		tmplCmd, err := templateCommand(device, cmd)
+// runCommandActivity runs the commands of an action on the device in order. A
+// command that exits non-zero ends the action in an error.
+func runCommandActivity(sess android.DeviceSession, instance *androidv1alpha1.AndroidJob, device corev1.Pod, job androidv1alpha1.Action, out *stepOutput) (androidv1alpha1.DeviceJobStatus, error) {
+	for idx, cmd := range job.Commands {
+		result, err := sess.RunCommandWithResult(commandTimeout(job), job.RunAsRoot, cmd)
 		if err != nil {
-			err = fmt.Errorf("Failed to template command: %s", err.Error())
-			return androidv1alpha1.DeviceJobStatus{
-				Status:  androidv1alpha1.StatusFailed,
-				Message: err.Error(),
-			}, nil
-		}
-		if _, err = sess.RunCommand(job.RunAsRoot, tmplCmd); err != nil {
 			return androidv1alpha1.DeviceJobStatus{}, fmt.Errorf("%s: %s", device.Name, err.Error())
 		}
+		out.record(result)
+		if result.ExitCode != 0 {
+			// the rendered command is left out as it may contain secrets
+			return androidv1alpha1.DeviceJobStatus{}, fmt.Errorf("%s: command %d exited with code %d", device.Name, idx, result.ExitCode)
+		}
 	}
 	return androidv1alpha1.DeviceJobStatus{}, nil
 }
-
-func templateCommand(pod corev1.Pod, cmd string) (string, error) {
-	tmpl, err := template.New(pod.Name).Parse(cmd)
-	if err != nil {
-		return "", err
-	}
-	var out bytes.Buffer
-	if err := tmpl.Execute(&out, pod); err != nil {
-		return "", err
-	}
-	return out.String(), nil
-}
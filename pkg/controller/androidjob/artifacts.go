@@ -0,0 +1,120 @@
+package androidjob
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/artifacts"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// artifactCollector collects the artifacts configured on a job template from
+// a device and stores them in the configured sink. Failing to collect or store
+// an artifact is logged and does not affect the result of the job. A nil
+// collector collects nothing.
+type artifactCollector struct {
+	reqLogger   logr.Logger
+	config      *androidv1alpha1.ArtifactsConfig
+	sink        artifacts.Sink
+	sess        android.DeviceSession
+	prefix      string
+	logcatSince string
+	artifacts   []androidv1alpha1.JobArtifact
+}
+
+// newArtifactCollector returns a collector for the given configuration, or nil
+// if there is nothing to collect or the sink could not be set up. It should
+// be created before the job starts so the logcat covers the whole job.
+func newArtifactCollector(reqLogger logr.Logger, c client.Client, instance *androidv1alpha1.AndroidJob, device corev1.Pod, config *androidv1alpha1.ArtifactsConfig, sess android.DeviceSession) *artifactCollector {
+	if config == nil {
+		return nil
+	}
+	sink, err := artifacts.NewSink(c, instance.GetNamespace(), device.GetLabels()[androidv1alpha1.DeviceFarmLabel], &config.Sink)
+	if err != nil {
+		reqLogger.Error(err, "Failed to setup artifact sink, no artifacts will be collected", "DeviceName", device.Name)
+		return nil
+	}
+	collector := &artifactCollector{
+		reqLogger: reqLogger.WithValues("DeviceName", device.Name),
+		config:    config,
+		sink:      sink,
+		sess:      sess,
+		prefix:    path.Join(instance.GetNamespace(), instance.GetName(), device.Name),
+		artifacts: make([]androidv1alpha1.JobArtifact, 0),
+	}
+	if config.Logcat {
+		out, err := sess.RunCommand(false, "date +'%m-%d %H:%M:%S.000'")
+		if err != nil {
+			collector.reqLogger.Error(err, "Failed to read the device clock, logcat will not be collected")
+		} else {
+			collector.logcatSince = strings.TrimSpace(string(out))
+		}
+	}
+	return collector
+}
+
+// afterStep takes a screenshot after a step if the screenshot policy asks for
+// one.
+func (a *artifactCollector) afterStep(step androidv1alpha1.JobStepStatus) {
+	if a == nil {
+		return
+	}
+	switch a.config.Screenshots {
+	case androidv1alpha1.ScreenshotsEachAction:
+	case androidv1alpha1.ScreenshotsOnFailure:
+		if step.Status != androidv1alpha1.StatusFailed && step.Status != androidv1alpha1.StatusError {
+			return
+		}
+	default:
+		return
+	}
+	screen, err := a.sess.GetScreencapPNG()
+	if err != nil {
+		a.reqLogger.Error(err, "Failed to take screenshot", "Step", step.Name)
+		return
+	}
+	a.store(fmt.Sprintf("screenshot-%s.png", step.Name), screen)
+}
+
+// finish collects the logcat and files from the device and returns all the
+// artifacts that were stored.
+func (a *artifactCollector) finish() []androidv1alpha1.JobArtifact {
+	if a == nil {
+		return nil
+	}
+	if a.logcatSince != "" {
+		var out bytes.Buffer
+		if err := a.sess.DumpLogcat(a.logcatSince, &out); err != nil {
+			a.reqLogger.Error(err, "Failed to dump logcat")
+		} else {
+			a.store("logcat.txt", out.Bytes())
+		}
+	}
+	for _, p := range a.config.Paths {
+		var out bytes.Buffer
+		if err := a.sess.DownloadFile(p, &out); err != nil {
+			a.reqLogger.Error(err, "Failed to pull file from device", "Path", p)
+			continue
+		}
+		a.store(path.Join("files", path.Clean("/"+p)), out.Bytes())
+	}
+	return a.artifacts
+}
+
+// store stores an artifact and records its URL. The URL is returned, or an
+// empty string if the artifact could not be stored.
+func (a *artifactCollector) store(name string, data []byte) string {
+	url, err := a.sink.Store(path.Join(a.prefix, name), data)
+	if err != nil {
+		a.reqLogger.Error(err, "Failed to store artifact", "Artifact", name)
+		return ""
+	}
+	a.artifacts = append(a.artifacts, androidv1alpha1.JobArtifact{Name: name, URL: url})
+	return url
+}
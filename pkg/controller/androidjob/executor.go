@@ -0,0 +1,300 @@
+package androidjob
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxConcurrentDeviceJobs is the number of devices that jobs can run on at
+// the same time across all jobs.
+var MaxConcurrentDeviceJobs = 10
+
+// taskKey identifies the run of a job on a single device.
+type taskKey struct {
+	job    types.NamespacedName
+	device string
+}
+
+// deviceTask is the run of a job on a single device.
+type deviceTask struct {
+	key         taskKey
+	ctx         context.Context
+	instance    *androidv1alpha1.AndroidJob
+	jobTemplate *androidv1alpha1.AndroidJobTemplate
+	device      corev1.Pod
+}
+
+// jobExecutor runs jobs on devices in the background, so the reconciler does
+// not block on them. At most workers devices are run at a time, and progress
+// and results are written to the status of the job as they happen.
+type jobExecutor struct {
+	client   client.Client
+	reader   client.Reader
+	recorder record.EventRecorder
+	workers  chan struct{}
+
+	mux     sync.Mutex
+	running map[taskKey]context.CancelFunc
+	// statusMuxes serialize the status writes of each job
+	statusMuxes map[types.NamespacedName]*sync.Mutex
+}
+
+// newJobExecutor returns an executor that runs jobs on up to the given number
+// of devices at a time. Statuses are read with the given reader, which should
+// not be cached, before they are updated.
+func newJobExecutor(c client.Client, reader client.Reader, recorder record.EventRecorder, workers int) *jobExecutor {
+	if workers < 1 {
+		workers = 1
+	}
+	return &jobExecutor{
+		client:      c,
+		reader:      reader,
+		recorder:    recorder,
+		workers:     make(chan struct{}, workers),
+		running:     make(map[taskKey]context.CancelFunc),
+		statusMuxes: make(map[types.NamespacedName]*sync.Mutex),
+	}
+}
+
+// hasRunning returns true if the job is queued or running on any device.
+func (e *jobExecutor) hasRunning(instance *androidv1alpha1.AndroidJob) bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	job := namespacedName(instance)
+	for key := range e.running {
+		if key.job == job {
+			return true
+		}
+	}
+	return false
+}
+
+// isRunning returns true if the job is queued or running on the device.
+func (e *jobExecutor) isRunning(instance *androidv1alpha1.AndroidJob, deviceName string) bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	_, ok := e.running[taskKey{job: namespacedName(instance), device: deviceName}]
+	return ok
+}
+
+// submit queues the job to run on the device. It returns immediately, the
+// run waits for a free worker in the background.
+func (e *jobExecutor) submit(reqLogger logr.Logger, instance *androidv1alpha1.AndroidJob, jobTemplate *androidv1alpha1.AndroidJobTemplate, device corev1.Pod) {
+	key := taskKey{job: namespacedName(instance), device: device.Name}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e.mux.Lock()
+	if _, ok := e.running[key]; ok {
+		e.mux.Unlock()
+		cancel()
+		return
+	}
+	e.running[key] = cancel
+	e.mux.Unlock()
+
+	task := &deviceTask{
+		key:         key,
+		ctx:         ctx,
+		instance:    instance.DeepCopy(),
+		jobTemplate: jobTemplate.DeepCopy(),
+		device:      device,
+	}
+	go e.run(reqLogger.WithValues("DeviceName", device.Name), task)
+}
+
+// cancel stops the runs of a job that are queued or running. Running tasks
+// stop before their next action.
+func (e *jobExecutor) cancel(job types.NamespacedName) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	for key, cancel := range e.running {
+		if key.job == job {
+			cancel()
+		}
+	}
+}
+
+// forget cancels the runs of a job that was deleted and drops its status lock.
+func (e *jobExecutor) forget(job types.NamespacedName) {
+	e.cancel(job)
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	delete(e.statusMuxes, job)
+}
+
+// statusMux returns the lock serializing the status writes of a job.
+func (e *jobExecutor) statusMux(job types.NamespacedName) *sync.Mutex {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	mux, ok := e.statusMuxes[job]
+	if !ok {
+		mux = &sync.Mutex{}
+		e.statusMuxes[job] = mux
+	}
+	return mux
+}
+
+// done removes a task from the running tasks.
+func (e *jobExecutor) done(task *deviceTask) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if cancel, ok := e.running[task.key]; ok {
+		cancel()
+		delete(e.running, task.key)
+	}
+}
+
+// run waits for a free worker and runs a task, writing its progress and result
+// to the status of the job.
+func (e *jobExecutor) run(reqLogger logr.Logger, task *deviceTask) {
+	defer e.done(task)
+
+	select {
+	case e.workers <- struct{}{}:
+		defer func() { <-e.workers }()
+	case <-task.ctx.Done():
+		return
+	}
+
+	// the job may have been submitted from a stale cache, so check the latest
+	// status before running it on the device again
+	instance := &androidv1alpha1.AndroidJob{}
+	if err := e.reader.Get(context.TODO(), task.key.job, instance); err != nil {
+		if !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to read job before running it on device")
+		}
+		return
+	}
+	if instance.GetUID() != task.instance.GetUID() {
+		return
+	}
+	device := task.device
+	previous := instance.Status.JobStatus[device.Name]
+	if previous.Finished() || previous.Attempts > task.instance.Status.JobStatus[device.Name].Attempts {
+		reqLogger.Info("Job already ran on device since it was queued, skipping")
+		return
+	}
+	task.instance = instance
+	start := time.Now()
+	attempt := func(jobStatus androidv1alpha1.DeviceJobStatus) androidv1alpha1.DeviceJobStatus {
+		jobStatus.Attempts = previous.Attempts + 1
+		jobStatus.LastAttemptTime = &metav1.Time{Time: start}
+		return jobStatus
+	}
+
+	reqLogger.Info("Running job on device")
+	e.setStatus(reqLogger, task, attempt(androidv1alpha1.DeviceJobStatus{
+		Status:  androidv1alpha1.StatusRunning,
+		Message: "The job is running",
+	}))
+	progress := func(jobStatus androidv1alpha1.DeviceJobStatus) {
+		e.setStatus(reqLogger, task, attempt(jobStatus))
+	}
+
+	jobStatus, err := runDeviceJobs(task.ctx, reqLogger, e.client, instance, device, task.jobTemplate, progress)
+	if task.ctx.Err() != nil {
+		// the job was deleted or passed its deadline
+		reqLogger.Info("Job was cancelled on device")
+		return
+	}
+	jobStatus = attempt(jobStatus)
+	if err != nil {
+		reqLogger.Error(err, "Error running job on device")
+		metrics.ObserveJobResult(instance, metrics.JobResultError, time.Since(start))
+		if jobStatus.Status == "" {
+			jobStatus.Status = androidv1alpha1.StatusPending
+			jobStatus.Message = err.Error()
+		}
+		if jobStatus.Attempts > instance.GetBackoffLimit() {
+			jobStatus.Status = androidv1alpha1.StatusFailed
+			jobStatus.Reason = androidv1alpha1.ReasonBackoffLimitExceeded
+			jobStatus.Message = fmt.Sprintf("The job ended in an error %d times, the last was: %s", jobStatus.Attempts, err.Error())
+			e.recorder.Eventf(instance, corev1.EventTypeWarning, "DeviceJobFailed", "%s: %s", device.Name, jobStatus.Message)
+		} else {
+			e.recorder.Eventf(instance, corev1.EventTypeWarning, "DeviceJobError", "Error running the job on %s, retrying in %s: %s", device.Name, retryBackoff(jobStatus.Attempts), err.Error())
+		}
+		e.setStatus(reqLogger, task, jobStatus)
+		return
+	}
+
+	metrics.ObserveJobResult(instance, string(jobStatus.Status), time.Since(start))
+	switch jobStatus.Status {
+	case androidv1alpha1.StatusComplete:
+		e.recorder.Eventf(instance, corev1.EventTypeNormal, "DeviceJobCompleted", "%s: %s", device.Name, jobStatus.Message)
+	case androidv1alpha1.StatusFailed:
+		e.recorder.Eventf(instance, corev1.EventTypeWarning, "DeviceJobFailed", "%s: %s", device.Name, jobStatus.Message)
+	}
+	e.setStatus(reqLogger, task, jobStatus)
+}
+
+// setStatus writes the status of a task to its job. Statuses that were
+// already finished by the reconciler, like at the deadline of the job, are
+// left alone.
+func (e *jobExecutor) setStatus(reqLogger logr.Logger, task *deviceTask, jobStatus androidv1alpha1.DeviceJobStatus) {
+	e.updateStatus(reqLogger, task.instance, func(status *androidv1alpha1.AndroidJobStatus) bool {
+		if status.JobStatus == nil {
+			status.JobStatus = make(map[string]androidv1alpha1.DeviceJobStatus)
+		}
+		if status.JobStatus[task.key.device].Finished() {
+			return false
+		}
+		status.JobStatus[task.key.device] = jobStatus
+		return true
+	})
+}
+
+// setSummary writes the summary of the status of a job on the given devices.
+func (e *jobExecutor) setSummary(reqLogger logr.Logger, instance *androidv1alpha1.AndroidJob, deviceNames []string) {
+	e.updateStatus(reqLogger, instance, func(status *androidv1alpha1.AndroidJobStatus) bool {
+		summary := status.Summarize(deviceNames)
+		if summary == status.Summary {
+			return false
+		}
+		status.Summary = summary
+		return true
+	})
+}
+
+// updateStatus reads the latest status of a job, applies the mutate function
+// to it, and writes it back if the function returns true. Jobs that were
+// deleted or recreated are left alone.
+func (e *jobExecutor) updateStatus(reqLogger logr.Logger, job *androidv1alpha1.AndroidJob, mutate func(*androidv1alpha1.AndroidJobStatus) bool) {
+	// serialize writes so runs of the same job do not conflict with each other
+	mux := e.statusMux(namespacedName(job))
+	mux.Lock()
+	defer mux.Unlock()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &androidv1alpha1.AndroidJob{}
+		if err := e.reader.Get(context.TODO(), namespacedName(job), instance); err != nil {
+			return err
+		}
+		if instance.GetUID() != job.GetUID() {
+			return nil
+		}
+		if !mutate(&instance.Status) {
+			return nil
+		}
+		return e.client.Status().Update(context.TODO(), instance)
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Failed to update job status")
+	}
+}
+
+// namespacedName returns the namespaced name of a job.
+func namespacedName(instance *androidv1alpha1.AndroidJob) types.NamespacedName {
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
@@ -0,0 +1,168 @@
+package androidjob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/adb"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxStatusOutput is the number of bytes of stdout and stderr kept in the
+	// status of a step.
+	maxStatusOutput = 1024
+	// maxConfigMapOutput is the number of bytes of stdout and stderr kept in
+	// the output ConfigMap for a step.
+	maxConfigMapOutput = 128 * 1024
+	// maxConfigMapTotal is the number of bytes of output kept in the output
+	// ConfigMap for all steps, leaving room for its keys and metadata under the
+	// 1MiB limit on objects.
+	maxConfigMapTotal = 896 * 1024
+)
+
+// configMapKeyRegex matches the characters that are not allowed in ConfigMap
+// keys.
+var configMapKeyRegex = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// stepOutput collects the output of the commands run by an action.
+type stepOutput struct {
+	stdout, stderr bytes.Buffer
+	exitCode       *int
+}
+
+// record appends the output of a command and keeps its exit code.
+func (o *stepOutput) record(result *adb.Result) {
+	o.stdout.Write(result.Stdout)
+	o.stderr.Write(result.Stderr)
+	exitCode := result.ExitCode
+	o.exitCode = &exitCode
+}
+
+// reset discards the output collected so far.
+func (o *stepOutput) reset() {
+	o.stdout.Reset()
+	o.stderr.Reset()
+	o.exitCode = nil
+}
+
+// outputStore writes the output of steps to their status, and stores the full
+// output when it is too large for it. Full outputs go to the artifact sink if
+// the job template configures one, otherwise to a ConfigMap owned by the job.
+type outputStore struct {
+	reqLogger logr.Logger
+	client    client.Client
+	instance  *androidv1alpha1.AndroidJob
+	collector *artifactCollector
+	configMap types.NamespacedName
+	data      map[string]string
+	size      int
+}
+
+// newOutputStore returns an output store for the run of a job on a device.
+func newOutputStore(reqLogger logr.Logger, c client.Client, instance *androidv1alpha1.AndroidJob, device corev1.Pod, collector *artifactCollector) *outputStore {
+	return &outputStore{
+		reqLogger: reqLogger.WithValues("DeviceName", device.Name),
+		client:    c,
+		instance:  instance,
+		collector: collector,
+		configMap: types.NamespacedName{
+			Name:      fmt.Sprintf("%s-%s-output", instance.GetName(), device.Name),
+			Namespace: instance.GetNamespace(),
+		},
+		data: make(map[string]string),
+	}
+}
+
+// save sets the exit code and truncated output of a step, and stores the full
+// output if it was truncated. Failing to store the full output is reported in
+// the status of the step and does not affect its result.
+func (o *outputStore) save(step *androidv1alpha1.JobStepStatus, out *stepOutput) {
+	step.ExitCode = out.exitCode
+	step.Stdout = truncateOutput(out.stdout.Bytes(), maxStatusOutput)
+	step.Stderr = truncateOutput(out.stderr.Bytes(), maxStatusOutput)
+	if out.stdout.Len() <= maxStatusOutput && out.stderr.Len() <= maxStatusOutput {
+		return
+	}
+
+	if o.collector != nil {
+		ref := &androidv1alpha1.StepOutputRef{}
+		if out.stdout.Len() > 0 {
+			ref.StdoutURL = o.collector.store(fmt.Sprintf("output/%s.stdout", step.Name), out.stdout.Bytes())
+		}
+		if out.stderr.Len() > 0 {
+			ref.StderrURL = o.collector.store(fmt.Sprintf("output/%s.stderr", step.Name), out.stderr.Bytes())
+		}
+		if ref.StdoutURL != "" || ref.StderrURL != "" {
+			step.FullOutput = ref
+		}
+		return
+	}
+
+	// split what is left of the ConfigMap between stdout and stderr, so the
+	// outputs of the earlier steps are kept
+	max := (maxConfigMapTotal - o.size) / 2
+	if max > maxConfigMapOutput {
+		max = maxConfigMapOutput
+	}
+	if max <= 0 {
+		step.OutputError = fmt.Sprintf("ConfigMap %s is full", o.configMap.Name)
+		return
+	}
+	key := configMapKeyRegex.ReplaceAllString(step.Name, "-")
+	stdout := truncateOutput(out.stdout.Bytes(), max)
+	stderr := truncateOutput(out.stderr.Bytes(), max)
+	o.data[key+".stdout"] = stdout
+	o.data[key+".stderr"] = stderr
+	o.size += len(stdout) + len(stderr)
+	if err := o.writeConfigMap(); err != nil {
+		o.reqLogger.Error(err, "Failed to store step output", "Step", step.Name)
+		step.OutputError = fmt.Sprintf("Failed to store the output in ConfigMap %s: %s", o.configMap.Name, err.Error())
+		return
+	}
+	step.FullOutput = &androidv1alpha1.StepOutputRef{
+		ConfigMap: o.configMap.Name,
+		Truncated: out.stdout.Len() > max || out.stderr.Len() > max,
+	}
+}
+
+// writeConfigMap creates or updates the output ConfigMap with the outputs
+// saved so far, replacing the outputs of earlier attempts.
+func (o *outputStore) writeConfigMap() error {
+	cm := &corev1.ConfigMap{}
+	err := o.client.Get(context.TODO(), o.configMap, cm)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            o.configMap.Name,
+				Namespace:       o.configMap.Namespace,
+				OwnerReferences: o.instance.OwnerReferences(),
+			},
+			Data: o.data,
+		}
+		return o.client.Create(context.TODO(), cm)
+	}
+	cm.Data = o.data
+	return o.client.Update(context.TODO(), cm)
+}
+
+// truncateOutput returns the output as a string, keeping only the last max
+// bytes if it is longer.
+func truncateOutput(out []byte, max int) string {
+	if len(out) > max {
+		out = append([]byte("..."), out[len(out)-max:]...)
+	}
+	return strings.ToValidUTF8(string(out), "")
+}
@@ -0,0 +1,226 @@
+package androidjob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateData is passed to the templated fields of actions. The fields of the
+// device pod are available at the top level, so templates written before
+// parameters existed, like {{ .Name }}, keep working.
+type templateData struct {
+	corev1.Pod
+	// Params are the typed values of the job template parameters
+	Params map[string]interface{}
+	// Device is the metadata of the device the job is running on
+	Device deviceData
+}
+
+// deviceData is the metadata of a device made available to templates.
+type deviceData struct {
+	// Name is the name of the AndroidDevice
+	Name string
+	// Group is the device group the device belongs to, if any
+	Group string
+	// Farm is the farm the device belongs to, if any
+	Farm string
+	// Serial is the ADB serial of the device
+	Serial string
+	// Properties are the properties reported by the device
+	Properties androidv1alpha1.DeviceProperties
+}
+
+// newTemplateData resolves the parameters of a job and looks up the metadata
+// of a device. As with runAction, a returned status with a non-empty Status
+// means the job cannot run on the device, while an error means it should be
+// retried.
+func newTemplateData(c client.Client, instance *androidv1alpha1.AndroidJob, jobTemplate *androidv1alpha1.AndroidJobTemplate, device corev1.Pod) (*templateData, androidv1alpha1.DeviceJobStatus, error) {
+	params, err := resolveParameters(c, instance, jobTemplate)
+	if err != nil {
+		if _, ok := err.(*parameterError); ok {
+			return nil, androidv1alpha1.DeviceJobStatus{
+				Status:  androidv1alpha1.StatusFailed,
+				Reason:  androidv1alpha1.ReasonTemplateError,
+				Message: err.Error(),
+			}, nil
+		}
+		return nil, androidv1alpha1.DeviceJobStatus{}, err
+	}
+
+	data := &templateData{
+		Pod:    device,
+		Params: params,
+		Device: deviceData{
+			Name:  device.Name,
+			Group: device.GetLabels()[androidv1alpha1.DeviceGroupLabel],
+			Farm:  device.GetLabels()[androidv1alpha1.DeviceFarmLabel],
+		},
+	}
+
+	// device pods are named after the AndroidDevice that owns them, if they
+	// are managed by one
+	androidDevice := &androidv1alpha1.AndroidDevice{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: device.Name, Namespace: device.Namespace}, androidDevice); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, androidv1alpha1.DeviceJobStatus{}, err
+		}
+		return data, androidv1alpha1.DeviceJobStatus{}, nil
+	}
+	data.Device.Serial = androidDevice.Status.ADBSerial
+	if props := androidDevice.Status.Properties; props != nil {
+		data.Device.Properties = *props
+	}
+	return data, androidv1alpha1.DeviceJobStatus{}, nil
+}
+
+// parameterError is returned for parameters that cannot be resolved without
+// a change to the job or its template.
+type parameterError struct {
+	name, msg string
+}
+
+func (p *parameterError) Error() string {
+	return fmt.Sprintf("Parameter %s: %s", p.name, p.msg)
+}
+
+// resolveParameters returns the values of the parameters of a job template,
+// from the values supplied by the job or their defaults, converted to their
+// types.
+func resolveParameters(c client.Client, instance *androidv1alpha1.AndroidJob, jobTemplate *androidv1alpha1.AndroidJobTemplate) (map[string]interface{}, error) {
+	supplied := make(map[string]androidv1alpha1.JobParameterValue)
+	for _, value := range instance.Spec.Parameters {
+		supplied[value.Name] = value
+	}
+
+	params := make(map[string]interface{})
+	for _, param := range jobTemplate.Spec.Parameters {
+		var raw string
+		if value, ok := supplied[param.Name]; ok {
+			var err error
+			if raw, err = parameterValue(c, instance.GetNamespace(), value); err != nil {
+				return nil, err
+			}
+			delete(supplied, param.Name)
+		} else if param.Default != nil {
+			raw = *param.Default
+		} else if param.Required {
+			return nil, &parameterError{name: param.Name, msg: "a value is required"}
+		} else {
+			params[param.Name] = param.ZeroValue()
+			continue
+		}
+		value, err := param.Parse(raw)
+		if err != nil {
+			return nil, &parameterError{name: param.Name, msg: fmt.Sprintf("invalid %s value: %s", param.Type, err.Error())}
+		}
+		params[param.Name] = value
+	}
+
+	for name := range supplied {
+		return nil, &parameterError{name: name, msg: fmt.Sprintf("not a parameter of template %s", jobTemplate.GetName())}
+	}
+	return params, nil
+}
+
+// parameterValue returns the value supplied for a parameter, reading it from
+// a secret or ConfigMap in the namespace of the job if needed. Secrets must be
+// labeled for use by jobs.
+func parameterValue(c client.Client, namespace string, value androidv1alpha1.JobParameterValue) (string, error) {
+	if value.ValueFrom == nil {
+		return value.Value, nil
+	}
+	if ref := value.ValueFrom.SecretKeyRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+			if errors.IsNotFound(err) && ref.Optional != nil && *ref.Optional {
+				return "", nil
+			}
+			return "", err
+		}
+		// the operator can read any secret, so only expose the ones that were
+		// explicitly shared with jobs
+		if secret.GetLabels()[androidv1alpha1.JobParametersLabel] != "true" {
+			return "", &parameterError{name: value.Name, msg: fmt.Sprintf("secret %s is not labeled %s=true", ref.Name, androidv1alpha1.JobParametersLabel)}
+		}
+		data, ok := secret.Data[ref.Key]
+		if !ok {
+			if ref.Optional != nil && *ref.Optional {
+				return "", nil
+			}
+			return "", &parameterError{name: value.Name, msg: fmt.Sprintf("secret %s does not contain %s", ref.Name, ref.Key)}
+		}
+		return string(data), nil
+	}
+	if ref := value.ValueFrom.ConfigMapKeyRef; ref != nil {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: namespace}, cm); err != nil {
+			if errors.IsNotFound(err) && ref.Optional != nil && *ref.Optional {
+				return "", nil
+			}
+			return "", err
+		}
+		data, ok := cm.Data[ref.Key]
+		if !ok {
+			if ref.Optional != nil && *ref.Optional {
+				return "", nil
+			}
+			return "", &parameterError{name: value.Name, msg: fmt.Sprintf("ConfigMap %s does not contain %s", ref.Name, ref.Key)}
+		}
+		return data, nil
+	}
+	return "", &parameterError{name: value.Name, msg: "valueFrom has no source"}
+}
+
+// renderAction returns a copy of an action with its templated fields rendered
+// for a device.
+func renderAction(data *templateData, action androidv1alpha1.Action) (androidv1alpha1.Action, error) {
+	rendered := *action.DeepCopy()
+	var err error
+	for idx, cmd := range rendered.Commands {
+		if rendered.Commands[idx], err = renderTemplate(data, fmt.Sprintf("commands[%d]", idx), cmd); err != nil {
+			return rendered, err
+		}
+	}
+	if rendered.APKUrl, err = renderTemplate(data, "apkURL", rendered.APKUrl); err != nil {
+		return rendered, err
+	}
+	if rendered.Condition != nil {
+		if rendered.Condition.Command, err = renderTemplate(data, "condition.command", rendered.Condition.Command); err != nil {
+			return rendered, err
+		}
+	}
+	for idx := range rendered.Interactions {
+		interaction := &rendered.Interactions[idx]
+		if interaction.Target, err = renderTemplate(data, fmt.Sprintf("interactions[%d].target", idx), interaction.Target); err != nil {
+			return rendered, err
+		}
+		if interaction.Input, err = renderTemplate(data, fmt.Sprintf("interactions[%d].input", idx), interaction.Input); err != nil {
+			return rendered, err
+		}
+	}
+	return rendered, nil
+}
+
+// renderTemplate renders a single templated field. Errors include the name of
+// the field but not the rendered output, which may contain secrets.
+func renderTemplate(data *templateData, name, tmpl string) (string, error) {
+	if tmpl == "" {
+		return "", nil
+	}
+	t, err := androidv1alpha1.NewActionTemplate(name).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var log = logf.Log.WithName("metrics")
+
+var devicesDesc = prometheus.NewDesc(
+	"android_farm_devices",
+	"The number of AndroidDevices in each farm and group, by their phase",
+	[]string{"farm", "group", "namespace", "phase"},
+	nil,
+)
+
+// deviceCollector reports the number of devices in each phase from the
+// AndroidDevices in the cache of the manager at the time of the scrape.
+type deviceCollector struct {
+	client client.Client
+}
+
+// RegisterDeviceCollector registers a collector for the device counts that
+// reads devices with the given client.
+func RegisterDeviceCollector(c client.Client) error {
+	return crmetrics.Registry.Register(&deviceCollector{client: c})
+}
+
+// Describe implements prometheus.Collector.
+func (d *deviceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- devicesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (d *deviceCollector) Collect(ch chan<- prometheus.Metric) {
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := d.client.List(context.TODO(), devices, client.HasLabels{androidv1alpha1.DeviceFarmLabel}); err != nil {
+		log.Error(err, "Failed to list devices for metrics")
+		return
+	}
+	type key struct {
+		farm, group, namespace, phase string
+	}
+	counts := make(map[key]float64)
+	for _, device := range devices.Items {
+		phase := device.Status.Phase
+		if phase == "" {
+			phase = androidv1alpha1.DevicePending
+		}
+		labels := device.GetLabels()
+		counts[key{
+			farm:      labels[androidv1alpha1.DeviceFarmLabel],
+			group:     labels[androidv1alpha1.DeviceGroupLabel],
+			namespace: device.GetNamespace(),
+			phase:     string(phase),
+		}]++
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(devicesDesc, prometheus.GaugeValue, count, k.farm, k.group, k.namespace, k.phase)
+	}
+}
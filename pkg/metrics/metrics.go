@@ -0,0 +1,121 @@
+// Package metrics contains the prometheus metrics the operator reports about
+// farms, devices and jobs. They are registered with the controller-runtime
+// registry and served alongside its own metrics.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	bootDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "android_device_boot_duration_seconds",
+		Help:    "The time from the creation of an emulator pod until the device reports it has finished booting",
+		Buckets: []float64{15, 30, 45, 60, 90, 120, 180, 240, 300, 450, 600},
+	}, []string{"farm", "group"})
+
+	stfBindDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "android_device_stf_bind_duration_seconds",
+		Help:    "The time taken by the jobs binding emulators to their STF provider",
+		Buckets: []float64{1, 2, 5, 10, 15, 30, 60, 120},
+	}, []string{"farm", "group"})
+
+	stfBindFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "android_device_stf_bind_failures_total",
+		Help: "The number of jobs binding emulators to their STF provider that failed",
+	}, []string{"farm", "group"})
+
+	rethinkDBUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "android_farm_rethinkdb_up",
+		Help: "Whether the operator could connect to the RethinkDB proxy of a farm the last time it was reconciled",
+	}, []string{"farm"})
+
+	jobResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "android_job_device_results_total",
+		Help: "The number of runs of AndroidJobs on a single device, by their outcome",
+	}, []string{"namespace", "template", "result"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "android_job_device_duration_seconds",
+		Help:    "The time taken by runs of AndroidJobs on a single device, by their outcome",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"namespace", "template", "result"})
+
+	requeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "android_farm_operator_requeues_total",
+		Help: "The number of requests requeued by a controller, by the reason given",
+	}, []string{"controller", "reason"})
+)
+
+// JobResultError is the result recorded for runs of a job on a device that
+// ended in an error and are retried.
+const JobResultError = "Error"
+
+func init() {
+	crmetrics.Registry.MustRegister(
+		bootDuration,
+		stfBindDuration,
+		stfBindFailures,
+		rethinkDBUp,
+		jobResults,
+		jobDuration,
+		requeues,
+	)
+}
+
+// deviceLabels returns the farm and group label values for a device.
+func deviceLabels(device *androidv1alpha1.AndroidDevice) []string {
+	labels := device.GetLabels()
+	return []string{labels[androidv1alpha1.DeviceFarmLabel], labels[androidv1alpha1.DeviceGroupLabel]}
+}
+
+// ObserveBootDuration records the time a device took to boot.
+func ObserveBootDuration(device *androidv1alpha1.AndroidDevice, d time.Duration) {
+	bootDuration.WithLabelValues(deviceLabels(device)...).Observe(d.Seconds())
+}
+
+// ObserveSTFBindDuration records the time taken to bind a device to its STF
+// provider.
+func ObserveSTFBindDuration(device *androidv1alpha1.AndroidDevice, d time.Duration) {
+	stfBindDuration.WithLabelValues(deviceLabels(device)...).Observe(d.Seconds())
+}
+
+// RecordSTFBindFailure records a failed attempt to bind a device to its STF
+// provider.
+func RecordSTFBindFailure(device *androidv1alpha1.AndroidDevice) {
+	stfBindFailures.WithLabelValues(deviceLabels(device)...).Inc()
+}
+
+// SetRethinkDBReachable records whether the RethinkDB proxy of a farm could be
+// reached.
+func SetRethinkDBReachable(farm *androidv1alpha1.AndroidFarm, up bool) {
+	var val float64
+	if up {
+		val = 1
+	}
+	rethinkDBUp.WithLabelValues(farm.GetName()).Set(val)
+}
+
+// ForgetFarm removes the series for a farm that was deleted.
+func ForgetFarm(farm *androidv1alpha1.AndroidFarm) {
+	rethinkDBUp.DeleteLabelValues(farm.GetName())
+}
+
+// ObserveJobResult records the result and duration of a run of a job on a
+// single device.
+func ObserveJobResult(job *androidv1alpha1.AndroidJob, result string, d time.Duration) {
+	jobResults.WithLabelValues(job.GetNamespace(), job.Spec.JobTemplate, result).Inc()
+	jobDuration.WithLabelValues(job.GetNamespace(), job.Spec.JobTemplate, result).Observe(d.Seconds())
+}
+
+// RecordRequeue records a request requeued by a controller with the reason
+// given by the requeue error. The fixed reason is used rather than the message,
+// which may contain the names of objects.
+func RecordRequeue(controller string, err *errors.RequeueError) {
+	requeues.WithLabelValues(controller, err.Reason()).Inc()
+}
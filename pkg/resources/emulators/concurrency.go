@@ -3,8 +3,6 @@ package emulators
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
 
 	"github.com/go-logr/logr"
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
@@ -14,13 +12,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// getDeviceIdx returns the ordinal number of a device in a device group
-func getDeviceIdx(devName string) (int64, error) {
-	spl := strings.Split(devName, "-")
-	// Make sure it will fit into an int32
-	return strconv.ParseInt(spl[len(spl)-1], 10, 32)
-}
-
 // groupIndexReadyToCreate takes a device group, a management policy, and a device
 // index and determines if it is ready to be created. Devices are iterated in numerical
 // order up until this device index. If a device does not exist or is not finished booting,
@@ -42,20 +33,16 @@ func groupIndexReadyToCreate(c client.Client, group *androidv1alpha1.DeviceGroup
 			// return all other api errors
 			return err
 		}
-		// check if boot-completed annotation exists (this is provided by the AndroidDevice controller)
-		if found.GetAnnotations() != nil {
-			if bootCompleted, ok := found.Annotations[androidv1alpha1.BootCompletedAnnotation]; ok {
-				if bootCompleted == "true" {
-					// This device is ready
-					continue
-				}
-			}
+		// check if the device has finished booting (this is provided by the AndroidDevice controller)
+		if found.BootCompleted() {
+			// This device is ready
+			continue
 		}
 		// The device is still pending
 		pending++
 	}
 	if pending >= policy.GetConcurrency() {
-		return errors.NewRequeueError("Waiting to create device due to concurrency policy", 5)
+		return errors.NewRequeueError("ConcurrencyLimited", "Waiting to create device due to concurrency policy", 5)
 	}
 	return nil
 }
@@ -63,8 +50,8 @@ func groupIndexReadyToCreate(c client.Client, group *androidv1alpha1.DeviceGroup
 // groupIndexReadyToUpdateFunc returns a function that will return true if the
 // provided device index is ready to be updated. Like the create func above,
 // devices are iterated up until the provided index. See logs below for the checks
-// made on each device.
-// TODO: Both of these functions desperately need unit tests
+// made on each device. Leased devices are skipped, as they are not updated until
+// their lease is released, and so are outdated devices held below the partition.
 func groupIndexReadyToUpdateFunc(reqLogger logr.Logger, c client.Client, farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, devidx int32) func(string) bool {
 	return func(newChecksum string) bool {
 		// If there is no management policy for this group, return true immediately
@@ -87,28 +74,23 @@ func groupIndexReadyToUpdateFunc(reqLogger logr.Logger, c client.Client, farm *a
 				reqLogger.Error(err, "Error looking up device in farm, not allowing update")
 				return false
 			}
-			if found.GetAnnotations() == nil {
-				reqLogger.Info("Found device with no annotations, marking it as pending")
-				pending++
+			if found.IsLeased() {
+				// Leased devices are not updated until they are released, so they
+				// do not hold up the rest of the group.
+				reqLogger.Info("Device is leased, excluding it from the rolling update", "Device.Name", found.Name, "Lease", found.LeaseName())
 				continue
-			} else if _, ok := found.Annotations[androidv1alpha1.DeviceConfigSHAAnnotation]; !ok {
+			}
+			if found.ConfigChecksum() == "" {
 				reqLogger.Info("Found device with no config checksum, marking it as pending")
 				pending++
 				continue
 			}
-			currentChecksum := found.Annotations[androidv1alpha1.DeviceConfigSHAAnnotation]
-			if newChecksum != currentChecksum {
+			if newChecksum != found.ConfigChecksum() && !policy.HoldsUpdate(i) {
 				reqLogger.Info("Existing device's config checksum does not match the new one, marking as pending")
 				pending++
 				continue
 			}
-			bootCompleted, ok := found.Annotations[androidv1alpha1.BootCompletedAnnotation]
-			if !ok {
-				reqLogger.Info("Existing device has no boot status annotation, marking as pending")
-				pending++
-				continue
-			}
-			if bootCompleted != "true" {
+			if !found.BootCompleted() {
 				reqLogger.Info("Device is still booting, marking as pending")
 				pending++
 				continue
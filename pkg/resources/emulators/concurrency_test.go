@@ -0,0 +1,112 @@
+package emulators
+
+import (
+	"testing"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestGroupIndexReadyToUpdateFunc(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *androidv1alpha1.DeviceManagementPolicy
+		devices []*androidv1alpha1.AndroidDevice
+		idx     int32
+		want    bool
+	}{
+		{
+			name:    "no policy",
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(0, oldChecksum, false)},
+			idx:     1,
+			want:    true,
+		},
+		{
+			name:   "first device",
+			policy: &androidv1alpha1.DeviceManagementPolicy{},
+			idx:    0,
+			want:   true,
+		},
+		{
+			name:   "earlier devices updated and booted",
+			policy: &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{
+				testDevice(0, currentChecksum, true),
+				testDevice(1, currentChecksum, true),
+			},
+			idx:  2,
+			want: true,
+		},
+		{
+			name:    "earlier device missing",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(1, currentChecksum, true)},
+			idx:     2,
+			want:    false,
+		},
+		{
+			name:    "earlier device outdated",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(0, oldChecksum, true)},
+			idx:     1,
+			want:    false,
+		},
+		{
+			name:   "outdated devices within concurrency",
+			policy: &androidv1alpha1.DeviceManagementPolicy{Concurrency: 2},
+			devices: []*androidv1alpha1.AndroidDevice{
+				testDevice(0, currentChecksum, true),
+				testDevice(1, oldChecksum, true),
+			},
+			idx:  2,
+			want: true,
+		},
+		{
+			name:   "outdated devices above concurrency",
+			policy: &androidv1alpha1.DeviceManagementPolicy{Concurrency: 2},
+			devices: []*androidv1alpha1.AndroidDevice{
+				testDevice(0, oldChecksum, true),
+				testDevice(1, oldChecksum, true),
+			},
+			idx:  2,
+			want: false,
+		},
+		{
+			name:    "earlier device outdated below the partition",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{Partition: 1},
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(0, oldChecksum, true)},
+			idx:     1,
+			want:    true,
+		},
+		{
+			name:    "earlier device leased",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{leased(testDevice(0, oldChecksum, false))},
+			idx:     1,
+			want:    true,
+		},
+		{
+			name:    "earlier device without a checksum",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(0, "", true)},
+			idx:     1,
+			want:    false,
+		},
+		{
+			name:    "earlier device booting",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(0, currentChecksum, false)},
+			idx:     1,
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			farm, group := testFarm(tt.policy)
+			ready := groupIndexReadyToUpdateFunc(logf.Log, testClient(t, tt.devices...), farm, group, tt.idx)
+			if got := ready(currentChecksum); got != tt.want {
+				t.Errorf("ready to update = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package emulators
+
+import (
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newDeviceGroupForFarmGroup returns the AndroidDeviceGroup used for scaling
+// the given farm group.
+func newDeviceGroupForFarmGroup(farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) *androidv1alpha1.AndroidDeviceGroup {
+	return &androidv1alpha1.AndroidDeviceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      farm.DeviceGroupName(group),
+			Namespace: group.GetNamespace(),
+			Labels: map[string]string{
+				androidv1alpha1.DeviceFarmLabel:  farm.Name,
+				androidv1alpha1.DeviceGroupLabel: group.Name,
+			},
+			OwnerReferences: farm.OwnerReferences(),
+		},
+	}
+}
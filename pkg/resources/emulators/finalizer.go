@@ -0,0 +1,75 @@
+package emulators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var deviceFinalizer = "finalizer.androiddevices.android.stf.io"
+
+// deviceCleanupTimeout is how long the cleanup of a deleted device is retried
+// before the device is released anyway.
+var deviceCleanupTimeout = time.Duration(2) * time.Minute
+
+// finalizeDevice cleans up after a device that is marked for deletion and
+// removes its finalizer once the cleanup succeeds or times out.
+func (r *EmulatorDeviceReconciler) finalizeDevice(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDevice) error {
+	if !util.ContainsString(instance.GetFinalizers(), deviceFinalizer) {
+		return nil
+	}
+
+	if err := r.cleanupDevice(reqLogger, instance); err != nil {
+		if time.Since(instance.GetDeletionTimestamp().Time) < deviceCleanupTimeout {
+			return err
+		}
+		reqLogger.Error(err, "Timed out cleaning up after deleted device, releasing it anyway")
+		r.recorder.Event(instance, corev1.EventTypeWarning, "CleanupTimedOut", fmt.Sprintf("Gave up cleaning up after the device: %s", err.Error()))
+	}
+
+	// remove the finalizer
+	instance.SetFinalizers(util.RemoveString(instance.GetFinalizers(), deviceFinalizer))
+	return r.client.Update(context.TODO(), instance)
+}
+
+// cleanupDevice removes a deleted device from OpenSTF. Its serial is
+// disconnected from the ADB server of its stf provider, along with removing any
+// binding job left behind, and the device is removed from rethinkdb.
+func (r *EmulatorDeviceReconciler) cleanupDevice(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDevice) error {
+	if !instance.IsFarmedDevice() {
+		return nil
+	}
+	farm, err := instance.GetFarm(r.client)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		reqLogger.Info("Farm for deleted device no longer exists, nothing to clean up in OpenSTF")
+		return nil
+	}
+
+	// disconnect first, so the provider doesn't put the device back in
+	// rethinkdb after it is removed
+	if adbServer, ok := instance.GetAnnotations()[androidv1alpha1.STFProviderAnnotation]; ok && instance.Status.ADBSerial != "" {
+		// the pod may already be gone, so only its metadata is used for the jobs
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Name,
+				Namespace: instance.Namespace,
+				Labels:    util.DeviceLabels(instance),
+			},
+		}
+		if err := reconcileSTFUnbinding(reqLogger, r.client, farm, pod, adbServer, instance.Status.ADBSerial); err != nil {
+			return err
+		}
+	}
+
+	return removeFromRethinkDB(reqLogger, farm, instance)
+}
@@ -2,27 +2,24 @@ package emulators
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
 	stfutil "github.com/tinyzimmer/android-farm-operator/pkg/util/stf"
 	"github.com/go-logr/logr"
 	rdb "gopkg.in/rethinkdb/rethinkdb-go.v6"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // runGC runs garbage collection for a given android farm. This function is invoked at
-// the end of every reconcile event.
-func runGC(reqLogger logr.Logger, c client.Client, farm *androidv1alpha1.AndroidFarm) error {
-
-	// make a map of device groups to their count
-	deviceGroups := make(map[string]int32)
-	for _, group := range farm.DeviceGroups() {
-		if group.IsEmulatedGroup() {
-			deviceGroups[group.Name] = group.GetCount()
-		}
-	}
+// the end of every reconcile event with the number of devices desired in each
+// emulated device group.
+func runGC(reqLogger logr.Logger, c client.Client, recorder record.EventRecorder, farm *androidv1alpha1.AndroidFarm, deviceGroups map[string]int32) error {
 
 	// fetch all devices for this farm
 	devices := &androidv1alpha1.AndroidDeviceList{}
@@ -34,31 +31,64 @@ func runGC(reqLogger logr.Logger, c client.Client, farm *androidv1alpha1.Android
 	// a group that no longer exists. Finally, check if their device index is larger
 	// than the number of devices supposed to be in the group.
 	for _, device := range devices.Items {
+		// physical devices follow the inventory of their provider instead
+		if device.IsPhysicalDevice() {
+			continue
+		}
 		var delete bool = false
+		var reason string
 		if group, ok := device.Labels[androidv1alpha1.DeviceGroupLabel]; !ok {
 			reqLogger.Info("Deleting device with no group label", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
 			delete = true
+			reason = "it has no group label"
 		} else if count, ok := deviceGroups[group]; !ok {
 			reqLogger.Info("Deleting device with reference to group that no longer exists", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
 			delete = true
+			reason = fmt.Sprintf("group %s no longer exists", group)
 		} else {
-			devidx, err := getDeviceIdx(device.Name)
+			devidx, err := util.DeviceIndex(device.Name)
 			if err != nil {
 				return err
 			}
-			if int32(devidx) > count-1 {
+			if devidx > count-1 {
 				reqLogger.Info("Deleting device as the group is being scaled down", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
 				delete = true
+				reason = fmt.Sprintf("group %s was scaled down to %d", group, count)
 			}
 		}
-		// If we are deleting, remove the device from STF and delete it's resources
+		// Leased devices are deleted once they are released
+		if delete && device.IsLeased() {
+			reqLogger.Info("Device is leased, deferring deletion until it is released", "Device.Name", device.Name, "Device.Namespace", device.Namespace, "Lease", device.LeaseName())
+			delete = false
+		}
+		// If we are deleting, the device finalizer removes it from STF
 		if delete {
-			if err := removeFromRethinkDB(reqLogger, farm, &device); err != nil {
-				return err
-			}
 			if err := c.Delete(context.TODO(), &device); err != nil {
 				return err
 			}
+			recorder.Eventf(farm, corev1.EventTypeNormal, "DeviceDeleted", "Deleted device %s/%s as %s", device.Namespace, device.Name, reason)
+		}
+	}
+
+	// Delete device groups for groups that no longer exist in the farm, or that
+	// have moved to another namespace
+	namespaces := make(map[string]string)
+	for _, group := range farm.DeviceGroups() {
+		if group.IsEmulatedGroup() {
+			namespaces[group.Name] = group.GetNamespace()
+		}
+	}
+	groups := &androidv1alpha1.AndroidDeviceGroupList{}
+	if err := c.List(context.TODO(), groups, client.InNamespace(metav1.NamespaceAll), farm.MatchingLabels()); err != nil {
+		return err
+	}
+	for _, group := range groups.Items {
+		if namespace, ok := namespaces[group.Labels[androidv1alpha1.DeviceGroupLabel]]; ok && namespace == group.Namespace {
+			continue
+		}
+		reqLogger.Info("Deleting device group that no longer exists in the farm", "DeviceGroup.Name", group.Name, "DeviceGroup.Namespace", group.Namespace)
+		if err := c.Delete(context.TODO(), &group); err != nil {
+			return err
 		}
 	}
 
@@ -69,15 +99,9 @@ func runGC(reqLogger logr.Logger, c client.Client, farm *androidv1alpha1.Android
 // of its farm. This is not a crucial function as its only purpose is to keep
 // the OpenSTF UI tidy.
 func removeFromRethinkDB(reqLogger logr.Logger, farm *androidv1alpha1.AndroidFarm, device *androidv1alpha1.AndroidDevice) error {
-	if device.GetAnnotations() == nil {
-		// TODO - I mean we could. Some more utility functions can be defined to
-		// deal with values like this, instead of annotations.
-		reqLogger.Info("Device has no annotations, can't determine serial", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
-		return nil
-	}
-	serial, ok := device.Annotations[androidv1alpha1.ProviderSerialAnnotation]
-	if !ok {
-		reqLogger.Info("Device has no provider serial annotations, can't determine stf name", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
+	serial := device.Status.ADBSerial
+	if serial == "" {
+		reqLogger.Info("Device has no ADB serial in its status, can't determine stf name", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
 		return nil
 	}
 	// connect to the master rethinkdb instance
@@ -87,6 +111,7 @@ func removeFromRethinkDB(reqLogger logr.Logger, farm *androidv1alpha1.AndroidFar
 	if err != nil {
 		return err
 	}
+	defer session.Close()
 	// remove any device from the devices table that matches the serial of the removed
 	// device.
 	_, err = rdb.DB("stf").Table("devices").Filter(func(uu rdb.Term) rdb.Term {
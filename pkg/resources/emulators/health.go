@@ -0,0 +1,241 @@
+package emulators
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileHealth runs the checks in the given health policy against a device
+// that has booted before, and remediates it once it has failed too many checks
+// in a row. The request is requeued for the next check.
+func (r *EmulatorDeviceReconciler) reconcileHealth(reqLogger logr.Logger, device *androidv1alpha1.AndroidDevice, pod *corev1.Pod, adbPort int32, policy *androidv1alpha1.HealthPolicy, status *androidv1alpha1.AndroidDeviceStatus) error {
+	if status.Health == nil {
+		status.Health = &androidv1alpha1.DeviceHealthStatus{}
+	}
+	health := status.Health
+
+	now := metav1.Now()
+	if health.LastCheckTime != nil {
+		if remaining := policy.GetInterval() - now.Sub(health.LastCheckTime.Time); remaining > 0 {
+			// keep binding the device in between checks, unless it is rebooting
+			if status.GetCondition(androidv1alpha1.DeviceBootCompleted).IsTrue() {
+				if err := reconcileSTFBinding(reqLogger, r.client, r.recorder, device, pod, status); err != nil {
+					return err
+				}
+			}
+			return requeueForHealthCheck(remaining)
+		}
+	}
+	health.LastCheckTime = &now
+
+	if err := runHealthChecks(reqLogger, pod.Status.PodIP, adbPort, policy, health); err != nil {
+		health.ConsecutiveFailures++
+		health.LastFailure = err.Error()
+		reqLogger.Info("Device failed health check", "Failures", health.ConsecutiveFailures, "Reason", health.LastFailure)
+		status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceHealthy, corev1.ConditionFalse, "CheckFailed", health.LastFailure))
+		if health.ConsecutiveFailures >= policy.GetFailureThreshold() {
+			return r.remediate(reqLogger, device, pod, adbPort, policy, status)
+		}
+		return requeueForHealthCheck(policy.GetInterval())
+	}
+
+	// the device is healthy again, start over from the first remediation
+	health.ConsecutiveFailures = 0
+	health.LastFailure = ""
+	health.LastAction = ""
+	status.LastHealthCheck = &now
+	status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceHealthy, corev1.ConditionTrue, "ChecksPassed", "The device passed its health checks"))
+	if !status.GetCondition(androidv1alpha1.DeviceBootCompleted).IsTrue() {
+		status.SetCondition(androidv1alpha1.NewCondition(
+			androidv1alpha1.DeviceBootCompleted, corev1.ConditionTrue, "BootCompleted", "The device has finished booting",
+		))
+	}
+	if err := reconcileSTFBinding(reqLogger, r.client, r.recorder, device, pod, status); err != nil {
+		return err
+	}
+	return requeueForHealthCheck(policy.GetInterval())
+}
+
+// remediate takes the next remediation action on an unhealthy device. Devices
+// that are not bound to an STF provider skip reconnecting, and devices that
+// cannot be rebooted over ADB have their pod recreated instead. Leased devices
+// are not rebooted or recreated until they are released.
+func (r *EmulatorDeviceReconciler) remediate(reqLogger logr.Logger, device *androidv1alpha1.AndroidDevice, pod *corev1.Pod, adbPort int32, policy *androidv1alpha1.HealthPolicy, status *androidv1alpha1.AndroidDeviceStatus) error {
+	health := status.Health
+	action := androidv1alpha1.NextRemediation(health.LastAction)
+	if _, ok := device.GetAnnotations()[androidv1alpha1.STFProviderAnnotation]; !ok && action == androidv1alpha1.RemediationReconnect {
+		action = androidv1alpha1.RemediationReboot
+	}
+	msg := fmt.Sprintf("The device failed %d health checks in a row: %s", health.ConsecutiveFailures, health.LastFailure)
+
+	if action != androidv1alpha1.RemediationReconnect && device.IsLeased() {
+		// the failures are kept, so the device is remediated on the first failed
+		// check after it is released
+		reqLogger.Info("Device is leased, deferring remediation until it is released", "Action", action, "Lease", device.LeaseName())
+		if cond := status.GetCondition(androidv1alpha1.DeviceHealthy); cond == nil || cond.Reason != "RemediationDeferred" {
+			r.recorder.Eventf(device, corev1.EventTypeWarning, "RemediationDeferred", "%s, deferring %s until lease %s is released", msg, action, device.LeaseName())
+		}
+		status.SetCondition(androidv1alpha1.NewCondition(
+			androidv1alpha1.DeviceHealthy, corev1.ConditionFalse, "RemediationDeferred",
+			fmt.Sprintf("%s. The device is leased by %s, so it is not remediated until it is released", health.LastFailure, device.LeaseName()),
+		))
+		return requeueForHealthCheck(policy.GetInterval())
+	}
+
+	if action == androidv1alpha1.RemediationReboot {
+		if err := rebootDevice(reqLogger, pod.Status.PodIP, adbPort); err != nil {
+			reqLogger.Error(err, "Failed to reboot device, recreating its pod instead")
+			action = androidv1alpha1.RemediationRecreate
+		}
+	}
+
+	reqLogger.Info("Remediating unhealthy device", "Action", action)
+	now := metav1.Now()
+	health.ConsecutiveFailures = 0
+	health.LastAction = action
+	health.LastActionTime = &now
+	health.ScreenHash = ""
+
+	switch action {
+	case androidv1alpha1.RemediationReconnect:
+		r.recorder.Event(device, corev1.EventTypeWarning, "Reconnecting", msg)
+		status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceSTFBound, corev1.ConditionFalse, "Reconnecting", "The device is being reconnected to its STF provider"))
+		if err := reconcileSTFBinding(reqLogger, r.client, r.recorder, device, pod, status); err != nil {
+			return err
+		}
+	case androidv1alpha1.RemediationReboot:
+		r.recorder.Event(device, corev1.EventTypeWarning, "Rebooting", msg)
+		setDeviceNotReady(status, "Rebooting", "The device is being rebooted after failing its health checks")
+	case androidv1alpha1.RemediationRecreate:
+		r.recorder.Event(device, corev1.EventTypeWarning, "Recreating", msg)
+		if err := r.client.Delete(context.TODO(), pod); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		resetDeviceStatus(status, "Recreating", "The device pod is being recreated after failing its health checks")
+		return errors.NewRequeueError("PodRecreating", "Requeueing to recreate the device pod", 3)
+	}
+	return requeueForHealthCheck(policy.GetInterval())
+}
+
+// runHealthChecks connects to the device and runs all the checks in the given
+// policy against it. The first failure is returned.
+func runHealthChecks(reqLogger logr.Logger, host string, port int32, policy *androidv1alpha1.HealthPolicy, health *androidv1alpha1.DeviceHealthStatus) error {
+	sess, err := android.NewSession(reqLogger, host, port)
+	if err != nil {
+		return fmt.Errorf("%s: %s", androidv1alpha1.ADBReachable, err.Error())
+	}
+	defer sess.Close()
+	for _, check := range policy.GetChecks() {
+		if err := runHealthCheck(sess, check, health); err != nil {
+			return fmt.Errorf("%s: %s", check.Type, err.Error())
+		}
+	}
+	return nil
+}
+
+// runHealthCheck runs a single check against the device in the given session.
+func runHealthCheck(sess android.DeviceSession, check androidv1alpha1.HealthCheck, health *androidv1alpha1.DeviceHealthStatus) error {
+	switch check.Type {
+	case androidv1alpha1.ADBReachable:
+		if _, err := sess.RunCommand(false, "true"); err != nil {
+			if strings.Contains(err.Error(), "device offline") {
+				return fmt.Errorf("The device is offline to ADB")
+			}
+			return err
+		}
+	case androidv1alpha1.BootCompleted:
+		complete, err := sess.BootCompleted()
+		if err != nil {
+			return err
+		}
+		if !complete {
+			return fmt.Errorf("sys.boot_completed is not set")
+		}
+	case androidv1alpha1.ShellProbe:
+		out, err := sess.RunCommand(false, check.Command)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(out), check.ExpectedOutput) {
+			return fmt.Errorf("Expected output to contain %q, got %q", check.ExpectedOutput, strings.TrimSpace(string(out)))
+		}
+	case androidv1alpha1.ScreenNotFrozen:
+		screencap, err := sess.GetScreencap()
+		if err != nil {
+			return err
+		}
+		if screenIsBlack(screencap) {
+			return fmt.Errorf("The screen is black")
+		}
+		hash := screenHash(screencap)
+		last := health.ScreenHash
+		health.ScreenHash = hash
+		if hash == last {
+			return fmt.Errorf("The screen has not changed since the last check")
+		}
+	}
+	return nil
+}
+
+// rebootDevice reboots the emulator at the given address over ADB.
+func rebootDevice(reqLogger logr.Logger, host string, port int32) error {
+	sess, err := android.NewSession(reqLogger, host, port)
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	return sess.Reboot()
+}
+
+// screenSampleStep is the distance in pixels between the points sampled when
+// checking if a screen is black.
+const screenSampleStep = 8
+
+// screenIsBlack returns true if no sampled pixel of the given screen capture
+// has any color.
+func screenIsBlack(img image.Image) bool {
+	bounds := img.Bounds()
+	// the first row is skipped, since raw captures start with a header
+	for y := bounds.Min.Y + 1; y < bounds.Max.Y; y += screenSampleStep {
+		for x := bounds.Min.X; x < bounds.Max.X; x += screenSampleStep {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if r|g|b > 0x0fff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// screenHash returns a short hash of the pixels of the given screen capture.
+func screenHash(img image.Image) string {
+	h := sha256.New()
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		h.Write(nrgba.Pix)
+	} else {
+		bounds := img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b, a := img.At(x, y).RGBA()
+				h.Write([]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8), byte(a >> 8)})
+			}
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
+
+func requeueForHealthCheck(after time.Duration) error {
+	return errors.NewRequeueError("HealthCheckScheduled", "Requeueing for the next health check", int(after.Seconds())+1)
+}
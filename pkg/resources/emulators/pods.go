@@ -38,11 +38,16 @@ func newPodForDevice(device *androidv1alpha1.AndroidDevice, conf *androidv1alpha
 			},
 		},
 		Spec: corev1.PodSpec{
-			Hostname:         device.Spec.Hostname,
-			Subdomain:        device.Spec.Subdomain,
-			ImagePullSecrets: conf.GetImagePullSecrets(),
-			Volumes:          volumes,
-			SecurityContext:  podSecurityContext,
+			Hostname:                  device.Spec.Hostname,
+			Subdomain:                 device.Spec.Subdomain,
+			ImagePullSecrets:          conf.GetImagePullSecrets(),
+			Volumes:                   volumes,
+			SecurityContext:           podSecurityContext,
+			NodeSelector:              kvmNodeSelector(conf),
+			Affinity:                  conf.GetAffinity(deviceGroupSelector(device)),
+			Tolerations:               conf.GetTolerations(),
+			PriorityClassName:         conf.GetPriorityClassName(),
+			TopologySpreadConstraints: conf.GetTopologySpreadConstraints(),
 			Containers: append([]corev1.Container{
 				{
 					Name:            device.GetName(),
@@ -67,3 +72,20 @@ func newPodForDevice(device *androidv1alpha1.AndroidDevice, conf *androidv1alpha
 		},
 	}
 }
+
+// deviceGroupSelector returns the labels selecting the pods of the device group
+// the given device belongs to, or nil if it does not belong to a group.
+func deviceGroupSelector(device *androidv1alpha1.AndroidDevice) map[string]string {
+	farm, ok := device.Labels[androidv1alpha1.DeviceFarmLabel]
+	if !ok {
+		return nil
+	}
+	group, ok := device.Labels[androidv1alpha1.DeviceGroupLabel]
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		androidv1alpha1.DeviceFarmLabel:  farm,
+		androidv1alpha1.DeviceGroupLabel: group,
+	}
+}
@@ -0,0 +1,409 @@
+package emulators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// quickBootMountPath is where the golden snapshot volume is mounted in emulator
+// and snapshot pods.
+var quickBootMountPath = "/quickboot"
+
+// volumeSnapshotGVK is the kind used for cloning golden snapshot volumes. It is
+// handled as unstructured data so the snapshot CRDs are only required when
+// volume snapshots are used.
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1beta1",
+	Kind:    "VolumeSnapshot",
+}
+
+// quickBootChecksum shortens a configuration checksum so it can be used in
+// resource names and label values.
+func quickBootChecksum(checksum string) string {
+	return shortChecksum(checksum)
+}
+
+// quickBootName returns the name of the golden snapshot resources for a
+// configuration checksum.
+func quickBootName(checksum string) string {
+	return fmt.Sprintf("quickboot-%s", quickBootChecksum(checksum))
+}
+
+// reconcileQuickBoot ensures a golden snapshot exists in the namespace of the
+// given pod for the configuration checksum, and attaches it to the pod. The
+// request is requeued while the snapshot is being taken. If the snapshot job
+// fails, the pod is left to cold boot, and the failed job is kept so the
+// snapshot is not taken again until it is deleted or the configuration changes.
+func reconcileQuickBoot(reqLogger logr.Logger, c client.Client, recorder record.EventRecorder, device *androidv1alpha1.AndroidDevice, conf *androidv1alpha1.AndroidDeviceConfig, checksum string, pod *corev1.Pod, status *androidv1alpha1.AndroidDeviceStatus) error {
+	name := quickBootName(checksum)
+	labels := map[string]string{androidv1alpha1.QuickBootChecksumLabel: quickBootChecksum(checksum)}
+
+	// ensure the golden volume and populate it with a job
+	golden := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: pod.Namespace,
+			Labels:    labels,
+		},
+		Spec: conf.Spec.QuickBoot.PVCSpec,
+	}
+	if err := ensurePVC(reqLogger, c, golden); err != nil {
+		return err
+	}
+	job := newQuickBootJob(name, pod.Namespace, labels, conf)
+	if err := util.ReconcileJob(reqLogger, c, job, true); err != nil {
+		if _, ok := errors.IsRequeueError(err); !ok {
+			return err
+		}
+		failed, getErr := quickBootJobFailed(c, job)
+		if getErr != nil {
+			return getErr
+		}
+		if failed == nil {
+			return err
+		}
+		if cond := status.GetCondition(androidv1alpha1.DeviceQuickBootReady); cond == nil || cond.Reason != "SnapshotFailed" {
+			reqLogger.Info("Quick-boot snapshot job failed, falling back to a cold boot", "Job.Name", job.Name, "Reason", failed.Reason)
+			recorder.Eventf(device, corev1.EventTypeWarning, "QuickBootFailed", "The job taking the quick-boot snapshot %s failed, cold booting the device: %s", job.Name, failed.Message)
+		}
+		status.SetCondition(androidv1alpha1.NewCondition(
+			androidv1alpha1.DeviceQuickBootReady, corev1.ConditionFalse, "SnapshotFailed",
+			fmt.Sprintf("The job taking the quick-boot snapshot %s failed, delete it to try again", job.Name),
+		))
+		return nil
+	}
+
+	if !conf.Spec.QuickBoot.ClonesVolumeSnapshots() {
+		appendQuickBootVolume(pod, golden.Name, true)
+		setQuickBootReady(status)
+		return nil
+	}
+
+	// snapshot the golden volume and give the pod its own copy of it
+	if err := reconcileVolumeSnapshot(reqLogger, c, name, pod.Namespace, labels, conf.Spec.QuickBoot.VolumeSnapshotClassName); err != nil {
+		return err
+	}
+	cloneLabels := map[string]string{androidv1alpha1.QuickBootChecksumLabel: quickBootChecksum(checksum)}
+	for k, v := range util.DeviceLabels(device) {
+		cloneLabels[k] = v
+	}
+	clone := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", pod.Name, name),
+			Namespace: pod.Namespace,
+			Labels:    cloneLabels,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         device.APIVersion,
+					Kind:               device.Kind,
+					Name:               device.GetName(),
+					UID:                device.GetUID(),
+					Controller:         util.BoolPointer(true),
+					BlockOwnerDeletion: util.BoolPointer(true),
+				},
+			},
+		},
+		Spec: *conf.Spec.QuickBoot.PVCSpec.DeepCopy(),
+	}
+	clone.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &volumeSnapshotGVK.Group,
+		Kind:     volumeSnapshotGVK.Kind,
+		Name:     name,
+	}
+	if err := ensurePVC(reqLogger, c, clone); err != nil {
+		return err
+	}
+	appendQuickBootVolume(pod, clone.Name, false)
+	setQuickBootReady(status)
+	return nil
+}
+
+// quickBootJobFailed returns the failed condition of the given snapshot job, or
+// nil if it has not failed.
+func quickBootJobFailed(c client.Client, job *batchv1.Job) (*batchv1.JobCondition, error) {
+	found := &batchv1.Job{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, found); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	for i, cond := range found.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return &found.Status.Conditions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// setQuickBootReady records that the device boots from its golden snapshot.
+func setQuickBootReady(status *androidv1alpha1.AndroidDeviceStatus) {
+	status.SetCondition(androidv1alpha1.NewCondition(
+		androidv1alpha1.DeviceQuickBootReady, corev1.ConditionTrue, "SnapshotReady", "The device boots from its golden quick-boot snapshot",
+	))
+}
+
+// reconcileVolumeSnapshot ensures a VolumeSnapshot of the golden volume with the
+// given name, and requeues until it is ready to be cloned.
+func reconcileVolumeSnapshot(reqLogger logr.Logger, c client.Client, name, namespace string, labels map[string]string, className string) error {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, snapshot); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		reqLogger.Info("Creating VolumeSnapshot of golden snapshot volume", "VolumeSnapshot.Name", name, "VolumeSnapshot.Namespace", namespace)
+		snapshot.SetName(name)
+		snapshot.SetNamespace(namespace)
+		snapshot.SetLabels(labels)
+		snapshot.Object["spec"] = map[string]interface{}{
+			"volumeSnapshotClassName": className,
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": name,
+			},
+		}
+		if err := c.Create(context.TODO(), snapshot); err != nil {
+			return err
+		}
+		return errors.NewRequeueError("SnapshotNotReady", "Waiting for VolumeSnapshot of golden snapshot volume", 3)
+	}
+	if ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !ready {
+		return errors.NewRequeueError("SnapshotNotReady", "Waiting for VolumeSnapshot of golden snapshot volume", 3)
+	}
+	return nil
+}
+
+// ensurePVC creates the given PVC if it does not exist. Existing PVCs are left
+// as they are.
+func ensurePVC(reqLogger logr.Logger, c client.Client, pvc *corev1.PersistentVolumeClaim) error {
+	found := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, found); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		reqLogger.Info("Creating new PVC for quick-boot snapshot", "PVC.Name", pvc.Name, "PVC.Namespace", pvc.Namespace)
+		return c.Create(context.TODO(), pvc)
+	}
+	return nil
+}
+
+// appendQuickBootVolume mounts the golden snapshot volume into an emulator pod
+// and instructs the emulator to boot from it.
+func appendQuickBootVolume(pod *corev1.Pod, claimName string, readOnly bool) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "quickboot",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+				ReadOnly:  readOnly,
+			},
+		},
+	})
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "quickboot",
+		MountPath: quickBootMountPath,
+		ReadOnly:  readOnly,
+	})
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, quickBootEnvVars("load")...)
+}
+
+// newQuickBootJob returns a job that cold boots an emulator with the given
+// configuration and saves a snapshot of it to the golden volume.
+func newQuickBootJob(name, namespace string, labels map[string]string, conf *androidv1alpha1.AndroidDeviceConfig) *batchv1.Job {
+	volumes := []corev1.Volume{
+		{
+			Name: "quickboot",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: name,
+				},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "quickboot",
+			MountPath: quickBootMountPath,
+		},
+	}
+	securityContext := &corev1.SecurityContext{}
+	podSecurityContext := &corev1.PodSecurityContext{}
+	if conf.IsKVMEnabled() {
+		volumes, volumeMounts = appendKVMVolume(name, volumes, volumeMounts)
+		securityContext.Privileged = util.BoolPointer(true)
+		podSecurityContext.RunAsNonRoot = util.BoolPointer(false)
+	}
+	backoffLimit := int32(2)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:     corev1.RestartPolicyNever,
+					ImagePullSecrets:  conf.GetImagePullSecrets(),
+					Volumes:           volumes,
+					SecurityContext:   podSecurityContext,
+					NodeSelector:      kvmNodeSelector(conf),
+					Affinity:          conf.GetAffinity(nil),
+					Tolerations:       conf.GetTolerations(),
+					PriorityClassName: conf.GetPriorityClassName(),
+					Containers: []corev1.Container{
+						{
+							Name:            "quickboot",
+							Command:         conf.Spec.Command,
+							Args:            conf.Spec.Args,
+							Image:           conf.Spec.DockerImage,
+							ImagePullPolicy: conf.Spec.ImagePullPolicy,
+							VolumeMounts:    volumeMounts,
+							SecurityContext: securityContext,
+							Env:             append(conf.GetEnvVars(), quickBootEnvVars("save")...),
+							Resources:       conf.Spec.Resources,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// quickBootEnvVars returns the environment variables that tell the emulator
+// image where the golden snapshot is and whether to save or load it.
+func quickBootEnvVars(mode string) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "QUICKBOOT_MODE",
+			Value: mode,
+		},
+		{
+			Name:  "QUICKBOOT_DIR",
+			Value: quickBootMountPath,
+		},
+	}
+}
+
+// cleanupQuickBoot removes golden snapshots, and the volumes cloned from them,
+// that are no longer used by any device in the given namespace. A snapshot is
+// in use while a device is running the configuration it was taken for, or is
+// about to.
+func cleanupQuickBoot(reqLogger logr.Logger, c client.Client, namespace string) error {
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := c.List(context.TODO(), devices, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	// devices of the same group share their configuration, so each distinct
+	// configuration is only looked up and checksummed once
+	refs := make(map[string]*androidv1alpha1.AndroidDeviceConfig)
+	checksums := make(map[string]string)
+	inUse := make(map[string]struct{})
+	for i := range devices.Items {
+		device := &devices.Items[i]
+		if device.Status.ConfigChecksum != "" {
+			inUse[quickBootChecksum(device.Status.ConfigChecksum)] = struct{}{}
+		}
+		var refName string
+		if device.Spec.ConfigRef != nil {
+			refName = device.Spec.ConfigRef.Name
+		}
+		overrides, err := json.Marshal(device.Spec.DeviceConfig)
+		if err != nil {
+			return err
+		}
+		key := refName + "/" + string(overrides)
+		checksum, ok := checksums[key]
+		if !ok {
+			base, ok := refs[refName]
+			if !ok {
+				base = &androidv1alpha1.AndroidDeviceConfig{}
+				if refName != "" {
+					if err := c.Get(context.TODO(), types.NamespacedName{Name: refName, Namespace: metav1.NamespaceAll}, base); err != nil {
+						return err
+					}
+				}
+				refs[refName] = base
+			}
+			config, err := device.MergeConfig(base)
+			if err != nil {
+				return err
+			}
+			if checksum, err = config.Checksum(); err != nil {
+				return err
+			}
+			checksums[key] = checksum
+		}
+		inUse[quickBootChecksum(checksum)] = struct{}{}
+	}
+	stale := func(obj metav1.Object) bool {
+		_, ok := inUse[obj.GetLabels()[androidv1alpha1.QuickBootChecksumLabel]]
+		return !ok
+	}
+	selector := client.HasLabels{androidv1alpha1.QuickBootChecksumLabel}
+
+	jobs := &batchv1.JobList{}
+	if err := c.List(context.TODO(), jobs, client.InNamespace(namespace), selector); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		if !stale(&jobs.Items[i]) {
+			continue
+		}
+		reqLogger.Info("Deleting stale quick-boot snapshot job", "Job.Name", jobs.Items[i].Name, "Job.Namespace", namespace)
+		if err := c.Delete(context.TODO(), &jobs.Items[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	snapshots := &unstructured.UnstructuredList{}
+	snapshots.SetGroupVersionKind(volumeSnapshotGVK)
+	if err := c.List(context.TODO(), snapshots, client.InNamespace(namespace), selector); err != nil {
+		// the snapshot CRDs are not required unless they are used
+		if !meta.IsNoMatchError(err) {
+			return err
+		}
+	}
+	for i := range snapshots.Items {
+		if !stale(&snapshots.Items[i]) {
+			continue
+		}
+		reqLogger.Info("Deleting stale quick-boot VolumeSnapshot", "VolumeSnapshot.Name", snapshots.Items[i].GetName(), "VolumeSnapshot.Namespace", namespace)
+		if err := c.Delete(context.TODO(), &snapshots.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	// PVCs that are still mounted are not removed until their pods are gone
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(context.TODO(), pvcs, client.InNamespace(namespace), selector); err != nil {
+		return err
+	}
+	for i := range pvcs.Items {
+		if !stale(&pvcs.Items[i]) {
+			continue
+		}
+		reqLogger.Info("Deleting stale quick-boot PVC", "PVC.Name", pvcs.Items[i].Name, "PVC.Namespace", namespace)
+		if err := c.Delete(context.TODO(), &pvcs.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
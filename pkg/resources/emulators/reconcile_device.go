@@ -3,17 +3,22 @@ package emulators
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/metrics"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/android"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -21,30 +26,56 @@ import (
 type EmulatorDeviceReconciler struct {
 	resources.FarmReconciler
 
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
-// NewForDevice returns a reconciler for an AndroidDevice object
-func NewForDevice(c client.Client, s *runtime.Scheme) resources.DeviceReconciler {
-	return &EmulatorDeviceReconciler{client: c, scheme: s}
+// NewForDevice returns a reconciler for an AndroidDevice object. Remediations
+// taken on unhealthy devices are recorded as events with the given recorder.
+func NewForDevice(c client.Client, s *runtime.Scheme, recorder record.EventRecorder) resources.DeviceReconciler {
+	return &EmulatorDeviceReconciler{client: c, scheme: s, recorder: recorder}
 }
 
 // Reconcile reconciles an AndroidDevice in the cluster with its desired state.
+// The observed state of the device is written to its status once reconciliation
+// finishes or is requeued.
 func (r *EmulatorDeviceReconciler) Reconcile(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDevice) error {
-	// TODO : Use a finalizer for this
-	if instance.GetDeletionTimestamp() != nil && instance.IsFarmedDevice() {
-		farm, err := instance.GetFarm(r.client)
-		if err != nil {
-			reqLogger.Error(err, "Could not find farm for deleted device, won't be able to clean up rethinkdb")
-			return nil
+	status := instance.Status.DeepCopy()
+
+	// check if marked for deletion and clean up after the device
+	if instance.GetDeletionTimestamp() != nil {
+		status.Phase = androidv1alpha1.DeviceTerminating
+		if err := updateDeviceStatus(r.client, instance, status); err != nil {
+			return err
+		}
+		return r.finalizeDevice(reqLogger, instance)
+	}
+
+	// seed the status of devices created by earlier versions of the operator
+	if err := r.migrateLegacyAnnotations(instance, status); err != nil {
+		return err
+	}
+
+	// ensure finalizer for cleanup
+	if !util.ContainsString(instance.GetFinalizers(), deviceFinalizer) {
+		instance.SetFinalizers(append(instance.GetFinalizers(), deviceFinalizer))
+		if err := r.client.Update(context.TODO(), instance); err != nil {
+			return err
 		}
-		if err := removeFromRethinkDB(reqLogger, farm, instance); err != nil {
-			reqLogger.Error(err, "Failed to remove device from rethinkdb, leaving some garbage behind")
-			return nil
+	}
+
+	err := r.reconcileDevice(reqLogger, instance, status)
+	if statusErr := updateDeviceStatus(r.client, instance, status); statusErr != nil {
+		if err == nil {
+			return statusErr
 		}
+		reqLogger.Error(statusErr, "Failed to update AndroidDevice status")
 	}
+	return err
+}
 
+func (r *EmulatorDeviceReconciler) reconcileDevice(reqLogger logr.Logger, instance *androidv1alpha1.AndroidDevice, status *androidv1alpha1.AndroidDeviceStatus) error {
 	reqLogger.Info("Reconciling pod for android device", "DeviceName", instance.Name, "DeviceNamespace", instance.Namespace)
 
 	config, err := instance.GetConfig(r.client)
@@ -52,26 +83,59 @@ func (r *EmulatorDeviceReconciler) Reconcile(reqLogger logr.Logger, instance *an
 		return err
 	}
 
+	checksum, err := config.Checksum()
+	if err != nil {
+		return err
+	}
+
 	pod := newPodForDevice(instance, config)
 
+	volumes := make([]androidv1alpha1.DeviceVolumeStatus, 0)
 	if len(config.Spec.Volumes) > 0 {
 		for _, vol := range config.Spec.Volumes {
 			// retrieve an existing pvc or create a new one
-			pvc, err := reconcilePVCForPod(reqLogger, r.client, pod, vol, util.DeviceLabels(instance))
+			pvc, err := reconcilePVCForPod(reqLogger, r.client, instance, pod, vol, checksum)
 			if err != nil {
 				return err
 			}
+			volumes = append(volumes, androidv1alpha1.DeviceVolumeStatus{ClaimName: pvc.Name, Phase: pvc.Status.Phase})
 			// attach the pvc to the pod
 			// TODO : This could be done with getters from newPodForDevice
 			pod = appendPVCToPod(pod, pvc, vol)
 		}
 	}
+	status.Volumes = nil
+	if len(volumes) > 0 {
+		status.Volumes = volumes
+	}
+
+	// boot from a golden snapshot if configured, the current pod keeps running
+	// while a new one is taken
+	if config.QuickBootEnabled() {
+		if err := reconcileQuickBoot(reqLogger, r.client, r.recorder, instance, config, checksum, pod, status); err != nil {
+			if _, ok := errors.IsRequeueError(err); ok && !status.GetCondition(androidv1alpha1.DeviceBootCompleted).IsTrue() {
+				setDeviceNotReady(status, "WaitingForSnapshot", "Waiting for the golden quick-boot snapshot to be taken")
+			}
+			return err
+		}
+	} else if status.GetCondition(androidv1alpha1.DeviceQuickBootReady) != nil {
+		status.RemoveCondition(androidv1alpha1.DeviceQuickBootReady)
+	}
 
 	if created, err := util.ReconcilePod(reqLogger, r.client, pod); err != nil {
 		return err
 	} else if created {
-		return errors.NewRequeueError("Requeueing to check pod boot progress", 3)
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, "PodCreated", "Created pod %s for the device", pod.Name)
+		resetDeviceStatus(status, "PodCreated", "A new pod was created for the device")
+		status.ConfigChecksum = checksum
+		if err := cleanupQuickBoot(reqLogger, r.client, instance.Namespace); err != nil {
+			reqLogger.Error(err, "Failed to clean up stale quick-boot snapshots")
+		}
+		return errors.NewRequeueError("DeviceBooting", "Requeueing to check pod boot progress", 3)
 	}
+	// the config checksum is only recorded when a pod is created with it, an
+	// existing pod keeps running the config it was created with until the
+	// device is updated by its farm
 
 	// Fetch the created pod
 	found := &corev1.Pod{}
@@ -79,7 +143,7 @@ func (r *EmulatorDeviceReconciler) Reconcile(reqLogger logr.Logger, instance *an
 		if client.IgnoreNotFound(err) != nil {
 			return err
 		}
-		return errors.NewRequeueError("Could not find pod for new android device", 3)
+		return errors.NewRequeueError("PodNotFound", "Could not find pod for new android device", 3)
 	}
 
 	// Get our ADB Port
@@ -89,9 +153,21 @@ func (r *EmulatorDeviceReconciler) Reconcile(reqLogger logr.Logger, instance *an
 	}
 
 	// connect to the device and check boot status
+	status.PodIP = found.Status.PodIP
 	if found.Status.PodIP == "" {
-		return errors.NewRequeueError("The device has not yet been assigned a private IP address", 3)
+		return errors.NewRequeueError("PodIPPending", "The device has not yet been assigned a private IP address", 3)
 	}
+	// devices that have booted before are watched by their health policy, if any
+	policy := config.GetHealthPolicy()
+	if policy == nil {
+		status.Health = nil
+		if status.GetCondition(androidv1alpha1.DeviceHealthy) != nil {
+			status.RemoveCondition(androidv1alpha1.DeviceHealthy)
+		}
+	} else if status.BootCompletedAt != nil {
+		return r.reconcileHealth(reqLogger, instance, found, adbPort, policy, status)
+	}
+
 	reqLogger.Info(fmt.Sprintf("Connecting to android device %s on %s:%d", found.Name, found.Status.PodIP, adbPort))
 	sess, err := android.NewSession(reqLogger, found.Status.PodIP, adbPort)
 	if err != nil {
@@ -100,63 +176,145 @@ func (r *EmulatorDeviceReconciler) Reconcile(reqLogger logr.Logger, instance *an
 	defer sess.Close()
 	if complete, err := sess.BootCompleted(); err != nil {
 		if strings.Contains(err.Error(), "device offline") {
-			if err := resetDeviceAnnotations(r.client, instance); err != nil {
-				return err
-			}
-			return errors.NewRequeueError("ADB needs some time to catch up...", 3)
+			r.recorder.Event(instance, corev1.EventTypeWarning, "DeviceOffline", "The device is offline to ADB")
+			setDeviceNotReady(status, "DeviceOffline", "The device is offline to ADB")
+			return errors.NewRequeueError("ADBNotReady", "ADB needs some time to catch up...", 3)
 		}
 		reqLogger.Error(err, "Unhandled ADB error while checking boot status")
-		return errors.NewRequeueError("ADB needs some time to catch up...", 3)
+		return errors.NewRequeueError("ADBNotReady", "ADB needs some time to catch up...", 3)
 	} else if !complete {
-		if err := resetDeviceAnnotations(r.client, instance); err != nil {
-			return err
+		if status.BootStartedAt == nil {
+			now := metav1.Now()
+			status.BootStartedAt = &now
 		}
-		return errors.NewRequeueError("Device is still booting", 3)
+		setDeviceNotReady(status, "Booting", "The device is still booting")
+		return errors.NewRequeueError("DeviceBooting", "Device is still booting", 3)
 	}
 
 	// mark device as booted
-	if instance.GetAnnotations() == nil {
-		instance.Annotations = make(map[string]string)
-	}
-	if booted, ok := instance.Annotations[androidv1alpha1.BootCompletedAnnotation]; !ok || booted != "true" {
-		instance.Annotations[androidv1alpha1.BootCompletedAnnotation] = "true"
-		if err := r.client.Update(context.TODO(), instance); err != nil {
-			return err
+	now := metav1.Now()
+	if !status.GetCondition(androidv1alpha1.DeviceBootCompleted).IsTrue() {
+		status.SetCondition(androidv1alpha1.NewCondition(
+			androidv1alpha1.DeviceBootCompleted, corev1.ConditionTrue, "BootCompleted", "The device has finished booting",
+		))
+		if status.BootCompletedAt == nil {
+			status.BootCompletedAt = &now
+			bootDuration := now.Sub(found.GetCreationTimestamp().Time)
+			metrics.ObserveBootDuration(instance, bootDuration)
+			r.recorder.Eventf(instance, corev1.EventTypeNormal, "BootCompleted", "The device finished booting %s after its pod was created", bootDuration.Round(time.Second))
 		}
-		return errors.NewRequeueError("Marking device as finished booting and requeueing", 1)
+	}
+	// only record health checks periodically, so we don't write the status on
+	// every reconcile.
+	if status.LastHealthCheck == nil || now.Sub(status.LastHealthCheck.Time) > healthCheckRecordInterval {
+		status.LastHealthCheck = &now
 	}
 
 	// Check if we are binding this device to an ADB server
-	if err := reconcileSTFBinding(reqLogger, r.client, instance, found); err != nil {
+	if err := reconcileSTFBinding(reqLogger, r.client, r.recorder, instance, found, status); err != nil {
 		return err
 	}
 
+	if policy != nil {
+		return requeueForHealthCheck(policy.GetInterval())
+	}
 	return nil
 }
 
-// resetDeviceAnnotations sets all boot/adb status annotations to false for a device,
-// and then updates the remote state if necessary.
-func resetDeviceAnnotations(c client.Client, device *androidv1alpha1.AndroidDevice) error {
-	annotations := device.GetAnnotations()
-	if annotations == nil {
+// migrateLegacyAnnotations seeds the status of a device from the annotations
+// earlier versions of the operator tracked it with, and removes them. Without
+// the config checksum, the device would never be updated by its farm, and
+// without the serial it could not be removed from STF when it is deleted. Boot
+// completion is not seeded, since it is checked again on the next reconcile.
+// The status is written before the annotations are removed, so the state is not
+// lost if either update fails.
+func (r *EmulatorDeviceReconciler) migrateLegacyAnnotations(instance *androidv1alpha1.AndroidDevice, status *androidv1alpha1.AndroidDeviceStatus) error {
+	annotations := instance.GetAnnotations()
+	legacy := []string{
+		androidv1alpha1.LegacyADBConnectedAnnotation,
+		androidv1alpha1.LegacyBootCompletedAnnotation,
+		androidv1alpha1.LegacyDeviceConfigSHAAnnotation,
+		androidv1alpha1.LegacyProviderSerialAnnotation,
+	}
+	var found bool
+	for _, key := range legacy {
+		if _, ok := annotations[key]; ok {
+			found = true
+		}
+	}
+	if !found {
 		return nil
 	}
-	var changed bool
-	if adbConnected, ok := annotations[androidv1alpha1.ADBConnectedAnnotation]; ok {
-		if adbConnected == "true" {
-			annotations[androidv1alpha1.ADBConnectedAnnotation] = "false"
-			changed = true
+
+	if status.ConfigChecksum == "" {
+		status.ConfigChecksum = annotations[androidv1alpha1.LegacyDeviceConfigSHAAnnotation]
+	}
+	if serial := annotations[androidv1alpha1.LegacyProviderSerialAnnotation]; serial != "" && status.ADBSerial == "" {
+		status.ADBSerial = serial
+		if annotations[androidv1alpha1.LegacyADBConnectedAnnotation] == "true" {
+			status.SetCondition(androidv1alpha1.NewCondition(
+				androidv1alpha1.DeviceSTFBound, corev1.ConditionTrue, "Connected", "The device was connected to its STF provider",
+			))
 		}
 	}
-	if bootCompleted, ok := annotations[androidv1alpha1.BootCompletedAnnotation]; ok {
-		if bootCompleted == "true" {
-			annotations[androidv1alpha1.BootCompletedAnnotation] = "false"
-			changed = true
+	if err := updateDeviceStatus(r.client, instance, status); err != nil {
+		return err
+	}
+
+	for _, key := range legacy {
+		delete(annotations, key)
+	}
+	instance.SetAnnotations(annotations)
+	return r.client.Update(context.TODO(), instance)
+}
+
+// healthCheckRecordInterval is the minimum amount of time between updates to
+// the last health check time in a device's status.
+var healthCheckRecordInterval = time.Minute
+
+// resetDeviceStatus clears all boot and connection state from a device status.
+// This is used when a new pod is created for the device.
+func resetDeviceStatus(status *androidv1alpha1.AndroidDeviceStatus, reason, msg string) {
+	status.PodIP = ""
+	status.ADBSerial = ""
+	status.BootStartedAt = nil
+	status.BootCompletedAt = nil
+	setDeviceNotReady(status, reason, msg)
+}
+
+// setDeviceNotReady marks the device as not booted and not connected to its
+// STF provider.
+func setDeviceNotReady(status *androidv1alpha1.AndroidDeviceStatus, reason, msg string) {
+	status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceBootCompleted, corev1.ConditionFalse, reason, msg))
+	status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceSTFBound, corev1.ConditionFalse, reason, msg))
+}
+
+// devicePhase computes the phase of a device from its observed status.
+func devicePhase(status *androidv1alpha1.AndroidDeviceStatus) androidv1alpha1.DevicePhase {
+	switch {
+	case status.Phase == androidv1alpha1.DeviceTerminating:
+		return androidv1alpha1.DeviceTerminating
+	case status.GetCondition(androidv1alpha1.DeviceBootCompleted).IsTrue():
+		if status.GetCondition(androidv1alpha1.DeviceSTFBound).IsTrue() {
+			return androidv1alpha1.DeviceBound
 		}
+		return androidv1alpha1.DeviceReady
+	case status.BootCompletedAt != nil:
+		return androidv1alpha1.DeviceDegraded
+	case status.PodIP != "":
+		return androidv1alpha1.DeviceBooting
+	default:
+		return androidv1alpha1.DevicePending
 	}
-	if changed {
-		device.SetAnnotations(annotations)
-		return c.Update(context.TODO(), device)
+}
+
+// updateDeviceStatus sets the phase on the given status and writes it to the
+// status subresource of the device if it has changed.
+func updateDeviceStatus(c client.Client, device *androidv1alpha1.AndroidDevice, status *androidv1alpha1.AndroidDeviceStatus) error {
+	status.Phase = devicePhase(status)
+	if reflect.DeepEqual(*status, device.Status) {
+		return nil
 	}
-	return nil
+	device.Status = *status
+	return c.Status().Update(context.TODO(), device)
 }
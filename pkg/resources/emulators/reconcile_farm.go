@@ -5,7 +5,10 @@ import (
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
 	"github.com/tinyzimmer/android-farm-operator/pkg/resources"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -13,77 +16,118 @@ import (
 type EmulatorFarmReconciler struct {
 	resources.FarmReconciler
 
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 var _ resources.FarmReconciler = &EmulatorFarmReconciler{}
 var _ resources.DeviceReconciler = &EmulatorDeviceReconciler{}
 
-// NewForFarm returns a new reconciler for an AndroidFarm
-func NewForFarm(c client.Client, s *runtime.Scheme) resources.FarmReconciler {
-	return &EmulatorFarmReconciler{client: c, scheme: s}
+// NewForFarm returns a new reconciler for an AndroidFarm. Devices created,
+// updated and removed are recorded as events with the given recorder.
+func NewForFarm(c client.Client, s *runtime.Scheme, recorder record.EventRecorder) resources.FarmReconciler {
+	return &EmulatorFarmReconciler{client: c, scheme: s, recorder: recorder}
 }
 
 // Reconcile will reconcile the desired state of the devices for an AndroidFarm
 // with what is running in the cluster.
 func (r *EmulatorFarmReconciler) Reconcile(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm) error {
-	// Iterate device farms and reconcile devices
+	// Iterate device farms and reconcile devices, keeping track of the number
+	// of devices desired in each group.
+	replicas := make(map[string]int32)
 	for _, group := range instance.DeviceGroups() {
 		reqLogger.Info("Reconciling device group")
 		// If it's an emulated device group, reconcile the emulator devices
 		if group.IsEmulatedGroup() {
 			logger := reqLogger.WithValues("Group", group.Name, "Namespace", group.GetNamespace())
 			logger.Info("Device group has an emulated device configuration, reconciling devices")
-			if err := r.ReconcileEmulatedDeviceGroup(logger, instance, group); err != nil {
+			count, err := r.ReconcileEmulatedDeviceGroup(logger, instance, group)
+			if err != nil {
 				return err
 			}
+			replicas[group.Name] = count
 		}
 	}
 
 	// Run garbage collection on the farm
-	return runGC(reqLogger, r.client, instance)
+	return runGC(reqLogger, r.client, r.recorder, instance, replicas)
 }
 
-func (r *EmulatorFarmReconciler) ReconcileEmulatedDeviceGroup(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) error {
-	if group.GetCount() == 0 {
+// ReconcileEmulatedDeviceGroup reconciles the devices for an emulated device group
+// and returns the number of devices desired in the group.
+func (r *EmulatorFarmReconciler) ReconcileEmulatedDeviceGroup(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) (int32, error) {
+	// Ensure the AndroidDeviceGroup used for scaling the group
+	deviceGroup, err := util.ReconcileDeviceGroup(reqLogger, r.client, newDeviceGroupForFarmGroup(instance, group), group.GetCount())
+	if err != nil {
+		return 0, err
+	}
+	count := deviceGroup.DesiredReplicas(group)
+	if count != deviceGroup.Spec.Replicas {
+		reqLogger.Info("Device group replicas are out of bounds, limiting", "Replicas", deviceGroup.Spec.Replicas, "MaxCount", group.GetMaxCount())
+	}
+
+	if count == 0 {
 		reqLogger.Info("Device group has 0 devices, skipping")
-		return nil
+		return 0, nil
 	}
 
 	// Get the config
 	config, err := group.GetConfig(r.client)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// get the config's checksum
 	checksum, err := config.Checksum()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	reqLogger.Info("Calculated config checksum for device group", "Checksum", checksum)
 
 	// Create a headless service for DNS resolution
 	svc := serviceForDeviceGroup(instance, group, config)
 	if err := util.ReconcileService(reqLogger, r.client, svc); err != nil {
-		return err
+		return 0, err
 	}
 
-	// Create devices for the group
-	for i := int32(0); i < group.GetCount(); i++ {
+	// Observe the rollout of the current configuration, extra devices are run
+	// above the desired count while a rolling update can surge.
+	rollout, err := newGroupRollout(reqLogger, r.client, r.recorder, instance, group, checksum, count)
+	if err != nil {
+		return 0, err
+	}
+	total := count + rollout.surge()
+
+	// Create devices for the group. Devices waiting to be updated don't stop the
+	// rest of the group from being reconciled.
+	var waitErr error
+	for i := int32(0); i < total; i++ {
 		// check if we are enforcing concurrency
-		if policy := instance.GetDeviceManagementPolicy(group.Name); policy != nil {
+		if policy := instance.GetDeviceManagementPolicy(group.Name); policy != nil && policy.GetPodManagementPolicy() == androidv1alpha1.GroupedOrderedReady {
 			if err := groupIndexReadyToCreate(r.client, group, policy, i); err != nil {
-				return err
+				return 0, err
 			}
 		}
 		// Define a new Device object
 		reqLogger.Info("Reconciling emulator device for device farm", "Group", group.Name, "PodNumber", i)
-		device := newEmulatedDeviceForFarmGroup(reqLogger, instance, i, group, checksum)
-		if err := util.ReconcileDevice(reqLogger, r.client, device, groupIndexReadyToUpdateFunc(reqLogger, r.client, instance, group, i)); err != nil {
-			return err
+		device := newEmulatedDeviceForFarmGroup(reqLogger, instance, i, group)
+		created, err := util.ReconcileDevice(reqLogger, r.client, device, checksum, rollout.checkUpdateFunc(i))
+		if err != nil {
+			if _, ok := errors.IsRequeueError(err); ok {
+				if waitErr == nil {
+					waitErr = err
+				}
+				continue
+			}
+			return 0, err
 		}
+		if created {
+			r.recorder.Eventf(device, corev1.EventTypeNormal, "Created", "Created for group %s of AndroidFarm %s", group.Name, instance.Name)
+		}
+	}
+	if waitErr != nil {
+		return 0, waitErr
 	}
-	return nil
+	return total, nil
 }
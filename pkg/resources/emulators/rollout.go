@@ -0,0 +1,135 @@
+package emulators
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// groupRollout tracks the rollout of the current configuration of an emulated
+// device group to its devices over the course of a single reconcile.
+type groupRollout struct {
+	reqLogger logr.Logger
+	client    client.Client
+	recorder  record.EventRecorder
+	farm      *androidv1alpha1.AndroidFarm
+	group     *androidv1alpha1.DeviceGroup
+	policy    *androidv1alpha1.DeviceManagementPolicy
+	// the number of devices desired in the group
+	count int32
+	// the number of devices that are booted and not being replaced
+	available int32
+	// whether any device that is allowed to be updated runs an outdated configuration
+	outdated bool
+}
+
+// newGroupRollout observes the devices of a group and returns a rollout for
+// updating them to the configuration with the given checksum.
+func newGroupRollout(reqLogger logr.Logger, c client.Client, recorder record.EventRecorder, farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, checksum string, count int32) (*groupRollout, error) {
+	rollout := &groupRollout{
+		reqLogger: reqLogger,
+		client:    c,
+		recorder:  recorder,
+		farm:      farm,
+		group:     group,
+		policy:    farm.GetDeviceManagementPolicy(group.Name),
+		count:     count,
+	}
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := c.List(context.TODO(), devices, client.InNamespace(group.GetNamespace()), client.MatchingLabels{
+		androidv1alpha1.DeviceFarmLabel:  farm.Name,
+		androidv1alpha1.DeviceGroupLabel: group.Name,
+	}); err != nil {
+		return nil, err
+	}
+	for _, device := range devices.Items {
+		if device.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if device.BootCompleted() {
+			rollout.available++
+		}
+		idx, err := util.DeviceIndex(device.Name)
+		if err != nil {
+			return nil, err
+		}
+		if idx >= count || device.IsLeased() || (rollout.policy != nil && rollout.policy.HoldsUpdate(idx)) {
+			continue
+		}
+		if device.ConfigChecksum() != "" && device.ConfigChecksum() != checksum {
+			rollout.outdated = true
+		}
+	}
+	return rollout, nil
+}
+
+// surge returns the number of extra devices to run above the desired count
+// while outdated devices are replaced.
+func (r *groupRollout) surge() int32 {
+	if r.policy == nil || r.policy.GetPodManagementPolicy() != androidv1alpha1.RollingUpdate || r.policy.Paused || !r.outdated {
+		return 0
+	}
+	surge := r.policy.GetMaxSurge(r.count)
+	if headroom := r.group.GetMaxCount() - r.count; surge > headroom {
+		surge = headroom
+	}
+	if surge < 0 {
+		return 0
+	}
+	return surge
+}
+
+// checkUpdateFunc returns a function used by ReconcileDevice to decide if the
+// device at the given index can be updated now, according to the management
+// policy of the group. Each device that is let through is recorded as a step
+// of the rollout on the farm.
+func (r *groupRollout) checkUpdateFunc(devidx int32) func(*androidv1alpha1.AndroidDevice, string) (bool, error) {
+	check := r.policyCheckFunc(devidx)
+	return func(found *androidv1alpha1.AndroidDevice, newChecksum string) (bool, error) {
+		cont, err := check(found, newChecksum)
+		if cont {
+			r.recorder.Eventf(r.farm, corev1.EventTypeNormal, "RolloutStep", "Replacing device %s in group %s with configuration %s", found.Name, r.group.Name, newChecksum)
+		}
+		return cont, err
+	}
+}
+
+// policyCheckFunc returns a function that decides if the device at the given
+// index can be updated now, according to the management policy of the group.
+func (r *groupRollout) policyCheckFunc(devidx int32) func(*androidv1alpha1.AndroidDevice, string) (bool, error) {
+	return func(found *androidv1alpha1.AndroidDevice, newChecksum string) (bool, error) {
+		// If there is no management policy for this group, update immediately
+		if r.policy == nil {
+			return true, nil
+		}
+		if r.policy.HoldsUpdate(devidx) {
+			r.reqLogger.Info("Rollout is paused or device is below the partition, not updating", "Device.Name", found.Name, "Partition", r.policy.Partition, "Paused", r.policy.Paused)
+			return false, nil
+		}
+		switch r.policy.GetPodManagementPolicy() {
+		case androidv1alpha1.Parallel:
+			return true, nil
+		case androidv1alpha1.RollingUpdate:
+			// replacing a device that is not booted does not affect availability
+			if !found.BootCompleted() {
+				return true, nil
+			}
+			if r.available <= r.count-r.policy.GetMaxUnavailable(r.count) {
+				return false, errors.NewRequeueError("RolloutWaiting", "Waiting for devices to become available before updating more", 5)
+			}
+			r.available--
+			return true, nil
+		default:
+			if !groupIndexReadyToUpdateFunc(r.reqLogger, r.client, r.farm, r.group, devidx)(newChecksum) {
+				return false, errors.NewRequeueError("DeviceNotReady", "Device is not ready to be updated", 3)
+			}
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package emulators
+
+import (
+	"fmt"
+	"testing"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	testNamespace   = "default"
+	testGroup       = "group"
+	currentChecksum = "current"
+	oldChecksum     = "old"
+)
+
+// testDevice returns a device of the test group at the given index.
+func testDevice(idx int, checksum string, booted bool) *androidv1alpha1.AndroidDevice {
+	device := &androidv1alpha1.AndroidDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", testGroup, util.DeviceIntToString(idx)),
+			Namespace: testNamespace,
+		},
+		Status: androidv1alpha1.AndroidDeviceStatus{ConfigChecksum: checksum},
+	}
+	if booted {
+		device.Status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceBootCompleted, corev1.ConditionTrue, "BootCompleted", ""))
+	}
+	return device
+}
+
+// leased marks a device as held by a lease.
+func leased(device *androidv1alpha1.AndroidDevice) *androidv1alpha1.AndroidDevice {
+	device.Annotations = map[string]string{androidv1alpha1.LeaseAnnotation: "lease"}
+	return device
+}
+
+// testFarm returns a farm with a single emulated group using the given policy.
+func testFarm(policy *androidv1alpha1.DeviceManagementPolicy) (*androidv1alpha1.AndroidFarm, *androidv1alpha1.DeviceGroup) {
+	group := &androidv1alpha1.DeviceGroup{
+		Name: testGroup,
+		Emulators: &androidv1alpha1.EmulatorConfig{
+			Namespace:              testNamespace,
+			DeviceManagementPolicy: policy,
+		},
+	}
+	farm := &androidv1alpha1.AndroidFarm{
+		ObjectMeta: metav1.ObjectMeta{Name: "farm"},
+		Spec: androidv1alpha1.AndroidFarmSpec{
+			DeviceGroups: []*androidv1alpha1.DeviceGroup{group},
+		},
+	}
+	return farm, group
+}
+
+// testClient returns a fake client serving the given devices.
+func testClient(t *testing.T, devices ...*androidv1alpha1.AndroidDevice) client.Client {
+	s := runtime.NewScheme()
+	if err := androidv1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	objs := make([]runtime.Object, len(devices))
+	for i, device := range devices {
+		objs[i] = device
+	}
+	return fake.NewFakeClientWithScheme(s, objs...)
+}
+
+func intOrString(val intstr.IntOrString) *intstr.IntOrString { return &val }
+
+func TestGroupRolloutSurge(t *testing.T) {
+	rolling := func(maxSurge intstr.IntOrString) *androidv1alpha1.DeviceManagementPolicy {
+		return &androidv1alpha1.DeviceManagementPolicy{
+			PodManagementPolicy: androidv1alpha1.RollingUpdate,
+			RollingUpdate:       &androidv1alpha1.RollingUpdateConfig{MaxSurge: intOrString(maxSurge)},
+		}
+	}
+	paused := rolling(intstr.FromInt(2))
+	paused.Paused = true
+
+	tests := []struct {
+		name     string
+		policy   *androidv1alpha1.DeviceManagementPolicy
+		count    int32
+		maxCount int32
+		outdated bool
+		want     int32
+	}{
+		{name: "no policy", count: 3, maxCount: 5, outdated: true, want: 0},
+		{name: "grouped ordered ready", policy: &androidv1alpha1.DeviceManagementPolicy{}, count: 3, maxCount: 5, outdated: true, want: 0},
+		{name: "up to date", policy: rolling(intstr.FromInt(2)), count: 3, maxCount: 5, want: 0},
+		{name: "paused", policy: paused, count: 3, maxCount: 5, outdated: true, want: 0},
+		{name: "absolute surge", policy: rolling(intstr.FromInt(2)), count: 3, maxCount: 5, outdated: true, want: 2},
+		{name: "percent surge rounds up", policy: rolling(intstr.FromString("50%")), count: 3, maxCount: 10, outdated: true, want: 2},
+		{name: "surge limited to max count", policy: rolling(intstr.FromString("50%")), count: 3, maxCount: 4, outdated: true, want: 1},
+		{name: "no headroom", policy: rolling(intstr.FromInt(2)), count: 3, maxCount: 3, outdated: true, want: 0},
+		{name: "scaled above max count", policy: rolling(intstr.FromInt(2)), count: 6, maxCount: 5, outdated: true, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rollout := &groupRollout{
+				group: &androidv1alpha1.DeviceGroup{
+					Name:      testGroup,
+					Emulators: &androidv1alpha1.EmulatorConfig{Count: tt.count, MaxCount: tt.maxCount},
+				},
+				policy:   tt.policy,
+				count:    tt.count,
+				outdated: tt.outdated,
+			}
+			if got := rollout.surge(); got != tt.want {
+				t.Errorf("surge() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckFunc(t *testing.T) {
+	rolling := &androidv1alpha1.DeviceManagementPolicy{
+		PodManagementPolicy: androidv1alpha1.RollingUpdate,
+		RollingUpdate:       &androidv1alpha1.RollingUpdateConfig{MaxUnavailable: intOrString(intstr.FromInt(1))},
+	}
+
+	tests := []struct {
+		name          string
+		policy        *androidv1alpha1.DeviceManagementPolicy
+		devices       []*androidv1alpha1.AndroidDevice
+		available     int32
+		idx           int32
+		found         *androidv1alpha1.AndroidDevice
+		want          bool
+		wantRequeue   string
+		wantAvailable int32
+	}{
+		{
+			name:  "no policy",
+			idx:   1,
+			found: testDevice(1, oldChecksum, true),
+			want:  true,
+		},
+		{
+			name:   "paused",
+			policy: &androidv1alpha1.DeviceManagementPolicy{PodManagementPolicy: androidv1alpha1.Parallel, Paused: true},
+			idx:    1,
+			found:  testDevice(1, oldChecksum, true),
+			want:   false,
+		},
+		{
+			name:   "below partition",
+			policy: &androidv1alpha1.DeviceManagementPolicy{PodManagementPolicy: androidv1alpha1.Parallel, Partition: 2},
+			idx:    1,
+			found:  testDevice(1, oldChecksum, true),
+			want:   false,
+		},
+		{
+			name:   "at partition",
+			policy: &androidv1alpha1.DeviceManagementPolicy{PodManagementPolicy: androidv1alpha1.Parallel, Partition: 2},
+			idx:    2,
+			found:  testDevice(2, oldChecksum, true),
+			want:   true,
+		},
+		{
+			name:          "rolling update within max unavailable",
+			policy:        rolling,
+			available:     3,
+			idx:           1,
+			found:         testDevice(1, oldChecksum, true),
+			want:          true,
+			wantAvailable: 2,
+		},
+		{
+			name:          "rolling update at max unavailable",
+			policy:        rolling,
+			available:     2,
+			idx:           1,
+			found:         testDevice(1, oldChecksum, true),
+			want:          false,
+			wantRequeue:   "RolloutWaiting",
+			wantAvailable: 2,
+		},
+		{
+			name:          "rolling update of a device that is not booted",
+			policy:        rolling,
+			available:     2,
+			idx:           1,
+			found:         testDevice(1, oldChecksum, false),
+			want:          true,
+			wantAvailable: 2,
+		},
+		{
+			name:    "grouped ordered ready with earlier devices updated",
+			policy:  &androidv1alpha1.DeviceManagementPolicy{},
+			devices: []*androidv1alpha1.AndroidDevice{testDevice(0, currentChecksum, true)},
+			idx:     1,
+			found:   testDevice(1, oldChecksum, true),
+			want:    true,
+		},
+		{
+			name:        "grouped ordered ready with earlier devices outdated",
+			policy:      &androidv1alpha1.DeviceManagementPolicy{},
+			devices:     []*androidv1alpha1.AndroidDevice{testDevice(0, oldChecksum, true)},
+			idx:         1,
+			found:       testDevice(1, oldChecksum, true),
+			want:        false,
+			wantRequeue: "DeviceNotReady",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			farm, group := testFarm(tt.policy)
+			rollout := &groupRollout{
+				reqLogger: logf.Log,
+				client:    testClient(t, tt.devices...),
+				farm:      farm,
+				group:     group,
+				policy:    tt.policy,
+				count:     3,
+				available: tt.available,
+			}
+			got, err := rollout.policyCheckFunc(tt.idx)(tt.found, currentChecksum)
+			if got != tt.want {
+				t.Errorf("update allowed = %v, want %v", got, tt.want)
+			}
+			if tt.wantRequeue == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if tt.wantRequeue != "" {
+				if requeue, ok := errors.IsRequeueError(err); !ok || requeue.Reason() != tt.wantRequeue {
+					t.Errorf("error = %v, want requeue with reason %s", err, tt.wantRequeue)
+				}
+			}
+			if rollout.available != tt.wantAvailable {
+				t.Errorf("available = %d, want %d", rollout.available, tt.wantAvailable)
+			}
+		})
+	}
+}
@@ -6,18 +6,22 @@ import (
 
 	"github.com/go-logr/logr"
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/metrics"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // reconcileSTFBinding will ensure a job is run that binds a freshly booted
-// emulator to its stf provider.
-func reconcileSTFBinding(reqLogger logr.Logger, c client.Client, device *androidv1alpha1.AndroidDevice, pod *corev1.Pod) error {
+// emulator to its stf provider. The connection state is recorded in the given
+// device status.
+func reconcileSTFBinding(reqLogger logr.Logger, c client.Client, recorder record.EventRecorder, device *androidv1alpha1.AndroidDevice, pod *corev1.Pod, status *androidv1alpha1.AndroidDeviceStatus) error {
 	// If no adb server annotation, screw it
 	if device.GetAnnotations() == nil {
 		return nil
@@ -28,10 +32,8 @@ func reconcileSTFBinding(reqLogger logr.Logger, c client.Client, device *android
 	}
 
 	// check if connected already
-	if connected, ok := device.Annotations[androidv1alpha1.ADBConnectedAnnotation]; ok {
-		if connected == "true" {
-			return nil
-		}
+	if status.GetCondition(androidv1alpha1.DeviceSTFBound).IsTrue() {
+		return nil
 	}
 
 	// fetch the farm so we can use details about it
@@ -49,6 +51,10 @@ func reconcileSTFBinding(reqLogger logr.Logger, c client.Client, device *android
 
 	// ReconcileJob with true requeues until the job is finished
 	if err := util.ReconcileJob(reqLogger, c, job, true); err != nil {
+		if _, ok := errors.IsRequeueError(err); ok {
+			// a job that has given up is removed so the binding is retried
+			return checkSTFBindingFailed(reqLogger, c, recorder, device, job, status, err)
+		}
 		return err
 	}
 
@@ -57,24 +63,76 @@ func reconcileSTFBinding(reqLogger logr.Logger, c client.Client, device *android
 	if err := c.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, completedjob); err != nil {
 		return err
 	}
+	if completedjob.Status.CompletionTime != nil {
+		metrics.ObserveSTFBindDuration(device, completedjob.Status.CompletionTime.Sub(completedjob.GetCreationTimestamp().Time))
+	}
+	deleteADBMonJob(reqLogger, c, completedjob)
+
+	status.ADBSerial = podSerial
+	recorder.Eventf(device, corev1.EventTypeNormal, "STFBound", "The device is connected to %s as %s", adbServer, podSerial)
+	status.SetCondition(androidv1alpha1.NewCondition(
+		androidv1alpha1.DeviceSTFBound, corev1.ConditionTrue, "Connected",
+		fmt.Sprintf("The device is connected to %s", adbServer),
+	))
+	return nil
+}
+
+// checkSTFBindingFailed checks if the binding job for a device has failed. If
+// it has, the failure is recorded and the job is removed so that it is created
+// again on the next reconcile. The given requeue error is returned either way.
+func checkSTFBindingFailed(reqLogger logr.Logger, c client.Client, recorder record.EventRecorder, device *androidv1alpha1.AndroidDevice, job *batchv1.Job, status *androidv1alpha1.AndroidDeviceStatus, requeue error) error {
+	found := &batchv1.Job{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, found); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return requeue
+	}
+	for _, cond := range found.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			reqLogger.Info("STF binding job failed, removing it to retry", "Job.Name", found.Name, "Reason", cond.Reason)
+			metrics.RecordSTFBindFailure(device)
+			recorder.Eventf(device, corev1.EventTypeWarning, "STFBindFailed", "The job binding the device to %s failed: %s", device.Annotations[androidv1alpha1.STFProviderAnnotation], cond.Message)
+			deleteADBMonJob(reqLogger, c, found)
+			status.SetCondition(androidv1alpha1.NewCondition(
+				androidv1alpha1.DeviceSTFBound, corev1.ConditionFalse, "BindFailed", cond.Message,
+			))
+			return errors.NewRequeueError("STFBindFailed", "STF binding job failed, retrying", 5)
+		}
+	}
+	return requeue
+}
+
+// reconcileSTFUnbinding runs a job that disconnects the given serial from the
+// ADB server of a device's stf provider, along with removing any binding job
+// left behind for the device pod. The request is requeued until the job has
+// finished.
+func reconcileSTFUnbinding(reqLogger logr.Logger, c client.Client, farm *androidv1alpha1.AndroidFarm, pod *corev1.Pod, adbServer, podSerial string) error {
+	deleteADBMonJob(reqLogger, c, newSTFBindingJob(farm, pod, adbServer, podSerial))
+
+	job := newSTFUnbindingJob(farm, pod, adbServer, podSerial)
+	if err := util.ReconcileJob(reqLogger, c, job, true); err != nil {
+		return err
+	}
+	deleteADBMonJob(reqLogger, c, job)
+	return nil
+}
 
-	// We can delete the job, and fallback to the TTL if we fail
-	if err := c.Delete(context.TODO(), completedjob); err != nil {
+// deleteADBMonJob deletes an adbmon job and its pods. We fallback to the TTL
+// if this fails.
+func deleteADBMonJob(reqLogger logr.Logger, c client.Client, job *batchv1.Job) {
+	if err := c.Delete(context.TODO(), job); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			reqLogger.Info("Could not clean up job, hopefully ttl will catch it")
 		}
 	}
 
 	// also clean up pods
-	if err := c.DeleteAllOf(context.TODO(), &corev1.Pod{}, client.InNamespace(completedjob.Namespace), client.MatchingLabels{"job-name": completedjob.Name}); err != nil {
+	if err := c.DeleteAllOf(context.TODO(), &corev1.Pod{}, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			reqLogger.Info("Could not clean up job pod(s), hopefully ttl will catch it")
 		}
 	}
-
-	device.Annotations[androidv1alpha1.ADBConnectedAnnotation] = "true"
-	device.Annotations[androidv1alpha1.ProviderSerialAnnotation] = podSerial
-	return c.Update(context.TODO(), device)
 }
 
 func getPodAddr(pod *corev1.Pod) string {
@@ -91,9 +149,21 @@ var jobTTL int32 = 600
 // Note that TTL seconds after completion is not respected properly by all
 // kubernetes versions.
 func newSTFBindingJob(cr *androidv1alpha1.AndroidFarm, device *corev1.Pod, adbServer, podSerial string) *batchv1.Job {
+	return newADBMonJob(cr, device, "stf-connect", []string{"--host", adbServer, "--connect", podSerial, "--verbose"})
+}
+
+// newSTFUnbindingJob returns a new job definition for disconnecting an emulator
+// from its stf provider.
+func newSTFUnbindingJob(cr *androidv1alpha1.AndroidFarm, device *corev1.Pod, adbServer, podSerial string) *batchv1.Job {
+	return newADBMonJob(cr, device, "stf-disconnect", []string{"--host", adbServer, "--disconnect", podSerial, "--verbose"})
+}
+
+// newADBMonJob returns a job definition running adbmon with the given arguments
+// on behalf of an emulator pod.
+func newADBMonJob(cr *androidv1alpha1.AndroidFarm, device *corev1.Pod, name string, args []string) *batchv1.Job {
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-stf-connect", device.GetName()),
+			Name:      fmt.Sprintf("%s-%s", device.GetName(), name),
 			Namespace: cr.STFConfig().GetNamespace(),
 			Labels:    device.GetLabels(),
 		},
@@ -109,10 +179,10 @@ func newSTFBindingJob(cr *androidv1alpha1.AndroidFarm, device *corev1.Pod, adbSe
 					RestartPolicy:      "OnFailure",
 					Containers: []corev1.Container{
 						{
-							Name:            "stf-connect",
+							Name:            name,
 							Image:           "quay.io/tinyzimmer/adbmon",
 							ImagePullPolicy: "IfNotPresent",
-							Args:            []string{"--host", adbServer, "--connect", podSerial, "--verbose"},
+							Args:            args,
 							SecurityContext: cr.STFConfig().ContainerSecurityContext(),
 							Resources: corev1.ResourceRequirements{
 								Limits: corev1.ResourceList{
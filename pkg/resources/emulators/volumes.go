@@ -6,6 +6,8 @@ import (
 
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
 	"github.com/go-logr/logr"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	operrors "github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,15 +15,39 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// appendKVMVolume attaches the kvm device to an emulator pod
+// RequireKVMNodeLabel is set when the KVM discovery daemon is running in the
+// cluster. Emulators that use KVM are then only scheduled to nodes it has
+// labeled as KVM capable.
+var RequireKVMNodeLabel = false
+
+// kvmNodeSelector returns the node selector for emulator pods using the given
+// configuration. When KVM node labels are required and the configuration uses
+// KVM, the KVM label is merged into the configured selector.
+func kvmNodeSelector(conf *androidv1alpha1.AndroidDeviceConfig) map[string]string {
+	selector := conf.GetNodeSelector()
+	if !RequireKVMNodeLabel || !conf.IsKVMEnabled() {
+		return selector
+	}
+	merged := map[string]string{androidv1alpha1.KVMNodeLabel: "true"}
+	for k, v := range selector {
+		merged[k] = v
+	}
+	return merged
+}
+
+// appendKVMVolume attaches the kvm device to an emulator pod. The pod fails to
+// start if the node does not have the device, instead of a directory being
+// created in its place.
 // TODO: This could be done through getters in the API
 func appendKVMVolume(namePrefix string, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) ([]corev1.Volume, []corev1.VolumeMount) {
 	volName := fmt.Sprintf("%s-kvm", namePrefix)
+	hostPathType := corev1.HostPathCharDev
 	volumes = append(volumes, corev1.Volume{
 		Name: volName,
 		VolumeSource: corev1.VolumeSource{
 			HostPath: &corev1.HostPathVolumeSource{
 				Path: "/dev/kvm",
+				Type: &hostPathType,
 			},
 		},
 	})
@@ -51,30 +77,116 @@ func appendPVCToPod(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, vol andr
 }
 
 // reconcilePVCForPod will ensure a PVC for an emulator pod based off the user
-// provided spec.
+// provided spec, and applies the retention policy of the volume to it. The
+// request is requeued while the PVC is being replaced.
 // TODO: Make generic and move to util package
-func reconcilePVCForPod(reqLogger logr.Logger, c client.Client, pod *corev1.Pod, volume androidv1alpha1.Volume, labels map[string]string) (*corev1.PersistentVolumeClaim, error) {
+func reconcilePVCForPod(reqLogger logr.Logger, c client.Client, device *androidv1alpha1.AndroidDevice, pod *corev1.Pod, volume androidv1alpha1.Volume, checksum string) (*corev1.PersistentVolumeClaim, error) {
 	volName := fmt.Sprintf("%s%s", volume.VolumePrefix, pod.Name)
+	policy := volume.GetRetentionPolicy()
 	pvc := &corev1.PersistentVolumeClaim{}
 	if err := c.Get(context.TODO(), types.NamespacedName{Name: volName, Namespace: pod.Namespace}, pvc); err != nil {
-		if errors.IsNotFound(err) {
-			reqLogger.Info("Creating new PVC for emulator pod", "Pod.Name", pod.Name, "Pod.Namespace", pod.Namespace, "PVC.Name", volName)
-			pvc = &corev1.PersistentVolumeClaim{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      volName,
-					Namespace: pod.Namespace,
-					Labels:    labels,
-				},
-				Spec: volume.PVCSpec,
-			}
-			if err := c.Create(context.TODO(), pvc); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		reqLogger.Info("Creating new PVC for emulator pod", "Pod.Name", pod.Name, "Pod.Namespace", pod.Namespace, "PVC.Name", volName)
+		labels := make(map[string]string)
+		for k, v := range util.DeviceLabels(device) {
+			labels[k] = v
+		}
+		labels[androidv1alpha1.VolumeChecksumLabel] = shortChecksum(checksum)
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      volName,
+				Namespace: pod.Namespace,
+				Labels:    labels,
+			},
+			Spec: volume.PVCSpec,
+		}
+		if policy == androidv1alpha1.VolumeDelete {
+			pvc.OwnerReferences = []metav1.OwnerReference{deviceOwnerReference(device)}
+		}
+		if err := c.Create(context.TODO(), pvc); err != nil {
+			return nil, err
+		}
+		return pvc, nil
+	}
+
+	if pvc.GetDeletionTimestamp() != nil {
+		return nil, operrors.NewRequeueError("PVCTerminating", "Waiting for the previous PVC for the emulator pod to be removed", 3)
+	}
+
+	// replace the volume if it was created for another configuration, PVCs
+	// from before the policy was set are adopted instead. The volume is only
+	// wiped once the pod using it is gone, so devices the farm is holding on
+	// their current configuration keep their data. Leased devices are never
+	// wiped.
+	var wipePending bool
+	if last, ok := pvc.Labels[androidv1alpha1.VolumeChecksumLabel]; ok && last != shortChecksum(checksum) && policy == androidv1alpha1.VolumeWipeOnConfigChange {
+		found := &corev1.Pod{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, found)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		if err == nil || device.IsLeased() {
+			reqLogger.Info("Device configuration has changed, PVC will be wiped once the device pod is replaced", "Pod.Name", pod.Name, "Pod.Namespace", pod.Namespace, "PVC.Name", pvc.Name)
+			wipePending = true
+		} else {
+			reqLogger.Info("Device configuration has changed, wiping PVC for emulator pod", "Pod.Name", pod.Name, "Pod.Namespace", pod.Namespace, "PVC.Name", pvc.Name)
+			if err := c.Delete(context.TODO(), pvc); client.IgnoreNotFound(err) != nil {
 				return nil, err
 			}
+			return nil, operrors.NewRequeueError("PVCWiping", "Wiping PVC for the new device configuration", 3)
+		}
+	}
+
+	reqLogger.Info("Using existing PVC for emulator pod", "Pod.Name", pod.Name, "Pod.Namespace", pod.Namespace, "PVC.Name", pvc.Name)
+	var changed bool
+	if pvc.Labels == nil {
+		pvc.Labels = make(map[string]string)
+	}
+	// the checksum of a volume that is waiting to be wiped is kept, so it is
+	// still wiped when its pod is replaced
+	if !wipePending && pvc.Labels[androidv1alpha1.VolumeChecksumLabel] != shortChecksum(checksum) {
+		pvc.Labels[androidv1alpha1.VolumeChecksumLabel] = shortChecksum(checksum)
+		changed = true
+	}
+	// only PVCs that are deleted with the device are owned by it
+	if owned := metav1.IsControlledBy(pvc, device); owned != (policy == androidv1alpha1.VolumeDelete) {
+		if owned {
+			refs := make([]metav1.OwnerReference, 0)
+			for _, ref := range pvc.OwnerReferences {
+				if ref.UID != device.GetUID() {
+					refs = append(refs, ref)
+				}
+			}
+			pvc.OwnerReferences = refs
 		} else {
+			pvc.OwnerReferences = append(pvc.OwnerReferences, deviceOwnerReference(device))
+		}
+		changed = true
+	}
+	if changed {
+		if err := c.Update(context.TODO(), pvc); err != nil {
 			return nil, err
 		}
-	} else {
-		reqLogger.Info("Using existing PVC for emulator pod", "Pod.Name", pod.Name, "Pod.Namespace", pod.Namespace, "PVC.Name", pvc.Name)
 	}
 	return pvc, nil
 }
+
+// deviceOwnerReference returns a controller reference to the given device.
+func deviceOwnerReference(device *androidv1alpha1.AndroidDevice) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         device.APIVersion,
+		Kind:               device.Kind,
+		Name:               device.GetName(),
+		UID:                device.GetUID(),
+		Controller:         util.BoolPointer(true),
+		BlockOwnerDeletion: util.BoolPointer(true),
+	}
+}
+
+// shortChecksum returns a shortened configuration checksum that can be used in
+// label values and resource names.
+func shortChecksum(checksum string) string {
+	return checksum[:16]
+}
@@ -0,0 +1,142 @@
+package physical
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/inventory"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// invalidNameChars matches the characters in a serial that cannot be used in
+// the name of an AndroidDevice.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// physicalDeviceName returns the name of the AndroidDevice for a device with
+// the given serial in a device group.
+func physicalDeviceName(group *androidv1alpha1.DeviceGroup, serial string) string {
+	return fmt.Sprintf("%s-%s", group.Name, strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(serial), "-"), "-"))
+}
+
+// newPhysicalDeviceForFarmGroup returns an AndroidDevice for a device attached
+// to a provider of a host USB device group.
+func newPhysicalDeviceForFarmGroup(farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, provider, node, serial string) *androidv1alpha1.AndroidDevice {
+	labels := make(map[string]string)
+	for k, v := range util.DeviceFarmLabels(farm, group) {
+		labels[k] = v
+	}
+	if len(validation.IsValidLabelValue(node)) == 0 {
+		labels[androidv1alpha1.DeviceNodeLabel] = node
+	}
+	return &androidv1alpha1.AndroidDevice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      physicalDeviceName(group, serial),
+			Namespace: group.GetNamespace(),
+			Labels:    labels,
+			Annotations: map[string]string{
+				androidv1alpha1.STFProviderAnnotation: provider,
+			},
+			OwnerReferences: farm.OwnerReferences(),
+		},
+		Spec: androidv1alpha1.AndroidDeviceSpec{
+			Source: androidv1alpha1.PhysicalSource,
+		},
+	}
+}
+
+// reconcilePhysicalDevice ensures the AndroidDevice for a device attached to a
+// provider, and updates its status with the state reported in the inventory.
+// A device that moved to another node or provider is relabeled.
+func (r *PhysicalFarmReconciler) reconcilePhysicalDevice(reqLogger logr.Logger, farm *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, provider, node string, attached inventory.Device) (*androidv1alpha1.AndroidDevice, error) {
+	desired := newPhysicalDeviceForFarmGroup(farm, group, provider, node, attached.Serial)
+
+	device := &androidv1alpha1.AndroidDevice{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, device); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return nil, err
+		}
+		reqLogger.Info("Creating device for newly attached physical device", "Device.Name", desired.Name, "Serial", attached.Serial, "Node", node)
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, err
+		}
+		r.recorder.Eventf(desired, corev1.EventTypeNormal, "Attached", "Device %s was attached to node %s", attached.Serial, node)
+		device = desired
+	} else {
+		labels := device.GetLabels()
+		annotations := device.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if labels[androidv1alpha1.DeviceNodeLabel] != desired.Labels[androidv1alpha1.DeviceNodeLabel] || annotations[androidv1alpha1.STFProviderAnnotation] != provider {
+			reqLogger.Info("Physical device moved to another provider, updating", "Device.Name", device.Name, "Node", node, "Provider", provider)
+			if node := desired.Labels[androidv1alpha1.DeviceNodeLabel]; node != "" {
+				labels[androidv1alpha1.DeviceNodeLabel] = node
+			} else {
+				delete(labels, androidv1alpha1.DeviceNodeLabel)
+			}
+			annotations[androidv1alpha1.STFProviderAnnotation] = provider
+			device.SetLabels(labels)
+			device.SetAnnotations(annotations)
+			if err := r.client.Update(context.TODO(), device); err != nil {
+				return nil, err
+			}
+			r.recorder.Eventf(device, corev1.EventTypeNormal, "Moved", "Device was moved to node %s", node)
+		}
+	}
+
+	status := device.Status.DeepCopy()
+	status.ADBSerial = attached.Serial
+	status.NodeName = node
+	status.Properties = &androidv1alpha1.DeviceProperties{
+		Manufacturer: attached.Manufacturer,
+		Model:        attached.Model,
+		SDK:          attached.SDK,
+		Release:      attached.Release,
+		ABI:          attached.ABI,
+	}
+	if attached.BatteryLevel >= 0 {
+		status.Properties.BatteryLevel = &attached.BatteryLevel
+	} else if device.Status.Properties != nil {
+		// keep the last known level
+		status.Properties.BatteryLevel = device.Status.Properties.BatteryLevel
+	}
+	setAttachedStatus(status, attached.State)
+	return device, updateStatus(r.client, device, status)
+}
+
+// setAttachedStatus sets the phase and conditions of a physical device from
+// its state on the ADB server of its provider. Providers bind all the online
+// devices on their ADB server to STF.
+func setAttachedStatus(status *androidv1alpha1.AndroidDeviceStatus, state string) {
+	if state == inventory.StateDevice {
+		status.Phase = androidv1alpha1.DeviceBound
+		status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceBootCompleted, corev1.ConditionTrue, "Online", "The device is online on its provider's ADB server"))
+		status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceSTFBound, corev1.ConditionTrue, "Attached", "The device is attached to its provider"))
+		return
+	}
+	reason := strings.Title(state)
+	msg := fmt.Sprintf("The device is %s on its provider's ADB server", state)
+	status.Phase = androidv1alpha1.DeviceDegraded
+	status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceBootCompleted, corev1.ConditionFalse, reason, msg))
+	status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceSTFBound, corev1.ConditionFalse, reason, msg))
+}
+
+// updateStatus writes the given status to a device if it has changed.
+func updateStatus(c client.Client, device *androidv1alpha1.AndroidDevice, status *androidv1alpha1.AndroidDeviceStatus) error {
+	if reflect.DeepEqual(*status, device.Status) {
+		return nil
+	}
+	device.Status = *status
+	return c.Status().Update(context.TODO(), device)
+}
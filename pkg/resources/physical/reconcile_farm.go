@@ -0,0 +1,183 @@
+package physical
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/resources"
+	"github.com/tinyzimmer/android-farm-operator/pkg/util/android/inventory"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InventoryInterval is how often the inventories of host USB providers are
+// polled for attached devices.
+var InventoryInterval = time.Duration(30) * time.Second
+
+// PhysicalFarmReconciler maintains AndroidDevices for the physical devices
+// attached to the providers of the host USB device groups in an AndroidFarm.
+type PhysicalFarmReconciler struct {
+	resources.FarmReconciler
+
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+var _ resources.FarmReconciler = &PhysicalFarmReconciler{}
+
+// NewForFarm returns a new reconciler for the physical devices of an
+// AndroidFarm. Devices attached and detached are recorded as events with the
+// given recorder.
+func NewForFarm(c client.Client, s *runtime.Scheme, recorder record.EventRecorder) resources.FarmReconciler {
+	return &PhysicalFarmReconciler{client: c, scheme: s, recorder: recorder}
+}
+
+// HasInventory returns true if the given farm has providers serving an
+// inventory of physical devices, and should be polled for changes to them.
+func HasInventory(farm *androidv1alpha1.AndroidFarm) bool {
+	if farm.STFDisabled() {
+		return false
+	}
+	for _, group := range farm.DeviceGroups() {
+		if group.IsUSBGroup() {
+			return true
+		}
+	}
+	return false
+}
+
+// Reconcile fetches the inventory of every host USB provider in the farm and
+// ensures an AndroidDevice for each device attached to them. Devices that are
+// no longer attached are removed.
+func (r *PhysicalFarmReconciler) Reconcile(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm) error {
+	namespaces := make(map[string]string)
+	if !instance.STFDisabled() {
+		for _, group := range instance.DeviceGroups() {
+			if !group.IsUSBGroup() {
+				continue
+			}
+			namespaces[group.Name] = group.GetNamespace()
+			logger := reqLogger.WithValues("Group", group.Name, "Namespace", group.GetNamespace())
+			if err := r.reconcileUSBDeviceGroup(logger, instance, group); err != nil {
+				return err
+			}
+		}
+	}
+	return r.runGC(reqLogger, instance, namespaces)
+}
+
+// reconcileUSBDeviceGroup reconciles the physical devices attached to each of
+// the providers of a host USB device group.
+func (r *PhysicalFarmReconciler) reconcileUSBDeviceGroup(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) error {
+	// devices attached to providers whose inventory could not be read are left
+	// as they are
+	unreachable := make(map[string]struct{})
+	seen := make(map[string]struct{})
+	for slot := int32(0); slot < group.MaxUSBNodes(); slot++ {
+		name := fmt.Sprintf("%s-%s", instance.STFNamePrefix(), group.GetProviderSlotName(slot))
+		// skip slots that are not running a provider
+		svc := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: instance.STFConfig().GetNamespace()}, svc); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		provider := providerHost(instance, name)
+		inv, err := inventory.Get(provider)
+		if err != nil {
+			reqLogger.Info("Could not read the device inventory of provider, skipping", "Provider", provider, "Error", err.Error())
+			unreachable[provider] = struct{}{}
+			continue
+		}
+		for _, attached := range inv.Devices {
+			device, err := r.reconcilePhysicalDevice(reqLogger, instance, group, provider, inv.Node, attached)
+			if err != nil {
+				return err
+			}
+			seen[device.GetName()] = struct{}{}
+		}
+	}
+
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := r.client.List(context.TODO(), devices, client.InNamespace(group.GetNamespace()), client.MatchingLabels{
+		androidv1alpha1.DeviceFarmLabel:  instance.GetName(),
+		androidv1alpha1.DeviceGroupLabel: group.Name,
+	}); err != nil {
+		return err
+	}
+	for _, device := range devices.Items {
+		if !device.IsPhysicalDevice() {
+			continue
+		}
+		if _, ok := seen[device.GetName()]; ok {
+			continue
+		}
+		if _, ok := unreachable[device.GetAnnotations()[androidv1alpha1.STFProviderAnnotation]]; ok {
+			continue
+		}
+		if err := r.detachPhysicalDevice(reqLogger, instance, &device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detachPhysicalDevice removes the AndroidDevice for a device that is no
+// longer attached to its provider. Leased devices are marked as detached and
+// removed once they are released.
+func (r *PhysicalFarmReconciler) detachPhysicalDevice(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, device *androidv1alpha1.AndroidDevice) error {
+	if device.IsLeased() {
+		reqLogger.Info("Device is no longer attached but is leased, deferring deletion until it is released", "Device.Name", device.Name, "Lease", device.LeaseName())
+		r.recorder.Eventf(device, corev1.EventTypeWarning, "Detached", "The device is no longer attached to its provider, it will be removed once lease %s is released", device.LeaseName())
+		status := device.Status.DeepCopy()
+		status.Phase = androidv1alpha1.DeviceDegraded
+		status.SetCondition(androidv1alpha1.NewCondition(androidv1alpha1.DeviceSTFBound, corev1.ConditionFalse, "Detached", "The device is no longer attached to its provider"))
+		return updateStatus(r.client, device, status)
+	}
+	reqLogger.Info("Deleting device that is no longer attached to its provider", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
+	if err := r.client.Delete(context.TODO(), device); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	r.recorder.Eventf(instance, corev1.EventTypeNormal, "DeviceDeleted", "Deleted device %s/%s as it is no longer attached to its provider", device.Namespace, device.Name)
+	return nil
+}
+
+// runGC removes the physical devices of the farm that belong to groups that
+// are no longer host USB device groups, or that have moved to another
+// namespace. Leased devices are removed once they are released.
+func (r *PhysicalFarmReconciler) runGC(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, namespaces map[string]string) error {
+	devices := &androidv1alpha1.AndroidDeviceList{}
+	if err := r.client.List(context.TODO(), devices, instance.MatchingLabels()); err != nil {
+		return err
+	}
+	for _, device := range devices.Items {
+		if !device.IsPhysicalDevice() || device.IsLeased() {
+			continue
+		}
+		if namespace, ok := namespaces[device.GetLabels()[androidv1alpha1.DeviceGroupLabel]]; ok && namespace == device.GetNamespace() {
+			continue
+		}
+		reqLogger.Info("Deleting physical device for a group that no longer exists", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
+		if err := r.client.Delete(context.TODO(), &device); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, "DeviceDeleted", "Deleted device %s/%s as its host USB group no longer exists", device.Namespace, device.Name)
+	}
+	return nil
+}
+
+// providerHost returns the cluster address of a provider's service.
+func providerHost(instance *androidv1alpha1.AndroidFarm, name string) string {
+	return fmt.Sprintf("%s.%s.svc", name, instance.STFConfig().GetNamespace())
+}
@@ -39,6 +39,7 @@ func (r *RethinkDBReconciler) Reconcile(reqLogger logr.Logger, instance *android
 		msg   string
 		rfunc util.FarmReconcileFunc
 	}{
+		{"Checking if RethinkDB is reachable", r.checkRethinkDBIsReachable},
 		{"Reconciling headless and admin services for RethinkDB", r.reconcileServices},
 		{"Reconciling StatefulSet for RethinkDB", r.reconcileStatefulSet},
 		{"Checking if RethinkDB is ready", r.checkRethinkDBIsReady},
@@ -2,10 +2,15 @@ package rethinkdb
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"github.com/tinyzimmer/android-farm-operator/pkg/metrics"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/errors"
+	stfutil "github.com/tinyzimmer/android-farm-operator/pkg/util/stf"
+	rdb "gopkg.in/rethinkdb/rethinkdb-go.v6"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -23,12 +28,34 @@ func (r *RethinkDBReconciler) checkRethinkDBIsReady(reqLogger logr.Logger, insta
 	}
 
 	if ss.Status.ReadyReplicas != *instance.STFConfig().RethinkDBReplicas() {
-		return errors.NewRequeueError("Requeing until RethinkDB is ready", 5)
+		return errors.NewRequeueError("RethinkDBNotReady", "Requeing until RethinkDB is ready", 5)
 	}
 
 	return nil
 }
 
+// checkRethinkDBIsReachable records whether a connection can be made to the
+// rethinkdb proxy. It never returns an error, the readiness checks decide if
+// the request should be requeued.
+func (r *RethinkDBReconciler) checkRethinkDBIsReachable(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm) error {
+	session, err := rdb.Connect(rdb.ConnectOpts{
+		Address: strings.TrimPrefix(stfutil.RethinkDBProxyEndpoint(instance), "tcp://"),
+		Timeout: reachabilityTimeout,
+	})
+	if err != nil {
+		reqLogger.Info("RethinkDB is not reachable", "Error", err.Error())
+		metrics.SetRethinkDBReachable(instance, false)
+		return nil
+	}
+	session.Close()
+	metrics.SetRethinkDBReachable(instance, true)
+	return nil
+}
+
+// reachabilityTimeout is how long to wait for a connection to rethinkdb when
+// checking if it is reachable.
+var reachabilityTimeout = time.Duration(3) * time.Second
+
 // checkRethinkDBProxyIsReady returns nil if the rethinkdb proxy is ready. If
 // it isn't a requeue error is returned.
 func (r *RethinkDBReconciler) checkRethinkDBProxyIsReady(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm) error {
@@ -42,7 +69,7 @@ func (r *RethinkDBReconciler) checkRethinkDBProxyIsReady(reqLogger logr.Logger,
 	}
 
 	if ss.Status.ReadyReplicas != *instance.STFConfig().RethinkDBProxyReplicas() {
-		return errors.NewRequeueError("Requeing until RethinkDB is ready", 5)
+		return errors.NewRequeueError("RethinkDBNotReady", "Requeing until RethinkDB is ready", 5)
 	}
 
 	return nil
@@ -2,7 +2,9 @@ package stf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"text/template"
 
@@ -10,9 +12,17 @@ import (
 	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
 	"github.com/tinyzimmer/android-farm-operator/pkg/util/builders"
 	stfutil "github.com/tinyzimmer/android-farm-operator/pkg/util/stf"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// usbNodeSelectorKey is the node label used to pin a provider to the node its
+// USB devices are attached to.
+var usbNodeSelectorKey = "kubernetes.io/hostname"
+
 var providerStartScriptTmpl = template.Must(template.New("provider-start").Parse(`
 timeout 22 bash -c 'until printf "" 2>>/dev/null >>/dev/tcp/127.0.0.1/5037; do sleep 1; done'
 echo "ADB is available, launching {{ .ProviderName }}"
@@ -47,12 +57,21 @@ func (r *STFReconciler) reconcileProviders(reqLogger logr.Logger, instance *andr
 }
 
 func (r *STFReconciler) reconcileGroupProvider(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) error {
-	startScript, err := getGroupStartScript(instance, group)
+	if group.UsesUSBNodeSelector() {
+		return r.reconcileUSBNodeProviders(reqLogger, instance, group)
+	}
+	return r.reconcileProviderSlot(reqLogger, instance, group, 0, group.ProviderNodeSelector())
+}
+
+// reconcileProviderSlot ensures the provider deployment for the given slot of
+// a device group, scheduled with the given node selector.
+func (r *STFReconciler) reconcileProviderSlot(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, slot int32, nodeSelector map[string]string) error {
+	startScript, err := getGroupStartScript(instance, group, slot)
 	if err != nil {
 		return err
 	}
 
-	name := group.GetProviderName()
+	name := group.GetProviderSlotName(slot)
 
 	builder := builders.NewDeploymentBuilder(reqLogger, instance, name).
 		WithResourceRequirements(instance.STFConfig().ProviderResourceRequirements(group)).
@@ -65,13 +84,9 @@ func (r *STFReconciler) reconcileGroupProvider(reqLogger logr.Logger, instance *
 		WithSidecar(instance.STFConfig().ADBSidecarContainer(name, group)).
 		WithWait()
 
-	var maxPort int32
-	if group.IsEmulatedGroup() {
-		maxPort = group.GetCount()
-	} else if group.IsUSBGroup() {
-		maxPort = group.MaxUSBDevices()
+	if group.IsUSBGroup() {
 		builder = builder.
-			WithNodeSelector(group.ProviderNodeSelector()).
+			WithNodeSelector(nodeSelector).
 			WithVolumes([]corev1.Volume{
 				{
 					Name: "usb",
@@ -83,13 +98,93 @@ func (r *STFReconciler) reconcileGroupProvider(reqLogger logr.Logger, instance *
 				}}, nil)
 	}
 
-	for i := group.GetProviderStartPort(); i <= getProviderMaxPort(group.GetProviderStartPort(), maxPort); i++ {
+	minPort, maxPort := group.GetProviderSlotPorts(slot)
+	for i := minPort; i <= maxPort; i++ {
 		builder = builder.WithPort(fmt.Sprintf("provider-%d", i), i)
 	}
 
 	return builder.Reconcile(r.client)
 }
 
+// reconcileUSBNodeProviders runs a provider on every node matching the USB node
+// selector of a device group. Each node keeps the slot, and with it the ports,
+// it was first given for as long as it matches the selector. Providers for
+// nodes that no longer match are removed, freeing their slot.
+func (r *STFReconciler) reconcileUSBNodeProviders(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) error {
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodes, client.MatchingLabels(group.HostUSB.NodeSelector)); err != nil {
+		return err
+	}
+	matching := make(map[string]struct{})
+	for _, node := range nodes.Items {
+		matching[node.GetName()] = struct{}{}
+	}
+
+	// find the nodes already assigned to each slot
+	slots := make([]string, group.MaxUSBNodes())
+	assigned := make(map[string]struct{})
+	for slot := range slots {
+		deployment := &appsv1.Deployment{}
+		nn := types.NamespacedName{
+			Name:      fmt.Sprintf("%s-%s", instance.STFNamePrefix(), group.GetProviderSlotName(int32(slot))),
+			Namespace: instance.STFConfig().GetNamespace(),
+		}
+		if err := r.client.Get(context.TODO(), nn, deployment); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		nodeName := deployment.Spec.Template.Spec.NodeSelector[usbNodeSelectorKey]
+		if _, ok := matching[nodeName]; !ok {
+			reqLogger.Info("Removing provider for node no longer matching the USB node selector", "Node", nodeName, "Provider", deployment.GetName())
+			if err := r.deleteProviderSlot(deployment); err != nil {
+				return err
+			}
+			continue
+		}
+		slots[slot] = nodeName
+		assigned[nodeName] = struct{}{}
+	}
+
+	// give new nodes the lowest free slots, in name order so assignment is stable
+	newNodes := make([]string, 0)
+	for nodeName := range matching {
+		if _, ok := assigned[nodeName]; !ok {
+			newNodes = append(newNodes, nodeName)
+		}
+	}
+	sort.Strings(newNodes)
+	for slot := range slots {
+		if slots[slot] == "" && len(newNodes) > 0 {
+			slots[slot] = newNodes[0]
+			newNodes = newNodes[1:]
+		}
+	}
+	if len(newNodes) > 0 {
+		reqLogger.Info("More nodes match the USB node selector than maxNodes, skipping the remainder", "MaxNodes", group.MaxUSBNodes(), "Skipped", newNodes)
+	}
+
+	for slot, nodeName := range slots {
+		if nodeName == "" {
+			continue
+		}
+		if err := r.reconcileProviderSlot(reqLogger, instance, group, int32(slot), map[string]string{usbNodeSelectorKey: nodeName}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteProviderSlot removes a provider deployment and its service.
+func (r *STFReconciler) deleteProviderSlot(deployment *appsv1.Deployment) error {
+	if err := r.client.Delete(context.TODO(), deployment); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: deployment.GetName(), Namespace: deployment.GetNamespace()}}
+	return client.IgnoreNotFound(r.client.Delete(context.TODO(), svc))
+}
+
 func (r *STFReconciler) reconcileGroupProviderTraefik(reqLogger logr.Logger, instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) error {
 	providerDefs := calculateProviderSvcDefinitions(instance, group, false)
 
@@ -137,22 +232,17 @@ func (r *STFReconciler) reconcileGroupProviderTraefik(reqLogger logr.Logger, ins
 	return builder.Reconcile(r.client)
 }
 
-func getGroupStartScript(instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup) (string, error) {
+func getGroupStartScript(instance *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, slot int32) (string, error) {
+	minPort, maxPort := group.GetProviderSlotPorts(slot)
 	var buf bytes.Buffer
-	var deviceCount int32
-	if group.IsEmulatedGroup() {
-		deviceCount = group.GetCount()
-	} else {
-		deviceCount = group.MaxUSBDevices()
-	}
 	if err := providerStartScriptTmpl.Execute(&buf, map[string]interface{}{
-		"ProviderName":     group.GetProviderName(),
+		"ProviderName":     group.GetProviderSlotName(slot),
 		"TriproxyDev":      fmt.Sprintf("%s-triproxy-dev", instance.STFNamePrefix()),
 		"HTTPScheme":       instance.STFConfig().GetHTTPScheme(),
 		"WebsocketScheme":  instance.STFConfig().GetWebsocketScheme(),
 		"ProviderHostname": stfutil.GetGroupADBAdvertiseURL(instance, group),
-		"MinPort":          strconv.Itoa(int(group.GetProviderStartPort())),
-		"MaxPort":          strconv.Itoa(int(getProviderMaxPort(group.GetProviderStartPort(), deviceCount))),
+		"MinPort":          strconv.Itoa(int(minPort)),
+		"MaxPort":          strconv.Itoa(int(maxPort)),
 		"StorageURL":       instance.InternalStorageURL(),
 		"AppWebsocketURL":  instance.STFConfig().GetAppExternalWebsocketURL(),
 		"NoCleanup":        group.ProviderNoCleanup(),
@@ -161,7 +251,3 @@ func getGroupStartScript(instance *androidv1alpha1.AndroidFarm, group *androidv1
 	}
 	return buf.String(), nil
 }
-
-func getProviderMaxPort(startPort, deviceCount int32) int32 {
-	return (deviceCount * 4) + startPort
-}
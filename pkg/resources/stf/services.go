@@ -115,16 +115,18 @@ func calculateServiceDefinitions(cr *androidv1alpha1.AndroidFarm) []map[string]s
 
 	for _, group := range cr.DeviceGroups() {
 		if group.UseClusterLocalADB() {
-			providerName := group.GetProviderName()
-			svcs = append(svcs, map[string]svcDef{
-				providerName: svcDef{
-					Rule:       fmt.Sprintf("(Host(`%s`) && PathPrefix(`/d/%s/`))", cr.STFConfig().GetAppHostname(), providerName),
-					Endpoints:  []string{fmt.Sprintf("%s-%s-traefik", cr.STFNamePrefix(), providerName)},
-					Priority:   "10",
-					Port:       "8088",
-					IsProvider: false,
-				},
-			})
+			for slot := int32(0); slot < group.MaxUSBNodes(); slot++ {
+				providerName := group.GetProviderSlotName(slot)
+				svcs = append(svcs, map[string]svcDef{
+					providerName: svcDef{
+						Rule:       fmt.Sprintf("(Host(`%s`) && PathPrefix(`/d/%s/`))", cr.STFConfig().GetAppHostname(), providerName),
+						Endpoints:  []string{fmt.Sprintf("%s-%s-traefik", cr.STFNamePrefix(), group.GetProviderName())},
+						Priority:   "10",
+						Port:       "8088",
+						IsProvider: false,
+					},
+				})
+			}
 		} else {
 			svcs = append(svcs, calculateProviderSvcDefinitions(cr, group, true)...)
 		}
@@ -135,29 +137,25 @@ func calculateServiceDefinitions(cr *androidv1alpha1.AndroidFarm) []map[string]s
 
 func calculateProviderSvcDefinitions(cr *androidv1alpha1.AndroidFarm, group *androidv1alpha1.DeviceGroup, toTraefik bool) []map[string]svcDef {
 	svcs := make([]map[string]svcDef, 0)
-	var maxPort int32
-	minPort := group.GetProviderStartPort()
-	if group.IsEmulatedGroup() {
-		maxPort = getProviderMaxPort(minPort, group.GetCount())
-	} else {
-		maxPort = getProviderMaxPort(minPort, group.MaxUSBDevices())
-	}
-	providerName := group.GetProviderName()
-	for i := minPort; i <= maxPort; i++ {
-		svcName := fmt.Sprintf("%s-%d", providerName, i)
-		var endpoint string
-		if toTraefik {
-			endpoint = fmt.Sprintf("%s-%s-traefik", cr.STFNamePrefix(), providerName)
-		} else {
-			endpoint = fmt.Sprintf("%s-%s", cr.STFNamePrefix(), providerName)
+	for slot := int32(0); slot < group.MaxUSBNodes(); slot++ {
+		minPort, maxPort := group.GetProviderSlotPorts(slot)
+		providerName := group.GetProviderSlotName(slot)
+		for i := minPort; i <= maxPort; i++ {
+			svcName := fmt.Sprintf("%s-%d", providerName, i)
+			var endpoint string
+			if toTraefik {
+				endpoint = fmt.Sprintf("%s-%s-traefik", cr.STFNamePrefix(), group.GetProviderName())
+			} else {
+				endpoint = fmt.Sprintf("%s-%s", cr.STFNamePrefix(), providerName)
+			}
+			svcs = append(svcs, map[string]svcDef{svcName: svcDef{
+				Rule:       fmt.Sprintf("(Host(`%s`) && PathPrefix(`/d/%s/{serial:[^/]+}/%d/`))", cr.STFConfig().GetAppHostname(), providerName, i),
+				Endpoints:  []string{endpoint},
+				Priority:   "10",
+				Port:       strconv.Itoa(int(i)),
+				IsProvider: true,
+			}})
 		}
-		svcs = append(svcs, map[string]svcDef{svcName: svcDef{
-			Rule:       fmt.Sprintf("(Host(`%s`) && PathPrefix(`/d/%s/{serial:[^/]+}/%d/`))", cr.STFConfig().GetAppHostname(), providerName, i),
-			Endpoints:  []string{endpoint},
-			Priority:   "10",
-			Port:       strconv.Itoa(int(i)),
-			IsProvider: true,
-		}})
 	}
 	return svcs
 }
@@ -1,6 +1,7 @@
 package adb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -79,9 +80,8 @@ func (c *Cmd) WithVerbose() *Cmd {
 	return c
 }
 
-func (c *Cmd) Execute() ([]byte, error) {
-	// defer cancel context to prevent leaks in case a timeout was set
-	defer c.cancel()
+// args returns the arguments to pass to adb.
+func (c *Cmd) args() []string {
 	cmdArgs := make([]string, 0)
 	if c.device != "" {
 		cmdArgs = append(cmdArgs, "-s", c.device)
@@ -95,7 +95,13 @@ func (c *Cmd) Execute() ([]byte, error) {
 			cmdArgs = append(cmdArgs, "su", "root")
 		}
 	}
-	cmdArgs = append(cmdArgs, c.command...)
+	return append(cmdArgs, c.command...)
+}
+
+func (c *Cmd) Execute() ([]byte, error) {
+	// defer cancel context to prevent leaks in case a timeout was set
+	defer c.cancel()
+	cmdArgs := c.args()
 
 	var out []byte
 	var err error
@@ -116,3 +122,39 @@ func (c *Cmd) Execute() ([]byte, error) {
 	}
 	return out, err
 }
+
+// Result is the output and exit code of a command.
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// ExecuteWithResult runs the command and returns its output and exit code. A
+// non-zero exit code is not an error, errors are only returned when the
+// command could not be run or timed out. When the command is run in a shell,
+// adb reports the exit code of the command on the device.
+func (c *Cmd) ExecuteWithResult() (*Result, error) {
+	// defer cancel context to prevent leaks in case a timeout was set
+	defer c.cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(c.Context(), adbExec, c.args()...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if c.verbose {
+		log.Println(append([]string{adbExec}, c.args()...))
+	}
+	err := cmd.Run()
+	if ctxErr := c.Context().Err(); ctxErr != nil {
+		return nil, fmt.Errorf("%s: %s", ctxErr.Error(), stderr.String())
+	}
+	result := &Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, err
+		}
+		result.ExitCode = exitErr.ExitCode()
+	}
+	return result, nil
+}
@@ -17,15 +17,25 @@ import (
 	"github.com/go-logr/logr"
 )
 
+// mux guards the local adb server and the connection counts
 var mux sync.Mutex
 
+// connections counts the open sessions to each remote device. A device is
+// disconnected from the local adb server when its last session is closed.
+var connections = make(map[string]int)
+
 // DeviceSession provides an interface for interacting with an emulated device
 // over ADB. It includes utility functions for searching and detecting text on
 // the screen via OCR.
 type DeviceSession interface {
 	BootCompleted() (bool, error)
+	Reboot() error
 	RunCommand(bool, ...string) ([]byte, error)
+	RunCommandWithTimeout(time.Duration, bool, ...string) ([]byte, error)
+	RunCommandWithResult(time.Duration, bool, ...string) (*adb.Result, error)
 	DownloadFile(string, io.Writer) error
+	InstallAPK(string, time.Duration) error
+	DumpLogcat(string, io.Writer) error
 	GetScreencap() (image.Image, error)
 	GetScreencapPNG() ([]byte, error)
 	GetInvertedScreencapPNG() ([]byte, error)
@@ -33,6 +43,7 @@ type DeviceSession interface {
 	LaunchApp(string) error
 	Tap(x, y, count int) error
 	TapAtString(*TapOptions) error
+	ScreenContains(string, bool) (bool, error)
 	Tab() error
 	InputText(string) error
 	RemoveText(int) error
@@ -57,47 +68,76 @@ func NewSession(logger logr.Logger, host string, port int32) (DeviceSession, err
 }
 
 // connect ensures an adb server is running and connects to the remote device.
-// A global lock is used to ensure a persistent server throughout the session
-// usage. This was when the client is torn down it can kill the server to avoid
-// stale devices in ADB.
+// Sessions to different devices, or to the same device, can be used in
+// parallel. The lock is only held while connecting.
 func (d *deviceSession) connect() error {
 	mux.Lock()
+	defer mux.Unlock()
 	if _, err := adb.NewCommand("start-server").WithTimeout(time.Duration(5) * time.Second).Execute(); err != nil {
-		mux.Unlock()
 		return err
 	}
+	// connect even if another session is open in case the server restarted,
+	// adb reports the device as already connected otherwise.
 	out, err := adb.NewCommand("connect", d.host).WithTimeout(time.Duration(5) * time.Second).Execute()
 	if err != nil {
-		mux.Unlock()
 		return err
 	}
 	if !strings.Contains(string(out), "connected") {
-		mux.Unlock()
 		return fmt.Errorf("Failed to connect to device %s: %s", d.host, string(out))
 	}
-	// leave the lock in place allowing the client an uninterrupted session.
-	// TODO: A timeut on sessions should be enforced to avoid dead locking.
+	connections[d.host]++
 	return nil
 }
 
-// Close kills the adb server for this session and releases the global lock.
+// Close disconnects the remote device from the adb server if this was its
+// last open session, to avoid stale devices in ADB.
 func (d *deviceSession) Close() {
-	mux.Unlock()
-	if _, err := adb.NewCommand("kill-server").WithTimeout(time.Duration(5) * time.Second).Execute(); err != nil {
-		d.logger.Error(err, "Failed to cleanly stop adb server")
+	mux.Lock()
+	defer mux.Unlock()
+	connections[d.host]--
+	if connections[d.host] > 0 {
+		return
+	}
+	delete(connections, d.host)
+	if _, err := adb.NewCommand("disconnect", d.host).WithTimeout(time.Duration(5) * time.Second).Execute(); err != nil {
+		d.logger.Error(err, "Failed to cleanly disconnect device")
 	}
 }
 
+// DefaultCommandTimeout is the timeout for shell commands run with RunCommand.
+var DefaultCommandTimeout = time.Duration(10) * time.Second
+
+// DefaultInstallTimeout is the timeout for installing an APK when none is
+// given.
+var DefaultInstallTimeout = time.Duration(5) * time.Minute
+
 // RunCommand executes a shell command inside the remote device and returns the stdout
 // or any error that occurs.
 func (d *deviceSession) RunCommand(root bool, cmd ...string) ([]byte, error) {
-	adbcmd := adb.NewCommand(cmd...).WithDevice(d.host).WithShell().WithTimeout(time.Duration(10) * time.Second)
+	return d.RunCommandWithTimeout(DefaultCommandTimeout, root, cmd...)
+}
+
+// RunCommandWithTimeout is like RunCommand except the command is killed after
+// the given timeout.
+func (d *deviceSession) RunCommandWithTimeout(timeout time.Duration, root bool, cmd ...string) ([]byte, error) {
+	adbcmd := adb.NewCommand(cmd...).WithDevice(d.host).WithShell().WithTimeout(timeout)
 	if root {
 		adbcmd = adbcmd.WithRoot()
 	}
 	return adbcmd.Execute()
 }
 
+// RunCommandWithResult is like RunCommandWithTimeout except the stdout, stderr
+// and exit code of the command are returned. A non-zero exit code is not an
+// error.
+func (d *deviceSession) RunCommandWithResult(timeout time.Duration, root bool, cmd ...string) (*adb.Result, error) {
+	adbcmd := adb.NewCommand(cmd...).WithDevice(d.host).WithShell().WithTimeout(timeout)
+	if root {
+		adbcmd = adbcmd.WithRoot()
+	}
+	return adbcmd.ExecuteWithResult()
+}
+
 // DownloadFile retrieves the specified file from the device and writes its contents
 // to the provided buffer
 func (d *deviceSession) DownloadFile(path string, writer io.Writer) error {
@@ -111,6 +151,40 @@ func (d *deviceSession) DownloadFile(path string, writer io.Writer) error {
 	return err
 }
 
+// DumpLogcat writes the logcat of the device since the given time, in the
+// "MM-DD hh:mm:ss.mmm" format of the device clock, to the provided buffer.
+func (d *deviceSession) DumpLogcat(since string, writer io.Writer) error {
+	_, err := adb.NewCommand(fmt.Sprintf("logcat -d -v threadtime -T '%s'", since)).
+		WithDevice(d.host).
+		WithShell().
+		WithTimeout(60 * time.Second).
+		WithBuffer(writer).
+		Execute()
+	return err
+}
+
+// InstallAPK installs the APK at the given local path on the device, replacing
+// any existing installation of the app. A zero timeout uses the
+// DefaultInstallTimeout.
+func (d *deviceSession) InstallAPK(path string, timeout time.Duration) error {
+	d.logger.Info(fmt.Sprintf("Installing APK: %s", path))
+	if timeout == 0 {
+		timeout = DefaultInstallTimeout
+	}
+	out, err := adb.NewCommand("install", "-r", path).
+		WithDevice(d.host).
+		WithTimeout(timeout).
+		Execute()
+	if err != nil {
+		return err
+	}
+	// older versions of adb exit zero when the install fails
+	if strings.Contains(string(out), "Failure") {
+		return fmt.Errorf("Failed to install %s: %s", path, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 // BootCompleted returns true if the remote device is fully booted, false if it
 // isn't, or any adb error that occurs in the process.
 func (d *deviceSession) BootCompleted() (bool, error) {
@@ -124,6 +198,13 @@ func (d *deviceSession) BootCompleted() (bool, error) {
 	return false, nil
 }
 
+// Reboot restarts the remote device. The session should not be used again
+// until the device has finished booting.
+func (d *deviceSession) Reboot() error {
+	_, err := adb.NewCommand("reboot").WithDevice(d.host).WithTimeout(time.Duration(10) * time.Second).Execute()
+	return err
+}
+
 // InputText will type the given string into the device. It is assumed that
 // the text area to fill is already selected.
 func (d *deviceSession) InputText(s string) error {
@@ -0,0 +1,65 @@
+// Package inventory contains the types and client for the device inventory
+// served by adbmon for the devices attached to its ADB server.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+)
+
+// Path is the HTTP path of the device inventory.
+const Path = "/devices"
+
+// Device states as reported by `adb devices`.
+const (
+	StateDevice       = "device"
+	StateOffline      = "offline"
+	StateUnauthorized = "unauthorized"
+)
+
+// Inventory represents the devices attached to an ADB server.
+type Inventory struct {
+	// The node the ADB server is running on.
+	Node string `json:"node"`
+	// The STF provider the ADB server belongs to.
+	Provider string `json:"provider"`
+	// The devices attached to the ADB server.
+	Devices []Device `json:"devices"`
+}
+
+// Device represents a single device attached to an ADB server.
+type Device struct {
+	Serial       string `json:"serial"`
+	State        string `json:"state"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Model        string `json:"model,omitempty"`
+	SDK          string `json:"sdk,omitempty"`
+	Release      string `json:"release,omitempty"`
+	ABI          string `json:"abi,omitempty"`
+	// The battery level in percent, or -1 if it could not be read.
+	BatteryLevel int32 `json:"batteryLevel"`
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Get retrieves the device inventory from the adbmon instance at the given
+// host.
+func Get(host string) (*Inventory, error) {
+	resp, err := httpClient.Get(fmt.Sprintf("http://%s:%d%s", host, androidv1alpha1.ADBInventoryPort, Path))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status from inventory at %s: %s", host, resp.Status)
+	}
+	inv := &Inventory{}
+	if err := json.NewDecoder(resp.Body).Decode(inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
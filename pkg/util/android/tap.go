@@ -127,6 +127,28 @@ func (d *deviceSession) TapAtStringWithScroll(opts *TapOptions) error {
 	}
 }
 
+// ScreenContains returns true if the given string can be found on the device
+// screen, optionally inverting the image pixels before searching.
+func (d *deviceSession) ScreenContains(s string, invert bool) (bool, error) {
+	if err := d.ensureDimensions(); err != nil {
+		return false, err
+	}
+	var err error
+	var screen []byte
+	if invert {
+		screen, err = d.GetInvertedScreencapPNG()
+	} else {
+		screen, err = d.GetScreencapPNG()
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, _, err := d.getStringCoordinates(s, screen); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
 // getStringCoordinates will attempt to find the coordinates of a string on the
 // device screen.
 func (d *deviceSession) getStringCoordinates(s string, imgBytes []byte) (x, y int, err error) {
@@ -0,0 +1,194 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AccessKeyIDKey is the key of the access key ID in S3 credentials secrets
+	AccessKeyIDKey = "AWS_ACCESS_KEY_ID"
+	// SecretAccessKeyKey is the key of the secret access key in S3 credentials
+	// secrets
+	SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+
+	defaultS3Region = "us-east-1"
+)
+
+// s3Sink stores artifacts in an S3 compatible bucket. Requests are made with
+// path style addressing, which MinIO and AWS both support, and signed with
+// AWS Signature Version 4.
+type s3Sink struct {
+	scheme, endpoint, bucket, prefix, region string
+	accessKeyID, secretAccessKey             string
+	client                                   *http.Client
+}
+
+func newS3Sink(c client.Client, namespace string, cfg *androidv1alpha1.S3Sink) (Sink, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: cfg.CredentialsSecret, Namespace: namespace}, secret); err != nil {
+		return nil, err
+	}
+	accessKeyID, ok := secret.Data[AccessKeyIDKey]
+	if !ok {
+		return nil, fmt.Errorf("Secret %s does not contain %s", cfg.CredentialsSecret, AccessKeyIDKey)
+	}
+	secretAccessKey, ok := secret.Data[SecretAccessKeyKey]
+	if !ok {
+		return nil, fmt.Errorf("Secret %s does not contain %s", cfg.CredentialsSecret, SecretAccessKeyKey)
+	}
+	sink := &s3Sink{
+		scheme:          "https",
+		endpoint:        cfg.Endpoint,
+		bucket:          cfg.Bucket,
+		prefix:          cfg.Prefix,
+		region:          cfg.Region,
+		accessKeyID:     string(accessKeyID),
+		secretAccessKey: string(secretAccessKey),
+		client:          &http.Client{Timeout: time.Duration(2) * time.Minute},
+	}
+	if cfg.Insecure {
+		sink.scheme = "http"
+	}
+	if sink.region == "" {
+		sink.region = defaultS3Region
+	}
+	return sink, nil
+}
+
+// Store implements Sink.
+func (s *s3Sink) Store(key string, data []byte) (string, error) {
+	objPath := "/" + s.bucket + "/" + strings.TrimPrefix(path.Join(s.prefix, key), "/")
+	encodedPath := uriEncodePath(objPath)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s://%s%s", s.scheme, s.endpoint, encodedPath), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	// use the encoded path as-is in the request line so it matches the signature
+	req.URL.Opaque = "//" + s.endpoint + encodedPath
+	req.Header.Set("Content-Type", contentType(key))
+	s.sign(req, encodedPath, data, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("Failed to store %s: %s: %s", key, resp.Status, string(body))
+	}
+	return fmt.Sprintf("%s://%s%s", s.scheme, s.endpoint, encodedPath), nil
+}
+
+// sign adds the headers for AWS Signature Version 4 to a request.
+func (s *s3Sink) sign(req *http.Request, encodedPath string, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	scope, signedHeaders, signature := s.signature(req.Method, encodedPath, map[string]string{
+		"content-type":         req.Header.Get("Content-Type"),
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}, payloadHash, now)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// signature computes the AWS Signature Version 4 of a request without a query
+// string. Header names must be lowercase. It returns the credential scope, the
+// signed header names, and the signature.
+func (s *s3Sink) signature(method, encodedPath string, headers map[string]string, payloadHash string, now time.Time) (scope, signedHeaders, signature string) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(headers[name]))
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		encodedPath,
+		"", // no query string
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope = fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := signingKey(s.secretAccessKey, date, s.region, "s3")
+	return scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// signingKey derives the AWS Signature Version 4 signing key for a date,
+// region, and service.
+func signingKey(secretAccessKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// uriEncodePath encodes a path the way AWS expects in canonical requests,
+// which is stricter than url.PathEscape.
+func uriEncodePath(s string) string {
+	var out strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9'),
+			b == '-', b == '_', b == '.', b == '~', b == '/':
+			out.WriteByte(b)
+		default:
+			fmt.Fprintf(&out, "%%%02X", b)
+		}
+	}
+	return out.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,51 @@
+// Package artifacts contains the sinks that artifacts collected from devices
+// running jobs are stored in.
+package artifacts
+
+import (
+	"errors"
+	"mime"
+	"path"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Dir is the directory the artifacts volume is mounted at in the operator.
+// Volume sinks cannot be used when it is empty.
+var Dir string
+
+// Sink stores artifacts.
+type Sink interface {
+	// Store stores an artifact under the given key and returns the URL it was
+	// stored at.
+	Store(key string, data []byte) (string, error)
+}
+
+// NewSink returns the sink for the given configuration. Secrets referenced by
+// the sink are read from the given namespace, and STF sinks without a farm use
+// the given one.
+func NewSink(c client.Client, namespace, farm string, cfg *androidv1alpha1.ArtifactSink) (Sink, error) {
+	switch {
+	case cfg.Volume != nil:
+		return newVolumeSink(cfg.Volume)
+	case cfg.S3 != nil:
+		return newS3Sink(c, namespace, cfg.S3)
+	case cfg.STF != nil:
+		if cfg.STF.Farm != "" {
+			farm = cfg.STF.Farm
+		}
+		return newSTFSink(c, farm)
+	default:
+		return nil, errors.New("No artifact sink is configured")
+	}
+}
+
+// contentType returns the content type to store an artifact with based on
+// its key.
+func contentType(key string) string {
+	if ctype := mime.TypeByExtension(path.Ext(key)); ctype != "" {
+		return ctype
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,82 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"time"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// stfSink stores artifacts with the storage service of a farm, through its
+// internal traefik proxy.
+type stfSink struct {
+	uploadURL, externalURL string
+	client                 *http.Client
+}
+
+// stfUploadResponse is the response from the STF storage service to an upload.
+type stfUploadResponse struct {
+	Success   bool `json:"success"`
+	Resources map[string]struct {
+		Href string `json:"href"`
+	} `json:"resources"`
+}
+
+func newSTFSink(c client.Client, farmName string) (Sink, error) {
+	if farmName == "" {
+		return nil, errors.New("Could not determine the farm to store artifacts with")
+	}
+	farm := &androidv1alpha1.AndroidFarm{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: farmName}, farm); err != nil {
+		return nil, err
+	}
+	return &stfSink{
+		uploadURL:   fmt.Sprintf("%s/s/upload/blob", farm.InternalStorageURL()),
+		externalURL: farm.STFConfig().GetAppExternalURL(),
+		client:      &http.Client{Timeout: time.Duration(2) * time.Minute},
+	}, nil
+}
+
+// Store implements Sink. STF storage names blobs itself, so only the base of
+// the key is kept as the file name.
+func (s *stfSink) Store(key string, data []byte) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", path.Base(key))
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Post(s.uploadURL, writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("Failed to store %s: %s", key, resp.Status)
+	}
+	res := &stfUploadResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return "", err
+	}
+	file, ok := res.Resources["file"]
+	if !res.Success || !ok {
+		return "", fmt.Errorf("STF storage did not accept %s", key)
+	}
+	return s.externalURL + file.Href, nil
+}
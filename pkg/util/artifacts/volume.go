@@ -0,0 +1,34 @@
+package artifacts
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+)
+
+// volumeSink stores artifacts in a directory of the artifacts volume.
+type volumeSink struct {
+	dir string
+}
+
+func newVolumeSink(cfg *androidv1alpha1.VolumeSink) (Sink, error) {
+	if Dir == "" {
+		return nil, errors.New("The operator is not running with an artifacts volume")
+	}
+	return &volumeSink{dir: filepath.Join(Dir, filepath.Clean("/"+cfg.Path))}, nil
+}
+
+// Store implements Sink.
+func (v *volumeSink) Store(key string, data []byte) (string, error) {
+	dest := filepath.Join(v.dir, filepath.Clean("/"+key))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(dest, data, 0644); err != nil {
+		return "", err
+	}
+	return "file://" + dest, nil
+}
@@ -0,0 +1,61 @@
+package artifacts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+)
+
+func TestVolumeSinkStore(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	defer func(dir string) { Dir = dir }(Dir)
+	Dir = tmp
+
+	tests := []struct {
+		name string
+		path string
+		key  string
+		want string
+	}{
+		{name: "plain key", path: "jobs", key: "device-0/screen.png", want: "jobs/device-0/screen.png"},
+		{name: "no sink path", key: "screen.png", want: "screen.png"},
+		{name: "absolute key", path: "jobs", key: "/screen.png", want: "jobs/screen.png"},
+		{name: "key escaping the sink", path: "jobs", key: "../../etc/passwd", want: "jobs/etc/passwd"},
+		{name: "path escaping the volume", path: "../../etc", key: "passwd", want: "etc/passwd"},
+		{name: "dot segments", path: "./jobs/./a/..", key: "b/./c/../screen.png", want: "jobs/b/screen.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := newVolumeSink(&androidv1alpha1.VolumeSink{Path: tt.path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			url, err := sink.Store(tt.key, []byte("data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.Join(tmp, tt.want)
+			if url != "file://"+want {
+				t.Errorf("Store() = %s, want file://%s", url, want)
+			}
+			if _, err := os.Stat(want); err != nil {
+				t.Errorf("artifact was not written: %s", err)
+			}
+		})
+	}
+}
+
+func TestVolumeSinkWithoutVolume(t *testing.T) {
+	defer func(dir string) { Dir = dir }(Dir)
+	Dir = ""
+	if _, err := newVolumeSink(&androidv1alpha1.VolumeSink{Path: "jobs"}); err == nil {
+		t.Error("expected an error without an artifacts volume")
+	}
+}
@@ -6,6 +6,7 @@ import (
 )
 
 type RequeueError struct {
+	reason          string
 	errMsg          string
 	requeueDuration time.Duration
 }
@@ -14,12 +15,19 @@ func (r *RequeueError) Error() string {
 	return r.errMsg
 }
 
+// Reason returns a CamelCase identifier for why the request was requeued. Unlike
+// the message, it never contains the names of objects.
+func (r *RequeueError) Reason() string {
+	return r.reason
+}
+
 func (r *RequeueError) Duration() time.Duration {
 	return r.requeueDuration
 }
 
-func NewRequeueError(msg string, requeueSeconds int) error {
+func NewRequeueError(reason, msg string, requeueSeconds int) error {
 	return &RequeueError{
+		reason:          reason,
 		errMsg:          msg,
 		requeueDuration: time.Second * time.Duration(requeueSeconds),
 	}
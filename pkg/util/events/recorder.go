@@ -0,0 +1,104 @@
+// Package events contains an EventRecorder that drops repeats of the same
+// event, so reconcilers can record events on every pass without flooding the
+// objects they describe.
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// DefaultInterval is the interval within which repeats of the same event on
+// an object are dropped.
+var DefaultInterval = time.Duration(5) * time.Minute
+
+// eventKey identifies an event on an object.
+type eventKey struct {
+	object, eventtype, reason, message string
+}
+
+// rateLimitedRecorder wraps an EventRecorder and drops events identical to
+// one recorded on the same object within the interval.
+type rateLimitedRecorder struct {
+	record.EventRecorder
+
+	interval  time.Duration
+	mux       sync.Mutex
+	last      map[eventKey]time.Time
+	lastSweep time.Time
+}
+
+// NewRateLimitedRecorder returns a recorder that records events with the given
+// recorder, dropping repeats of the same event on an object within interval.
+func NewRateLimitedRecorder(recorder record.EventRecorder, interval time.Duration) record.EventRecorder {
+	return &rateLimitedRecorder{
+		EventRecorder: recorder,
+		interval:      interval,
+		last:          make(map[eventKey]time.Time),
+		lastSweep:     time.Now(),
+	}
+}
+
+// Event implements record.EventRecorder.
+func (r *rateLimitedRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.allow(object, eventtype, reason, message) {
+		r.EventRecorder.Event(object, eventtype, reason, message)
+	}
+}
+
+// Eventf implements record.EventRecorder.
+func (r *rateLimitedRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// PastEventf implements record.EventRecorder.
+func (r *rateLimitedRecorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.allow(object, eventtype, reason, message) {
+		r.EventRecorder.PastEventf(object, timestamp, eventtype, reason, "%s", message)
+	}
+}
+
+// AnnotatedEventf implements record.EventRecorder.
+func (r *rateLimitedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.allow(object, eventtype, reason, message) {
+		r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+	}
+}
+
+// allow returns true if the event has not been recorded on the object within
+// the interval, and marks it as recorded.
+func (r *rateLimitedRecorder) allow(object runtime.Object, eventtype, reason, message string) bool {
+	key := eventKey{eventtype: eventtype, reason: reason, message: message}
+	if obj, err := meta.Accessor(object); err == nil {
+		key.object = string(obj.GetUID())
+		if key.object == "" {
+			key.object = obj.GetNamespace() + "/" + obj.GetName()
+		}
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	now := time.Now()
+	// forget events that have expired so the map does not grow forever
+	if now.Sub(r.lastSweep) > r.interval {
+		for k, t := range r.last {
+			if now.Sub(t) > r.interval {
+				delete(r.last, k)
+			}
+		}
+		r.lastSweep = now
+	}
+	if t, ok := r.last[key]; ok && now.Sub(t) < r.interval {
+		return false
+	}
+	r.last[key] = now
+	return true
+}
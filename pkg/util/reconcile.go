@@ -16,42 +16,87 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// ReconcileDevice reconciles an AndroidDevice CR with the cluster. The checkUpdate
-// function provided will be called if an update is required. If the function returns
-// false, the request is requeued.
-func ReconcileDevice(reqLogger logr.Logger, c client.Client, device *androidv1alpha1.AndroidDevice, checkUpdate func(string) bool) error {
+// ReconcileDevice reconciles an AndroidDevice CR with the cluster and returns
+// true if it was created. An update is required if the spec has changed, or the
+// device reports it was provisioned with a config other than the given checksum.
+// The checkUpdate function provided will be called with the existing device if
+// an update is required. If the function returns false, the device is left as
+// it is and any error it returned is passed on, e.g. to requeue the request.
+// Updates to leased devices are deferred until they are released.
+func ReconcileDevice(reqLogger logr.Logger, c client.Client, device *androidv1alpha1.AndroidDevice, checksum string, checkUpdate func(*androidv1alpha1.AndroidDevice, string) (bool, error)) (bool, error) {
 	if err := SetCreationSpecAnnotation(&device.ObjectMeta, device); err != nil {
-		return err
+		return false, err
 	}
 	found := &androidv1alpha1.AndroidDevice{}
 	if err := c.Get(context.TODO(), types.NamespacedName{Name: device.Name, Namespace: device.Namespace}, found); err != nil {
 		// Return API error
 		if client.IgnoreNotFound(err) != nil {
-			return err
+			return false, err
 		}
 		// Create the device
 		reqLogger.Info("Creating new device", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
 		if err := c.Create(context.TODO(), device); err != nil {
-			return err
+			return false, err
 		}
-		return nil
+		return true, nil
 	}
 
-	// Check the found device spec
-	if !CreationSpecsEqual(device.ObjectMeta, found.ObjectMeta) {
+	// Check the found device spec and config
+	configChanged := found.ConfigChecksum() != "" && found.ConfigChecksum() != checksum
+	if !CreationSpecsEqual(device.ObjectMeta, found.ObjectMeta) || configChanged {
+		// Leased devices are updated once they are released
+		if found.IsLeased() {
+			reqLogger.Info("Device is leased, deferring update until it is released", "Device.Name", found.Name, "Device.Namespace", found.Namespace, "Lease", found.LeaseName())
+			return false, nil
+		}
 		// Check if we are allowed to update
-		if cont := checkUpdate(device.Annotations[androidv1alpha1.DeviceConfigSHAAnnotation]); !cont {
-			return errors.NewRequeueError("Device is not ready to be updated", 3)
+		if cont, err := checkUpdate(found, checksum); !cont {
+			return false, err
 		}
 		// We need to update the device
 		reqLogger.Info("Device annotation spec has changed, updating", "Device.Name", device.Name, "Device.Namespace", device.Namespace)
 		// will requeue the farm that made us
 		if err := c.Delete(context.TODO(), found); err != nil {
-			return err
+			return false, err
 		}
 	}
 
-	return nil
+	return false, nil
+}
+
+// ReconcileDeviceGroup reconciles an AndroidDeviceGroup CR with the cluster and
+// returns the state found. The replicas of the group are set to the given farm
+// count when it is created, or when the farm count differs from the one it was
+// last synced from. Otherwise, the replicas are left to be managed through the
+// scale subresource.
+func ReconcileDeviceGroup(reqLogger logr.Logger, c client.Client, group *androidv1alpha1.AndroidDeviceGroup, farmCount int32) (*androidv1alpha1.AndroidDeviceGroup, error) {
+	found := &androidv1alpha1.AndroidDeviceGroup{}
+	if err := c.Get(context.TODO(), group.NamespacedName(), found); err != nil {
+		// Return API error
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		// Create the device group
+		reqLogger.Info("Creating new device group", "DeviceGroup.Name", group.Name, "DeviceGroup.Namespace", group.Namespace)
+		group.Spec.Replicas = farmCount
+		group.SetFarmCount(farmCount)
+		if err := c.Create(context.TODO(), group); err != nil {
+			return nil, err
+		}
+		return group, nil
+	}
+
+	// Check if the count in the farm has changed
+	if found.FarmCount() != farmCount {
+		reqLogger.Info("Device group count has changed in the farm, updating replicas", "DeviceGroup.Name", found.Name, "DeviceGroup.Namespace", found.Namespace, "Replicas", farmCount)
+		found.Spec.Replicas = farmCount
+		found.SetFarmCount(farmCount)
+		if err := c.Update(context.TODO(), found); err != nil {
+			return nil, err
+		}
+	}
+
+	return found, nil
 }
 
 // ReconcileService will reconcile a provided service spec with the cluster.
@@ -80,7 +125,7 @@ func ReconcileService(reqLogger logr.Logger, c client.Client, svc *corev1.Servic
 		if err := c.Delete(context.TODO(), found); err != nil {
 			return err
 		}
-		return errors.NewRequeueError("Deleted service definition, requeueing", 2)
+		return errors.NewRequeueError("ServiceRecreated", "Deleted service definition, requeueing", 2)
 	}
 
 	return nil
@@ -110,7 +155,7 @@ func ReconcilePod(reqLogger logr.Logger, c client.Client, pod *corev1.Pod) (bool
 
 	// Check if the found pod is in the middle of terminating
 	if found.GetDeletionTimestamp() != nil {
-		return false, errors.NewRequeueError("Existing pod is still being terminated, requeuing", 3)
+		return false, errors.NewRequeueError("PodTerminating", "Existing pod is still being terminated, requeuing", 3)
 	}
 
 	// Check the found pod spec
@@ -119,7 +164,7 @@ func ReconcilePod(reqLogger logr.Logger, c client.Client, pod *corev1.Pod) (bool
 		if err := c.Delete(context.TODO(), found); err != nil {
 			return false, err
 		}
-		return false, errors.NewRequeueError("Pod spec has changed, recreating", 3)
+		return false, errors.NewRequeueError("PodSpecChanged", "Pod spec has changed, recreating", 3)
 	}
 
 	return false, nil
@@ -145,7 +190,7 @@ func ReconcileDeployment(reqLogger logr.Logger, c client.Client, deployment *app
 			return err
 		}
 		if wait {
-			return errors.NewRequeueError("Created new deployment with wait, requeing for status check", 3)
+			return errors.NewRequeueError("DeploymentCreated", "Created new deployment with wait, requeing for status check", 3)
 		}
 		return nil
 	}
@@ -166,7 +211,7 @@ func ReconcileDeployment(reqLogger logr.Logger, c client.Client, deployment *app
 			return err
 		}
 		if runningDeploy.Status.ReadyReplicas != *deployment.Spec.Replicas {
-			return errors.NewRequeueError(fmt.Sprintf("Waiting for %s to be ready", deployment.Name), 3)
+			return errors.NewRequeueError("DeploymentNotReady", fmt.Sprintf("Waiting for %s to be ready", deployment.Name), 3)
 		}
 	}
 
@@ -223,6 +268,10 @@ func ReconcileJob(reqLogger logr.Logger, c client.Client, job *batchv1.Job, wait
 		if err := c.Create(context.TODO(), job); err != nil {
 			return err
 		}
+		if wait {
+			return errors.NewRequeueError("JobRunning", "Waiting for new job to complete", 3)
+		}
+		return nil
 	}
 
 	// Check the found job spec
@@ -232,14 +281,14 @@ func ReconcileJob(reqLogger logr.Logger, c client.Client, job *batchv1.Job, wait
 			return err
 		}
 		if wait {
-			return errors.NewRequeueError("Deleting stale job and requeuing", 5)
+			return errors.NewRequeueError("JobRecreating", "Deleting stale job and requeuing", 5)
 		}
 	}
 
 	if wait {
 		// Make sure the job completed
 		if found.Status.Succeeded != 1 {
-			return errors.NewRequeueError("Waiting for job to complete", 3)
+			return errors.NewRequeueError("JobRunning", "Waiting for job to complete", 3)
 		}
 	}
 
@@ -293,7 +342,7 @@ func ReconcileCertificate(reqLogger logr.Logger, c client.Client, cert *cm.Certi
 			return err
 		}
 		if wait {
-			return errors.NewRequeueError("Requeueing status check for new certificate", 3)
+			return errors.NewRequeueError("CertificateCreated", "Requeueing status check for new certificate", 3)
 		}
 		return nil
 	}
@@ -315,7 +364,7 @@ func ReconcileCertificate(reqLogger logr.Logger, c client.Client, cert *cm.Certi
 				}
 			}
 		}
-		return errors.NewRequeueError("Certificate is not ready yet", 3)
+		return errors.NewRequeueError("CertificateNotReady", "Certificate is not ready yet", 3)
 	}
 
 	return nil
@@ -9,9 +9,21 @@ const (
 	StatusUnauthorized = 2
 )
 
+// DeviceOwner represents the user that owns a device in OpenSTF. Lease is not
+// used by OpenSTF, it identifies the lease that claimed the device so claims are
+// not confused with users or other leases that share the same email.
+type DeviceOwner struct {
+	Email string `rethinkdb:"email"`
+	Name  string `rethinkdb:"name"`
+	Lease string `rethinkdb:"lease,omitempty"`
+}
+
 type RethinkDBSession interface {
 	GetAllDevicesForProvider(provider string) ([]string, error)
 	GetDevicesForProviderByStatus(provider string, status int) ([]string, error)
+	GetDevicesInUseForProvider(provider string) ([]string, error)
+	ClaimDevice(serial string, owner *DeviceOwner) (bool, error)
+	ReleaseDevice(serial string, owner *DeviceOwner) error
 	Close() error
 }
 
@@ -34,37 +46,83 @@ func (r *rethinkDBSession) Close() error {
 }
 
 func (r *rethinkDBSession) GetAllDevicesForProvider(provider string) ([]string, error) {
-	res, err := rdb.DB("stf").
+	return r.getDeviceSerials(func(uu rdb.Term) rdb.Term {
+		return uu.Field("provider").Field("name").Eq(provider)
+	})
+}
+
+func (r *rethinkDBSession) GetDevicesForProviderByStatus(provider string, status int) ([]string, error) {
+	return r.getDeviceSerials(func(uu rdb.Term) rdb.Term {
+		return uu.And(uu.Field("provider").Field("name").Eq(provider), uu.Field("status").Eq(status))
+	})
+}
+
+// GetDevicesInUseForProvider returns the serials of the devices on the given
+// provider that are currently owned by a user.
+func (r *rethinkDBSession) GetDevicesInUseForProvider(provider string) ([]string, error) {
+	return r.getDeviceSerials(func(uu rdb.Term) rdb.Term {
+		return uu.And(uu.Field("provider").Field("name").Eq(provider), uu.Field("owner").Ne(nil))
+	})
+}
+
+// ClaimDevice marks the device with the given serial as owned by the given user,
+// if it is not already owned by someone else. It returns false if the device is
+// owned by another user or lease, as identified by the lease of the owner.
+func (r *rethinkDBSession) ClaimDevice(serial string, owner *DeviceOwner) (bool, error) {
+	if _, err := rdb.DB("stf").
 		Table("devices").
-		Filter(func(uu rdb.Term) rdb.Term {
-			return uu.Field("provider").Field("name").Eq(provider)
+		Get(serial).
+		Update(func(device rdb.Term) rdb.Term {
+			return rdb.Branch(
+				device.Field("owner").Default(nil).Eq(nil),
+				map[string]interface{}{"owner": owner},
+				map[string]interface{}{},
+			)
 		}).
-		Field("serial").
+		RunWrite(r.session); err != nil {
+		return false, err
+	}
+	res, err := rdb.DB("stf").
+		Table("devices").
+		Get(serial).
+		Field("owner").
+		Field("lease").
+		Default("").
 		Run(r.session)
 	if err != nil {
-		return nil, err
-	}
-	if res.IsNil() {
-		return []string{}, nil
-	} else if res.Err() != nil {
-		return nil, res.Err()
+		return false, err
 	}
 	defer res.Close()
-	devices := make([]string, 0)
-	ch := make(chan string)
-	res.Listen(ch)
-	for x := range ch {
-		devices = append(devices, x)
+	var lease string
+	if err := res.One(&lease); err != nil {
+		return false, err
 	}
-	return devices, nil
+	return owner.Lease != "" && lease == owner.Lease, nil
 }
 
-func (r *rethinkDBSession) GetDevicesForProviderByStatus(provider string, status int) ([]string, error) {
-	res, err := rdb.DB("stf").
+// ReleaseDevice removes the owner of the device with the given serial, if it is
+// still owned by the lease of the given owner.
+func (r *rethinkDBSession) ReleaseDevice(serial string, owner *DeviceOwner) error {
+	_, err := rdb.DB("stf").
 		Table("devices").
-		Filter(func(uu rdb.Term) rdb.Term {
-			return uu.And(uu.Field("provider").Field("name").Eq(provider), uu.Field("status").Eq(status))
+		Get(serial).
+		Update(func(device rdb.Term) rdb.Term {
+			return rdb.Branch(
+				device.Field("owner").Field("lease").Default(nil).Eq(owner.Lease),
+				map[string]interface{}{"owner": nil},
+				map[string]interface{}{},
+			)
 		}).
+		RunWrite(r.session)
+	return err
+}
+
+// getDeviceSerials returns the serials of all devices in the stf devices table
+// matching the given filter.
+func (r *rethinkDBSession) getDeviceSerials(filter func(rdb.Term) rdb.Term) ([]string, error) {
+	res, err := rdb.DB("stf").
+		Table("devices").
+		Filter(filter).
 		Field("serial").
 		Run(r.session)
 	if err != nil {
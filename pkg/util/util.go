@@ -25,6 +25,28 @@ func IsMarkedForDeletion(cr *androidv1alpha1.AndroidFarm) bool {
 	return cr.GetDeletionTimestamp() != nil
 }
 
+// ContainsString returns true if the given slice contains the string s.
+func ContainsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveString returns a copy of the given slice with all occurrences of the
+// string rm removed.
+func RemoveString(in []string, rm string) []string {
+	out := make([]string, 0)
+	for _, x := range in {
+		if x != rm {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
 func SetCreationSpecAnnotation(meta *metav1.ObjectMeta, obj runtime.Object) error {
 	annotations := meta.GetAnnotations()
 	if annotations == nil {
@@ -85,3 +107,11 @@ func DeviceIntToString(i int) string {
 func IntFromDeviceString(s string) (int, error) {
 	return strconv.Atoi(strings.TrimLeft(s, "0"))
 }
+
+// DeviceIndex returns the index of a farmed device in its group from its name.
+func DeviceIndex(devName string) (int32, error) {
+	spl := strings.Split(devName, "-")
+	// Make sure it will fit into an int32
+	idx, err := strconv.ParseInt(spl[len(spl)-1], 10, 32)
+	return int32(idx), err
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// convertibleCRDs are the CRDs that are served at more than one version and
+// require the conversion webhook.
+var convertibleCRDs = []string{
+	"androidfarms.android.stf.io",
+	"androiddevices.android.stf.io",
+	"androiddeviceconfigs.android.stf.io",
+}
+
+// InjectConversionConfig configures the multi-version CRDs to convert objects
+// through the webhook served by the operator behind the given service. The CA
+// bundle for the webhook is read from ca.crt in the given certificate directory.
+func InjectConversionConfig(cfg *rest.Config, service types.NamespacedName, certDir string) error {
+	caBundle, err := ioutil.ReadFile(filepath.Join(certDir, "ca.crt"))
+	if err != nil {
+		return err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1beta1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	path := "/convert"
+	conversion := &apiextensionsv1beta1.CustomResourceConversion{
+		Strategy: apiextensionsv1beta1.WebhookConverter,
+		WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+			Service: &apiextensionsv1beta1.ServiceReference{
+				Namespace: service.Namespace,
+				Name:      service.Name,
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		},
+		ConversionReviewVersions: []string{"v1beta1"},
+	}
+
+	for _, name := range convertibleCRDs {
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name}, crd); err != nil {
+			return err
+		}
+		if reflect.DeepEqual(crd.Spec.Conversion, conversion) {
+			continue
+		}
+		crd.Spec.Conversion = conversion
+		if err := c.Update(context.TODO(), crd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
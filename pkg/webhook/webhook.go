@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	androidv1alpha1 "github.com/tinyzimmer/android-farm-operator/pkg/apis/android/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// webhookTypes are the types that serve admission webhooks. Each type implements
+// the webhook.Validator interface, and optionally webhook.Defaulter. Types that
+// are conversion hubs also serve the conversion webhook for their other versions.
+var webhookTypes = []runtime.Object{
+	&androidv1alpha1.AndroidFarm{},
+	&androidv1alpha1.AndroidDevice{},
+	&androidv1alpha1.AndroidDeviceGroup{},
+	&androidv1alpha1.AndroidDeviceLease{},
+	&androidv1alpha1.AndroidDeviceConfig{},
+	&androidv1alpha1.AndroidJob{},
+	&androidv1alpha1.AndroidJobTemplate{},
+}
+
+// AddToManager registers the admission webhooks for all types with the Manager
+func AddToManager(m manager.Manager) error {
+	for _, obj := range webhookTypes {
+		if err := builder.WebhookManagedBy(m).For(obj).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}